@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/toxyl/gfx/parser"
@@ -30,20 +32,30 @@ func main() {
 		return
 	}
 
-	comp := parser.NewComposition("", 0, 0).LoadGFXS(*fileIn)
-	f := *fileOut
-	fl := strings.ToLower(f)
-
-	if strings.HasSuffix(fl, ".png") {
-		comp.Render().SaveAsPNG(f)
+	comp, err := parser.NewComposition("", 0, 0).LoadGFXS(*fileIn)
+	if err != nil {
+		fmt.Printf("failed to load composition: %s\n", err)
+		os.Exit(1)
+	}
+	img, err := comp.Render(context.Background())
+	if err != nil {
+		fmt.Printf("failed to render composition: %s\n", err)
+		os.Exit(1)
 	}
-	if strings.HasSuffix(fl, ".jpg") || strings.HasSuffix(fl, ".jpeg") {
-		comp.Render().SaveAsJPG(f)
+	if err := img.Save(*fileOut); err != nil {
+		fmt.Printf("failed to save image: %s\n", err)
+		os.Exit(1)
 	}
 	if strings.TrimSpace(*fileOutGFXS) != "" {
-		comp.SaveGFXS(*fileOutGFXS)
+		if err := comp.SaveGFXS(*fileOutGFXS); err != nil {
+			fmt.Printf("failed to save GFXS: %s\n", err)
+			os.Exit(1)
+		}
 	}
 	if strings.TrimSpace(*fileOutYAML) != "" {
-		comp.SaveYAML(*fileOutYAML)
+		if err := comp.SaveYAML(*fileOutYAML); err != nil {
+			fmt.Printf("failed to save YAML: %s\n", err)
+			os.Exit(1)
+		}
 	}
 }
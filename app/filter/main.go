@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"strings"
@@ -37,10 +38,20 @@ func MakeExample(m *parser.MetaData) string {
 	return sb.String()
 }
 
+// PrintStats prints a table with the statistics of all channels of img.
+func PrintStats(img *image.Image) {
+	fmt.Printf("%-7s %8s %8s %8s %8s %8s %8s %8s\n", "channel", "min", "p5", "median", "p95", "max", "mean", "stddev")
+	for _, ch := range image.Channels {
+		s := img.Stats(ch, nil)
+		fmt.Printf("%-7s %8.4f %8.4f %8.4f %8.4f %8.4f %8.4f %8.4f\n", ch, s.Min, s.Percentile(5), s.Median, s.Percentile(95), s.Max, s.Mean, s.StdDev)
+	}
+}
+
 func main() {
 	var (
 		chain     filterChain
 		showList  = flag.Bool("list", false, "if provided a list with examples of all available filters will be printed, all other flags will be ignored")
+		showStats = flag.Bool("stats", false, "if provided statistics of all channels of the input file will be printed, -out is not required")
 		fileIn    = flag.String("in", "", "input file")
 		fileOut   = flag.String("out", "", "output file")
 		fileChain = flag.String("chain", "", "filter chain file (if present the filter chain will be loaded from this file instead of the -f flags, if not present the -f flags will be used to create the file)")
@@ -56,6 +67,18 @@ func main() {
 		return
 	}
 
+	if *showStats && *fileIn != "" {
+		img, err := image.OpenFile(*fileIn)
+		if err != nil {
+			fmt.Printf("Failed to load image: %s\n", err.Error())
+			return
+		}
+		PrintStats(img)
+		if *fileOut == "" {
+			return
+		}
+	}
+
 	if fileIn != nil && *fileIn != "" && fileOut != nil && *fileOut != "" {
 		filterChain := parser.NewFilterChain()
 		appendFilters := true
@@ -86,7 +109,16 @@ func main() {
 				fmt.Printf("Filter chain saved to %s.\n", *fileOut)
 			}
 		}
-		res := filterChain.Apply(image.NewFromFile(*fileIn))
+		img, err := image.OpenFile(*fileIn)
+		if err != nil {
+			fmt.Printf("Failed to load image: %s\n", err.Error())
+			return
+		}
+		res, err := filterChain.Apply(context.Background(), img)
+		if err != nil {
+			fmt.Printf("Failed to apply filter chain: %s\n", err.Error())
+			return
+		}
 		ft := strings.ToLower(*fileOut)
 		if strings.HasSuffix(ft, ".png") {
 			res.SaveAsPNG(*fileOut)
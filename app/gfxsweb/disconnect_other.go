@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// watchDisconnect returns a context for the render of c. Disconnects are only detected on unix systems,
+// here the context is only cancelled by stop.
+func watchDisconnect(c *fiber.Ctx) (ctx context.Context, stop func()) {
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// watchDisconnect returns a context that is cancelled when the client of c closes its connection.
+// fasthttp doesn't report disconnects, so the socket is watched with a peek (MSG_PEEK) that leaves all data
+// in it for fasthttp. Call stop before writing the response.
+//
+// HTTP/1.1 keep-alive clients may send their next request before they got the response. The watcher stops
+// once data arrives, so closing the connection after that isn't noticed and the render runs to the end.
+func watchDisconnect(c *fiber.Ctx) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := c.Context().Conn()
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return ctx, cancel // e.g. TLS connections
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return ctx, cancel
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		closed := false
+		buf := make([]byte, 1)
+		// returns with a timeout error when stop sets the read deadline
+		_ = raw.Read(func(fd uintptr) bool {
+			n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK)
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				return false // nothing to read yet
+			}
+			closed = err != nil || n == 0
+			return true
+		})
+		if closed {
+			cancel()
+		}
+	}()
+	return ctx, func() {
+		_ = conn.SetReadDeadline(time.Now()) // fasthttp sets its own deadline before reading the next request
+		<-done
+		cancel()
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/base64"
 	"flag"
@@ -25,6 +26,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	gfxi "github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/executor"
 	"github.com/toxyl/gfx/parser"
 )
 
@@ -42,6 +44,9 @@ var gfxsmode string
 
 var MAX_MP int = 2048 * 1536
 
+// MAX_WORKERS is the number of goroutines a single render may use, 0 means no limit besides the executor's.
+var MAX_WORKERS int = 0
+
 // getCurrentImagePath returns the path of the current static image.
 func getCurrentImagePath() (string, error) {
 	if _, err := os.Stat("image.png"); err == nil {
@@ -190,9 +195,15 @@ var (
 		}
 
 		// Render the composition and encode the processed image as PNG into memory.
+		// The render is cancelled if the browser disconnects, e.g. because it started a newer render.
+		ctx, stop := watchDisconnect(c)
 		outBuffer := new(bytes.Buffer)
-		renderedComp := comp.Render()
-		renderedData := renderedComp.Get()
+		renderedComp, err := comp.Render(executor.WithLimit(ctx, MAX_WORKERS))
+		stop()
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).SendString("Failed to render composition: " + err.Error())
+		}
+		renderedData := renderedComp.Get().NRGBA()
 		if err := png.Encode(outBuffer, renderedData); err != nil {
 			return c.Status(http.StatusInternalServerError).SendString("Failed to encode processed image: " + err.Error())
 		}
@@ -261,7 +272,10 @@ var (
 				return c.Status(http.StatusBadRequest).SendString("Failed to save image: " + err.Error())
 			}
 
-			i := gfxi.NewFromFile(tempOrigPath)
+			i, err := gfxi.OpenFile(tempOrigPath)
+			if err != nil {
+				continue
+			}
 			updatedGfxs := strings.ReplaceAll(updateDimensionsIfMissing(gfxs, i.W(), i.H()), `$IMG`, i.Path())
 			comp, err := parser.ParseComposition(updatedGfxs)
 			if err != nil {
@@ -269,7 +283,13 @@ var (
 			}
 			processedName := changeExtension(fileHeader.Filename)
 			processedPath := filepath.Join(tempDir, processedName)
-			comp.Render().SaveAsPNG(processedPath)
+			renderedComp, err := comp.Render(context.Background())
+			if err != nil {
+				continue
+			}
+			if err := renderedComp.Save(processedPath); err != nil {
+				continue
+			}
 			processedFiles = append(processedFiles, processedFile{originalName: processedName, processedPath: processedPath})
 		}
 
@@ -356,13 +376,26 @@ var (
 		if err != nil || len(u) == 0 {
 			return c.Status(http.StatusBadRequest).SendString("Valid URL required")
 		}
-		i := gfxi.NewFromURL(string(u)).ResizeToMaxMP(MAX_MP).SaveAsPNG(baseImage)
+		i, err := gfxi.OpenURL(string(u))
+		if err != nil {
+			return c.Status(http.StatusBadGateway).SendString("Failed to load image: " + err.Error())
+		}
+		i = i.ResizeToMaxMP(MAX_MP)
+		if err := i.Save(baseImage); err != nil {
+			return c.Status(http.StatusInternalServerError).SendString("Failed to save image: " + err.Error())
+		}
 		filterText := updateDimensionsIfMissing(strings.ReplaceAll(string(data), `$IMG`, i.Path()), i.W(), i.H())
 		comp, err := parser.ParseComposition(filterText)
 		if err != nil {
 			return c.Status(http.StatusBadRequest).SendString("GFXS filter could not be parsed: " + err.Error())
 		}
-		_ = comp.Render().SaveAsPNG(baseImage)
+		renderedComp, err := comp.Render(context.Background())
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).SendString("Failed to render filter: " + err.Error())
+		}
+		if err := renderedComp.Save(baseImage); err != nil {
+			return c.Status(http.StatusInternalServerError).SendString("Failed to save filter render: " + err.Error())
+		}
 		time.Sleep(100 * time.Millisecond)
 		return c.SendFile(baseImage)
 	}
@@ -370,12 +403,17 @@ var (
 
 func main() {
 	fport := flag.Uint("p", 8080, "The port to run the server on, defaults to 8080.")
+	fworkers := flag.Int("workers", 0, "The number of goroutines used for pixel processing, defaults to the number of CPUs.")
+	flag.IntVar(&MAX_WORKERS, "render-workers", MAX_WORKERS, "The number of goroutines a single render may use, defaults to all workers.")
 	flag.Parse()
 
+	executor.SetConcurrency(*fworkers)
+
 	os.MkdirAll("filters", 0755)
 
 	app := fiber.New(fiber.Config{
-		BodyLimit: 10 * 1024 * 1024 * 1024,
+		BodyLimit:   10 * 1024 * 1024 * 1024,
+		IdleTimeout: time.Minute, // also makes fasthttp reset the read deadline that watchDisconnect sets
 	})
 	app.Use(recover.New())
 	app.Use(cors.New())
@@ -37,6 +37,7 @@ document.addEventListener("DOMContentLoaded", () => {
   let autoRenderTimeoutId = null;
   let autoRenderScheduledTime = null;
   let isRenderInProgress = false;
+  let renderController = null;
   let lastRenderedContent = "";
   let currentTimestamp = Date.now();
   const AUTO_RENDER_DELAY = 10000; // 10 seconds
@@ -127,6 +128,10 @@ document.addEventListener("DOMContentLoaded", () => {
     const currentContent = editorVars.getValue() + editorFilters.getValue() + editorComposition.getValue() + editorLayers.getValue();
     if (!force && currentContent === lastRenderedContent) return;
     if (isRenderInProgress && !force) return;
+    // A forced render replaces the running one, aborting its request makes the server cancel it.
+    if (renderController) renderController.abort();
+    const controller = new AbortController();
+    renderController = controller;
     isRenderInProgress = true;
     setRenderIndicator(true);
     const gfxs = "[VARS]\n" + editorVars.getValue() +
@@ -139,6 +144,7 @@ document.addEventListener("DOMContentLoaded", () => {
         method: "POST",
         headers: { "Content-Type": "application/x-www-form-urlencoded" },
         body: `gfxs=${encodeURIComponent(gfxs)}`,
+        signal: controller.signal,
       });
       if (response.ok) {
         const json = await response.json();
@@ -158,8 +164,12 @@ document.addEventListener("DOMContentLoaded", () => {
         alert("Failed to render: " + await response.text());
       }
     } catch (error) {
-      alert("An error occurred: " + error.message);
+      if (error.name !== "AbortError") {
+        alert("An error occurred: " + error.message);
+      }
     } finally {
+      if (renderController !== controller) return; // superseded by a newer render
+      renderController = null;
       isRenderInProgress = false;
       setRenderIndicator(false);
       scheduleAutoRender();
@@ -0,0 +1,133 @@
+package blend
+
+import (
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/math"
+)
+
+// The non-separable blend modes combine components (hue, saturation and luminosity) of both colors
+// instead of blending each channel on its own. They follow the formulas of the W3C compositing spec.
+
+// rgb holds red, green and blue in the range [0, 1].
+type rgb [3]float64
+
+func newRGB(c *rgba.RGBA) rgb {
+	return rgb{c.R() / 255.0, c.G() / 255.0, c.B() / 255.0}
+}
+
+// lum returns the luminosity of c.
+func (c rgb) lum() float64 {
+	return 0.3*c[0] + 0.59*c[1] + 0.11*c[2]
+}
+
+// sat returns the saturation of c, the difference between the largest and the smallest channel.
+func (c rgb) sat() float64 {
+	return math.Max(c[0], math.Max(c[1], c[2])) - math.Min(c[0], math.Min(c[1], c[2]))
+}
+
+// clip moves colors outside of [0, 1] back into range while keeping their luminosity.
+func (c rgb) clip() rgb {
+	l := c.lum()
+	n := math.Min(c[0], math.Min(c[1], c[2]))
+	x := math.Max(c[0], math.Max(c[1], c[2]))
+	for i := range c {
+		if n < 0 {
+			c[i] = l + (c[i]-l)*l/(l-n)
+		}
+		if x > 1 {
+			c[i] = l + (c[i]-l)*(1-l)/(x-l)
+		}
+	}
+	return c
+}
+
+// withLum returns c with its luminosity set to l.
+func (c rgb) withLum(l float64) rgb {
+	d := l - c.lum()
+	return rgb{c[0] + d, c[1] + d, c[2] + d}.clip()
+}
+
+// withSat returns c with its saturation set to s, hue and order of the channels are kept.
+func (c rgb) withSat(s float64) rgb {
+	iMin, iMid, iMax := 0, 1, 2
+	if c[iMin] > c[iMid] {
+		iMin, iMid = iMid, iMin
+	}
+	if c[iMid] > c[iMax] {
+		iMid, iMax = iMax, iMid
+	}
+	if c[iMin] > c[iMid] {
+		iMin, iMid = iMid, iMin
+	}
+	res := rgb{}
+	if c[iMax] > c[iMin] {
+		res[iMid] = (c[iMid] - c[iMin]) * s / (c[iMax] - c[iMin])
+		res[iMax] = s
+	}
+	return res
+}
+
+// componentFunc is a reusable function for applying a non-separable blend mode.
+func componentFunc(c1, c2 *rgba.RGBA, blendFunc func(base, blend rgb) rgb) *rgba.RGBA {
+	a2 := c2.A() / 255.0
+
+	if a2 == 0 {
+		return c1
+	}
+
+	res := blendFunc(newRGB(c1), newRGB(c2))
+	outR := math.Clamp(c1.R()*(1-a2)+res[0]*255.0*a2, 0x00, 0xFF)
+	outG := math.Clamp(c1.G()*(1-a2)+res[1]*255.0*a2, 0x00, 0xFF)
+	outB := math.Clamp(c1.B()*(1-a2)+res[2]*255.0*a2, 0x00, 0xFF)
+
+	return rgba.New(outR, outG, outB, 0xFF)
+}
+
+// hue implements the "hue" blend mode, the hue of the blend color with saturation and luminosity of the base color.
+func hue(c1, c2 *rgba.RGBA) *rgba.RGBA {
+	return componentFunc(c1, c2, func(base, blend rgb) rgb {
+		return blend.withSat(base.sat()).withLum(base.lum())
+	})
+}
+
+// saturation implements the "saturation" blend mode, the saturation of the blend color with hue and luminosity of the base color.
+func saturation(c1, c2 *rgba.RGBA) *rgba.RGBA {
+	return componentFunc(c1, c2, func(base, blend rgb) rgb {
+		return base.withSat(blend.sat()).withLum(base.lum())
+	})
+}
+
+// color implements the "color" blend mode, hue and saturation of the blend color with the luminosity of the base color.
+// This colorizes grayscale images.
+func color(c1, c2 *rgba.RGBA) *rgba.RGBA {
+	return componentFunc(c1, c2, func(base, blend rgb) rgb {
+		return blend.withLum(base.lum())
+	})
+}
+
+// luminosity implements the "luminosity" blend mode, the luminosity of the blend color with hue and saturation of the base color.
+func luminosity(c1, c2 *rgba.RGBA) *rgba.RGBA {
+	return componentFunc(c1, c2, func(base, blend rgb) rgb {
+		return base.withLum(blend.lum())
+	})
+}
+
+// lighterColor implements the "lighter color" blend mode, where the color with the higher luminosity is selected.
+func lighterColor(c1, c2 *rgba.RGBA) *rgba.RGBA {
+	return componentFunc(c1, c2, func(base, blend rgb) rgb {
+		if blend.lum() > base.lum() {
+			return blend
+		}
+		return base
+	})
+}
+
+// darkerColor implements the "darker color" blend mode, where the color with the lower luminosity is selected.
+func darkerColor(c1, c2 *rgba.RGBA) *rgba.RGBA {
+	return componentFunc(c1, c2, func(base, blend rgb) rgb {
+		if blend.lum() < base.lum() {
+			return blend
+		}
+		return base
+	})
+}
@@ -0,0 +1,117 @@
+package blend
+
+import (
+	"strings"
+
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/math"
+)
+
+// CompositeOp type represents Porter-Duff operator identifiers.
+// The operator decides how much of the source and the destination remain, the blend mode
+// decides the color where both overlap.
+type CompositeOp string
+
+// CompositeFunc returns the fractions fa and fb of the source (with alpha as) and the destination (with alpha ab)
+// that remain after compositing.
+type CompositeFunc func(as, ab float64) (fa, fb float64)
+
+// Constants for Porter-Duff operators
+const (
+	SRC_OVER CompositeOp = "src-over"
+	DST_OVER CompositeOp = "dst-over"
+	SRC_IN   CompositeOp = "src-in"
+	DST_IN   CompositeOp = "dst-in"
+	SRC_OUT  CompositeOp = "src-out"
+	DST_OUT  CompositeOp = "dst-out"
+	SRC_ATOP CompositeOp = "src-atop"
+	DST_ATOP CompositeOp = "dst-atop"
+	XOR      CompositeOp = "xor"
+	CLEAR    CompositeOp = "clear"
+)
+
+// CompositeOps map allows accessing Porter-Duff operators by name.
+var CompositeOps = map[CompositeOp]CompositeFunc{
+	SRC_OVER: func(as, ab float64) (float64, float64) { return 1, 1 - as },
+	DST_OVER: func(as, ab float64) (float64, float64) { return 1 - ab, 1 },
+	SRC_IN:   func(as, ab float64) (float64, float64) { return ab, 0 },
+	DST_IN:   func(as, ab float64) (float64, float64) { return 0, as },
+	SRC_OUT:  func(as, ab float64) (float64, float64) { return 1 - ab, 0 },
+	DST_OUT:  func(as, ab float64) (float64, float64) { return 0, 1 - as },
+	SRC_ATOP: func(as, ab float64) (float64, float64) { return ab, 1 - as },
+	DST_ATOP: func(as, ab float64) (float64, float64) { return 1 - ab, as },
+	XOR:      func(as, ab float64) (float64, float64) { return 1 - ab, 1 - as },
+	CLEAR:    func(as, ab float64) (float64, float64) { return 0, 0 },
+}
+
+// CompositeOpNames returns the names of all Porter-Duff operators.
+func CompositeOpNames() []string {
+	return []string{
+		string(SRC_OVER), string(DST_OVER),
+		string(SRC_IN), string(DST_IN),
+		string(SRC_OUT), string(DST_OUT),
+		string(SRC_ATOP), string(DST_ATOP),
+		string(XOR), string(CLEAR),
+	}
+}
+
+// ParseCompositeOp returns the Porter-Duff operator with the given name, unknown names return an empty operator.
+func ParseCompositeOp(name string) CompositeOp {
+	op := CompositeOp(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := CompositeOps[op]; !ok {
+		return ""
+	}
+	return op
+}
+
+// Composite blends the source color c2 onto the destination color c1 with the given blend mode
+// and composites the result with the Porter-Duff operator op. The alpha of c2 is multiplied with alpha.
+// Without a (known) operator this is the same as RGBA. ERASE is composited as NORMAL with DST_OUT.
+func Composite(c1, c2 *rgba.RGBA, mode BlendMode, op CompositeOp, alpha float64) *rgba.RGBA {
+	if mode == ERASE {
+		mode, op = NORMAL, DST_OUT
+	}
+	opFunc, found := CompositeOps[op]
+	if !found {
+		return RGBA(c1, c2, mode, alpha)
+	}
+	blendFunc, found := BlendModes[mode]
+	if !found {
+		blendFunc = normal
+	}
+	ab := c1.A() / 255.0
+	as := c2.A() / 255.0 * alpha
+	fa, fb := opFunc(as, ab)
+	resAlpha := as*fa + ab*fb
+	if resAlpha <= 0 {
+		return rgba.New(0, 0, 0, 0) // Return fully transparent black
+	}
+
+	// where both overlap the source color is replaced by the blended color
+	blended := blendFunc(rgba.New(c1.R(), c1.G(), c1.B(), 0xFF), rgba.New(c2.R(), c2.G(), c2.B(), 0xFF))
+	channel := func(cb, cs, b float64) float64 {
+		cs = (1-ab)*cs + ab*b
+		return math.Clamp((cs*as*fa+cb*ab*fb)/resAlpha, 0x00, 0xFF)
+	}
+	return rgba.New(
+		channel(c1.R(), c2.R(), blended.R()),
+		channel(c1.G(), c2.G(), blended.G()),
+		channel(c1.B(), c2.B(), blended.B()),
+		math.Min(resAlpha, 1)*255.0,
+	)
+}
+
+// CompositeIn is like Composite but blends in the given space.
+func CompositeIn(c1, c2 *rgba.RGBA, mode BlendMode, op CompositeOp, alpha float64, space BlendSpace) *rgba.RGBA {
+	if space != SPACE_LINEAR {
+		return Composite(c1, c2, mode, op, alpha)
+	}
+	return toSRGB(Composite(toLinear(c1), toLinear(c2), mode, op, alpha))
+}
+
+// CompositeHSLAIn is like CompositeIn but for HSLA colors.
+func CompositeHSLAIn(c1, c2 *hsla.HSLA, mode BlendMode, op CompositeOp, alpha float64, space BlendSpace) *hsla.HSLA {
+	return convert.RGBAToHSLA(CompositeIn(convert.HSLAToRGBA(c1), convert.HSLAToRGBA(c2), mode, op, alpha, space))
+}
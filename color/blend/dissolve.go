@@ -0,0 +1,10 @@
+package blend
+
+import "github.com/toxyl/gfx/math"
+
+// Dissolve returns whether the pixel at x, y shows the blend color when dissolving with the given alpha,
+// otherwise it shows the base color. On average a fraction of alpha pixels pass, the same seed always
+// dissolves the same pixels.
+func Dissolve(seed uint64, x, y int, alpha float64) bool {
+	return math.HashFloat(seed, int64(x), int64(y)) < alpha
+}
@@ -9,22 +9,22 @@ import (
 
 // RGBA blends two RGBA colors to a new RGBA color.
 func RGBA(c1, c2 *rgba.RGBA, mode BlendMode, alpha float64) *rgba.RGBA {
-	srcAlpha := float64(c1.A()) / 255.0
-	dstAlpha := float64(c2.A()) / 255.0
+	srcAlpha := c1.A() / 255.0
+	dstAlpha := c2.A() / 255.0
 	resAlpha := srcAlpha + dstAlpha*(1-srcAlpha) // Porter-Duff Over operator
 
 	if resAlpha == 0 {
 		return rgba.New(0, 0, 0, 0) // Return fully transparent black
 	}
 
-	c2.SetA(uint8(float64(c2.A()) * alpha))
+	c2.SetA(c2.A() * alpha)
 	var blended *rgba.RGBA
 	if blendFunc, found := BlendModes[mode]; found {
 		blended = blendFunc(c1, c2)
 	} else {
 		blended = normal(c1, c2)
 	}
-	return rgba.New(blended.R(), blended.G(), blended.B(), uint8(math.Clamp(resAlpha*255.0, 0.0, 255.0)))
+	return rgba.New(blended.R(), blended.G(), blended.B(), resAlpha*255.0)
 }
 
 // HSLA blends two HSLA colors to a new HSLA color.
@@ -38,6 +38,15 @@ type BlendMode string
 // BlendFunc type represents a blend mode function that blends two RGBA colors.
 type BlendFunc func(c1, c2 *rgba.RGBA) *rgba.RGBA
 
+// ChannelFunc type represents the function of a separable blend mode that blends a single channel
+// of the base color src with the blend color dst. Channels range from 0 to 255.
+type ChannelFunc func(src, dst float64) float64
+
+// CustomFunc type represents a user-defined blend function that blends a single channel of the base color a
+// with the blend color b, like the ChannelFunc of a separable blend mode. Channels and the alpha of the blend
+// color range from 0 to 1. See image.DrawCustom.
+type CustomFunc func(a, b, alpha float64) float64
+
 // Constants for blend modes
 const (
 	NORMAL BlendMode = "normal"
@@ -51,10 +60,13 @@ const (
 	SCREEN  BlendMode = "screen"
 	ADD     BlendMode = "add"
 	//
-	OVERLAY    BlendMode = "overlay"
-	SOFT_LIGHT BlendMode = "soft-light"
-	HARD_LIGHT BlendMode = "hard-light"
-	PIN_LIGHT  BlendMode = "pin-light"
+	OVERLAY      BlendMode = "overlay"
+	SOFT_LIGHT   BlendMode = "soft-light"
+	HARD_LIGHT   BlendMode = "hard-light"
+	PIN_LIGHT    BlendMode = "pin-light"
+	VIVID_LIGHT  BlendMode = "vivid-light"
+	LINEAR_LIGHT BlendMode = "linear-light"
+	HARD_MIX     BlendMode = "hard-mix"
 	//
 	DIFFERENCE BlendMode = "difference"
 	EXCLUSION  BlendMode = "exclusion"
@@ -64,13 +76,61 @@ const (
 	AVERAGE  BlendMode = "average"
 	NEGATION BlendMode = "negation"
 	//
-	ERASE BlendMode = "erase"
+	HUE        BlendMode = "hue"
+	SATURATION BlendMode = "saturation"
+	COLOR      BlendMode = "color"
+	LUMINOSITY BlendMode = "luminosity"
+	//
+	LIGHTER_COLOR BlendMode = "lighter-color"
+	DARKER_COLOR  BlendMode = "darker-color"
+	//
+	DISSOLVE BlendMode = "dissolve"
+	ERASE    BlendMode = "erase"
 )
 
 // BlendModes map allows accessing blend modes by name.
 var BlendModes = map[BlendMode]BlendFunc{
 	NORMAL: normal,
 	//
+	DARKEN:      separable(darken),
+	MULTIPLY:    separable(multiply),
+	COLOR_BURN:  separable(colorBurn),
+	LINEAR_BURN: separable(linearBurn),
+	//
+	LIGHTEN: separable(lighten),
+	SCREEN:  separable(screen),
+	ADD:     separable(add),
+	//
+	OVERLAY:      separable(overlay),
+	SOFT_LIGHT:   separable(softLight),
+	HARD_LIGHT:   separable(hardLight),
+	PIN_LIGHT:    separable(pinLight),
+	VIVID_LIGHT:  separable(vividLight),
+	LINEAR_LIGHT: separable(linearLight),
+	HARD_MIX:     separable(hardMix),
+	//
+	DIFFERENCE: separable(difference),
+	EXCLUSION:  separable(exclusion),
+	SUBTRACT:   separable(subtract),
+	DIVIDE:     separable(divide),
+	//
+	AVERAGE:  separable(average),
+	NEGATION: separable(negation),
+	//
+	HUE:        hue,
+	SATURATION: saturation,
+	COLOR:      color,
+	LUMINOSITY: luminosity,
+	//
+	LIGHTER_COLOR: lighterColor,
+	DARKER_COLOR:  darkerColor,
+	//
+	DISSOLVE: normal, // the dithering needs the pixel position, see Dissolve
+	ERASE:    erase,
+}
+
+// Channels map allows accessing the channel functions of the separable blend modes by name.
+var Channels = map[BlendMode]ChannelFunc{
 	DARKEN:      darken,
 	MULTIPLY:    multiply,
 	COLOR_BURN:  colorBurn,
@@ -80,10 +140,13 @@ var BlendModes = map[BlendMode]BlendFunc{
 	SCREEN:  screen,
 	ADD:     add,
 	//
-	OVERLAY:    overlay,
-	SOFT_LIGHT: softLight,
-	HARD_LIGHT: hardLight,
-	PIN_LIGHT:  pinLight,
+	OVERLAY:      overlay,
+	SOFT_LIGHT:   softLight,
+	HARD_LIGHT:   hardLight,
+	PIN_LIGHT:    pinLight,
+	VIVID_LIGHT:  vividLight,
+	LINEAR_LIGHT: linearLight,
+	HARD_MIX:     hardMix,
 	//
 	DIFFERENCE: difference,
 	EXCLUSION:  exclusion,
@@ -92,35 +155,40 @@ var BlendModes = map[BlendMode]BlendFunc{
 	//
 	AVERAGE:  average,
 	NEGATION: negation,
-	//
-	ERASE: erase,
 }
 
 // blendChannel is a reusable function to blend each color channel.
-func blendChannel(src, dst uint8, a2 float64) uint8 {
-	vSrc := float64(src) * (1 - a2)
-	vDst := float64(dst) * a2
-	return uint8(math.Round(math.Clamp(vSrc+vDst, 0x00, 0xFF)))
+func blendChannel(src, dst, a2 float64) float64 {
+	vSrc := src * (1 - a2)
+	vDst := dst * a2
+	return math.Clamp(vSrc+vDst, 0x00, 0xFF)
+}
+
+// separable returns the blend function of a separable blend mode that blends each channel with fn.
+func separable(fn ChannelFunc) BlendFunc {
+	return func(c1, c2 *rgba.RGBA) *rgba.RGBA {
+		return blendModeFunc(c1, c2, fn)
+	}
 }
 
 // blendModeFunc is a reusable function for applying a blend mode on each channel.
-func blendModeFunc(c1, c2 *rgba.RGBA, blendFunc func(uint8, uint8) float64) *rgba.RGBA {
-	a2 := float64(c2.A()) / 255.0
+func blendModeFunc(c1, c2 *rgba.RGBA, blendFunc func(float64, float64) float64) *rgba.RGBA {
+	a2 := c2.A() / 255.0
 
 	if a2 == 0 {
 		return c1
 	}
 
-	outR := uint8(math.Clamp(math.Round(float64(c1.R())*(1-a2)+blendFunc(c1.R(), c2.R())*a2), 0x00, 0xFF))
-	outG := uint8(math.Clamp(math.Round(float64(c1.G())*(1-a2)+blendFunc(c1.G(), c2.G())*a2), 0x00, 0xFF))
-	outB := uint8(math.Clamp(math.Round(float64(c1.B())*(1-a2)+blendFunc(c1.B(), c2.B())*a2), 0x00, 0xFF))
+	outR := math.Clamp(c1.R()*(1-a2)+blendFunc(c1.R(), c2.R())*a2, 0x00, 0xFF)
+	outG := math.Clamp(c1.G()*(1-a2)+blendFunc(c1.G(), c2.G())*a2, 0x00, 0xFF)
+	outB := math.Clamp(c1.B()*(1-a2)+blendFunc(c1.B(), c2.B())*a2, 0x00, 0xFF)
 
 	return rgba.New(outR, outG, outB, 0xFF)
 }
 
 // normal implements the "normal" blend mode with cumulative alpha.
 func normal(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	a2 := float64(c2.A()) / 255.0
+	a2 := c2.A() / 255.0
 
 	if a2 == 0 {
 		return c1
@@ -134,174 +202,149 @@ func normal(c1, c2 *rgba.RGBA) *rgba.RGBA {
 }
 
 // multiply implements the "multiply" blend mode.
-func multiply(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return (vSrc * vDst) / 255.0
-	})
+func multiply(src, dst float64) float64 {
+	return (src * dst) / 255.0
 }
 
 // lighten implements the "lighten" blend mode, where the lighter value of each color channel is selected.
-func lighten(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return math.Max(vSrc, vDst)
-	})
+func lighten(src, dst float64) float64 {
+	return math.Max(src, dst)
 }
 
 // darken implements the "darken" blend mode, where the darker value of each color channel is selected.
-func darken(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return math.Min(vSrc, vDst)
-	})
+func darken(src, dst float64) float64 {
+	return math.Min(src, dst)
 }
 
 // screen implements the "screen" blend mode.
-func screen(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return 255.0 * (1.0 - (1.0-vSrc/255.0)*(1.0-vDst/255.0))
-	})
+func screen(src, dst float64) float64 {
+	return 255.0 * (1.0 - (1.0-src/255.0)*(1.0-dst/255.0))
 }
 
 // add implements the "add" blend mode.
-func add(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return math.Min(vSrc+vDst, 255.0)
-	})
+func add(src, dst float64) float64 {
+	return math.Min(src+dst, 255.0)
 }
 
 // overlay implements the "overlay" blend mode with smoother transitions.
-func overlay(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src) / 255.0 // Normalize to [0, 1]
-		vDst := float64(dst) / 255.0
-
-		// Gamma-adjusted overlay blending for smoother transitions
-		if vSrc < 0.5 {
-			// Adjusted multiply for darker base colors
-			return 2 * vSrc * vDst * 255.0
-		}
-		// Adjusted screen for lighter base colors
-		return 255.0 * (1 - 2*(1-vSrc)*(1-vDst))
-	})
+func overlay(src, dst float64) float64 {
+	vSrc := src / 255.0 // Normalize to [0, 1]
+	vDst := dst / 255.0
+
+	// Gamma-adjusted overlay blending for smoother transitions
+	if vSrc < 0.5 {
+		// Adjusted multiply for darker base colors
+		return 2 * vSrc * vDst * 255.0
+	}
+	// Adjusted screen for lighter base colors
+	return 255.0 * (1 - 2*(1-vSrc)*(1-vDst))
 }
 
 // exclusion implements the "exclusion" blend mode.
-func exclusion(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return (vSrc + vDst) - 2.0*vSrc*vDst/255.0
-	})
+func exclusion(src, dst float64) float64 {
+	return (src + dst) - 2.0*src*dst/255.0
 }
 
 // negation implements the "negation" blend mode.
-func negation(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return (255.0 - math.Abs(255.0-vSrc-vDst))
-	})
+func negation(src, dst float64) float64 {
+	return (255.0 - math.Abs(255.0-src-dst))
 }
 
 // colorBurn implements the "color burn" blend mode.
-func colorBurn(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src) / 255.0
-		vDst := float64(dst) / 255.0
-		if vDst == 0 {
-			return 0
-		}
-		return 255.0 * (1.0 - math.Min(1.0, (1.0-vSrc)/vDst))
-	})
+func colorBurn(src, dst float64) float64 {
+	vSrc := src / 255.0
+	vDst := dst / 255.0
+	if vDst == 0 {
+		return 0
+	}
+	return 255.0 * (1.0 - math.Min(1.0, (1.0-vSrc)/vDst))
 }
 
 // linearBurn implements the "linear burn" blend mode.
-func linearBurn(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		return math.Max(0, vSrc+vDst-255.0)
-	})
+func linearBurn(src, dst float64) float64 {
+	return math.Max(0, src+dst-255.0)
 }
 
 // softLight implements the "soft light" blend mode.
-func softLight(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src) / 255.0
-		vDst := float64(dst) / 255.0
-		if vDst < 0.5 {
-			return 255.0 * (vSrc - (1.0-2.0*vDst)*vSrc*(1.0-vSrc))
-		}
-		return 255.0 * (vSrc + (2.0*vDst-1.0)*(math.Sqrt(vSrc)-vSrc))
-	})
+func softLight(src, dst float64) float64 {
+	vSrc := src / 255.0
+	vDst := dst / 255.0
+	if vDst < 0.5 {
+		return 255.0 * (vSrc - (1.0-2.0*vDst)*vSrc*(1.0-vSrc))
+	}
+	return 255.0 * (vSrc + (2.0*vDst-1.0)*(math.Sqrt(vSrc)-vSrc))
 }
 
 // hardLight implements the "hard light" blend mode.
-func hardLight(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vDst := float64(dst) / 255.0
-		if vDst < 0.5 {
-			return 2.0 * float64(src) * vDst
-		}
-		return 255.0 - 2.0*(255.0-float64(src))*(1.0-vDst)
-	})
+func hardLight(src, dst float64) float64 {
+	vDst := dst / 255.0
+	if vDst < 0.5 {
+		return 2.0 * src * vDst
+	}
+	return 255.0 - 2.0*(255.0-src)*(1.0-vDst)
 }
 
 // pinLight implements the "pin light" blend mode.
-func pinLight(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		if vDst < 128 {
-			return math.Min(vSrc, 2.0*vDst)
+func pinLight(src, dst float64) float64 {
+	if dst < 128 {
+		return math.Min(src, 2.0*dst)
+	}
+	return math.Max(src, 2.0*(dst-128.0))
+}
+
+// vividLight implements the "vivid light" blend mode, a color burn for dark and a color dodge for light blend colors.
+func vividLight(src, dst float64) float64 {
+	vSrc := src / 255.0
+	vDst := dst / 255.0
+	if vDst < 0.5 {
+		if vDst == 0 {
+			return 0
 		}
-		return math.Max(vSrc, 2.0*(vDst-128.0))
-	})
+		return 255.0 * (1.0 - math.Min(1.0, (1.0-vSrc)/(2.0*vDst)))
+	}
+	if vDst == 1 {
+		return 255
+	}
+	return 255.0 * math.Min(1.0, vSrc/(2.0*(1.0-vDst)))
+}
+
+// linearLight implements the "linear light" blend mode, a linear burn for dark and a linear dodge for light blend colors.
+func linearLight(src, dst float64) float64 {
+	return math.Clamp(src+2.0*dst-255.0, 0, 255.0)
+}
+
+// hardMix implements the "hard mix" blend mode, every channel becomes either 0 or 255.
+func hardMix(src, dst float64) float64 {
+	if src+dst >= 255.0 {
+		return 255
+	}
+	return 0
 }
 
 // difference implements the "difference" blend mode.
-func difference(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		return math.Abs(float64(src) - float64(dst))
-	})
+func difference(src, dst float64) float64 {
+	return math.Abs(src - dst)
 }
 
 // subtract implements the "subtract" blend mode.
-func subtract(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		return math.Max(0, float64(src)-float64(dst))
-	})
+func subtract(src, dst float64) float64 {
+	return math.Max(0, src-dst)
 }
 
 // divide implements the "divide" blend mode.
-func divide(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		vSrc := float64(src)
-		vDst := float64(dst)
-		if vDst == 0 {
-			return 255
-		}
-		return math.Min(255.0, vSrc*255.0/vDst)
-	})
+func divide(src, dst float64) float64 {
+	if dst == 0 {
+		return 255
+	}
+	return math.Min(255.0, src*255.0/dst)
 }
 
 // average implements the "average" blend mode.
-func average(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return blendModeFunc(c1, c2, func(src, dst uint8) float64 {
-		return (float64(src) + float64(dst)) / 2.0
-	})
+func average(src, dst float64) float64 {
+	return (src + dst) / 2.0
 }
 
 // erase implements the "erase" blend mode.
 func erase(c1, c2 *rgba.RGBA) *rgba.RGBA {
-	return rgba.New(c1.R(), c1.G(), c1.B(), uint8(math.Max(0, float64(c1.A())-float64(c2.A()))))
+	return rgba.New(c1.R(), c1.G(), c1.B(), math.Max(0, c1.A()-c2.A()))
 }
@@ -0,0 +1,62 @@
+package blend
+
+import (
+	"strings"
+
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/color/rgba"
+)
+
+// BlendSpace defines how color values are encoded while they are blended.
+type BlendSpace string
+
+const (
+	SPACE_SRGB   BlendSpace = "srgb"   // blends the gamma-encoded values directly, this is what most image editors do
+	SPACE_LINEAR BlendSpace = "linear" // blends in linear light, mixes of colors don't come out too dark
+)
+
+// BlendSpaces returns the names of all blend spaces.
+func BlendSpaces() []string {
+	return []string{string(SPACE_SRGB), string(SPACE_LINEAR)}
+}
+
+// ParseBlendSpace returns the blend space with the given name, unknown names return an empty blend space.
+func ParseBlendSpace(name string) BlendSpace {
+	switch s := BlendSpace(strings.ToLower(strings.TrimSpace(name))); s {
+	case SPACE_SRGB, SPACE_LINEAR:
+		return s
+	}
+	return ""
+}
+
+// toLinear returns a copy of col with the color channels converted from sRGB to linear light.
+func toLinear(col *rgba.RGBA) *rgba.RGBA {
+	return rgba.New(
+		convert.SRGBToLinear(col.R()/255.0)*255.0,
+		convert.SRGBToLinear(col.G()/255.0)*255.0,
+		convert.SRGBToLinear(col.B()/255.0)*255.0,
+		col.A(),
+	)
+}
+
+// toSRGB converts the color channels of col from linear light to sRGB.
+func toSRGB(col *rgba.RGBA) *rgba.RGBA {
+	return col.
+		SetR(convert.LinearToSRGB(col.R()/255.0) * 255.0).
+		SetG(convert.LinearToSRGB(col.G()/255.0) * 255.0).
+		SetB(convert.LinearToSRGB(col.B()/255.0) * 255.0)
+}
+
+// RGBAIn is like RGBA but blends in the given space. The colors and the result are always sRGB-encoded.
+func RGBAIn(c1, c2 *rgba.RGBA, mode BlendMode, alpha float64, space BlendSpace) *rgba.RGBA {
+	if space != SPACE_LINEAR {
+		return RGBA(c1, c2, mode, alpha)
+	}
+	return toSRGB(RGBA(toLinear(c1), toLinear(c2), mode, alpha))
+}
+
+// HSLAIn is like HSLA but blends in the given space.
+func HSLAIn(c1, c2 *hsla.HSLA, mode BlendMode, alpha float64, space BlendSpace) *hsla.HSLA {
+	return convert.RGBAToHSLA(RGBAIn(convert.HSLAToRGBA(c1), convert.HSLAToRGBA(c2), mode, alpha, space))
+}
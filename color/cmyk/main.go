@@ -0,0 +1,30 @@
+package cmyk
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// CMYK represents a color in the subtractive CMYK color space, without a color profile.
+type CMYK struct {
+	Cyan    float64 `yaml:"c"` // Cyan, as a fraction [0, 1]
+	Magenta float64 `yaml:"m"` // Magenta, as a fraction [0, 1]
+	Yellow  float64 `yaml:"y"` // Yellow, as a fraction [0, 1]
+	Key     float64 `yaml:"k"` // Key (black), as a fraction [0, 1]
+	Alpha   float64 `yaml:"a"` // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](c, m, y, k, a N) *CMYK {
+	return &CMYK{Cyan: float64(c), Magenta: float64(m), Yellow: float64(y), Key: float64(k), Alpha: float64(a)}
+}
+
+func (cmyk *CMYK) String() string {
+	return fmt.Sprintf("c: %f, m: %f, y: %f, k: %f, a: %f", cmyk.Cyan, cmyk.Magenta, cmyk.Yellow, cmyk.Key, cmyk.Alpha)
+}
+
+func (cmyk *CMYK) C() float64 { return cmyk.Cyan }
+func (cmyk *CMYK) M() float64 { return cmyk.Magenta }
+func (cmyk *CMYK) Y() float64 { return cmyk.Yellow }
+func (cmyk *CMYK) K() float64 { return cmyk.Key }
+func (cmyk *CMYK) A() float64 { return cmyk.Alpha }
@@ -0,0 +1,34 @@
+package convert
+
+import (
+	"github.com/toxyl/gfx/color/cmyk"
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/math"
+)
+
+// RGBToCMYK converts red, green and blue in the range [0, 1] to cyan, magenta, yellow and key (black),
+// all in the range [0, 1]. This is the naive conversion without a color profile.
+func RGBToCMYK(r, g, b float64) (c, m, y, k float64) {
+	max := math.Max(r, math.Max(g, b))
+	if max == 0 {
+		return 0, 0, 0, 1
+	}
+	return (max - r) / max, (max - g) / max, (max - b) / max, 1 - max
+}
+
+// CMYKToRGB converts cyan, magenta, yellow and key (black) in the range [0, 1] to red, green and blue.
+func CMYKToRGB(c, m, y, k float64) (r, g, b float64) {
+	return (1 - c) * (1 - k), (1 - m) * (1 - k), (1 - y) * (1 - k)
+}
+
+// RGBAToCMYK converts a RGBA color to a CMYK color.
+func RGBAToCMYK(col *rgba.RGBA) *cmyk.CMYK {
+	c, m, y, k := RGBToCMYK(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return cmyk.New(c, m, y, k, col.A()/255.0)
+}
+
+// CMYKToRGBA converts a CMYK color to a RGBA color.
+func CMYKToRGBA(col *cmyk.CMYK) *rgba.RGBA {
+	r, g, b := CMYKToRGB(col.C(), col.M(), col.Y(), col.K())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
@@ -0,0 +1,69 @@
+package convert
+
+import (
+	"github.com/toxyl/gfx/color/hsva"
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/math"
+)
+
+// RGBAToHSVA converts a RGBA color to a HSVA color.
+func RGBAToHSVA(col *rgba.RGBA) *hsva.HSVA {
+	h, s, v := RGBToHSV(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return hsva.New(h, s, v, col.A()/255.0)
+}
+
+// HSVAToRGBA converts a HSVA color to a RGBA color.
+func HSVAToRGBA(col *hsva.HSVA) *rgba.RGBA {
+	r, g, b := HSVToRGB(col.H(), col.S(), col.V())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
+
+// RGBToHSV converts red, green and blue in the range [0, 1] to hue (in degrees [0, 360)), saturation and value.
+func RGBToHSV(r, g, b float64) (h, s, v float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+	if max == 0 {
+		return 0, 0, 0
+	}
+	if delta == 0 {
+		return 0, 0, max
+	}
+
+	if max == r {
+		h = math.Mod((g-b)/delta, 6)
+	} else if max == g {
+		h = (b-r)/delta + 2
+	} else {
+		h = (r-g)/delta + 4
+	}
+
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, delta / max, max
+}
+
+// HSVToRGB converts hue (in degrees [0, 360)), saturation and value to red, green and blue in the range [0, 1].
+func HSVToRGB(h, s, v float64) (r, g, b float64) {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	switch {
+	case 0 <= h && h < 60:
+		r, g, b = c, x, 0
+	case 60 <= h && h < 120:
+		r, g, b = x, c, 0
+	case 120 <= h && h < 180:
+		r, g, b = 0, c, x
+	case 180 <= h && h < 240:
+		r, g, b = 0, x, c
+	case 240 <= h && h < 300:
+		r, g, b = x, 0, c
+	case 300 <= h && h < 360:
+		r, g, b = c, 0, x
+	}
+	return r + m, g + m, b + m
+}
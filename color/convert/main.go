@@ -6,55 +6,50 @@ import (
 	"github.com/toxyl/gfx/color/hsla"
 	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/math"
-	"github.com/toxyl/gfx/vars"
 )
 
 // RGBAToHSLA convert a RGBA color to a HSLA color.
 func RGBAToHSLA(col *rgba.RGBA) *hsla.HSLA {
-	r := float64(col.R()) / 255.0
-	g := float64(col.G()) / 255.0
-	b := float64(col.B()) / 255.0
-	a := float64(col.A()) / 255.0
+	h, s, l := RGBToHSL(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return hsla.New(h, s, l, col.A()/255.0)
+}
 
+// RGBToHSL converts red, green and blue in the range [0, 1] to hue (in degrees [0, 360)), saturation and lightness.
+func RGBToHSL(r, g, b float64) (h, s, l float64) {
 	max := math.Max(r, math.Max(g, b))
 	min := math.Min(r, math.Min(g, b))
-	l := (max + min) / 2
+	l = (max + min) / 2
 
-	var h, s float64
 	delta := max - min
-
 	if delta == 0 {
-		h = 0
-		s = 0
+		return 0, 0, l
+	}
+
+	if max == r {
+		h = math.Mod((g-b)/delta, 6)
+	} else if max == g {
+		h = (b-r)/delta + 2
 	} else {
-		if max == r {
-			h = math.Mod((g-b)/delta, 6)
-		} else if max == g {
-			h = (b-r)/delta + 2
-		} else {
-			h = (r-g)/delta + 4
-		}
-
-		h *= 60
-		if h < 0 {
-			h += 360
-		}
+		h = (r-g)/delta + 4
+	}
 
-		s = delta / (1 - math.Abs(2*l-1))
+	h *= 60
+	if h < 0 {
+		h += 360
 	}
 
-	return hsla.New(h, s, l, a)
+	s = delta / (1 - math.Abs(2*l-1))
+	return h, s, l
 }
 
 // HSLAToRGBA converts a HSLA color to a RGBA color.
 func HSLAToRGBA(col *hsla.HSLA) *rgba.RGBA {
-	// Normalize the hue value to the range [0, 360)
-	h := col.H() // math.Wrap(, 0, 360)
-	s := col.S()
-	l := col.L()
-
-	var r, g, b, a float64
+	r, g, b := HSLToRGB(col.H(), col.S(), col.L())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
 
+// HSLToRGB converts hue (in degrees [0, 360)), saturation and lightness to red, green and blue in the range [0, 1].
+func HSLToRGB(h, s, l float64) (r, g, b float64) {
 	c := (1 - math.Abs(2*l-1)) * s
 	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
 	m := l - c/2
@@ -73,39 +68,18 @@ func HSLAToRGBA(col *hsla.HSLA) *rgba.RGBA {
 	case 300 <= h && h < 360:
 		r, g, b = c, 0, x
 	}
-
-	r = math.Round((r + m) * 0xFF)
-	g = math.Round((g + m) * 0xFF)
-	b = math.Round((b + m) * 0xFF)
-	a = math.Round(col.A() * 0xFF)
-
-	return rgba.New(r, g, b, a)
+	return r + m, g + m, b + m
 }
 
 // RGBAToRGBAPremul converts a RGBA color to a premultiplied RGBA color.
 func RGBAToRGBAPremul(col *rgba.RGBA) color.RGBA {
-	res := color.RGBA{
-		R: col.R(),
-		G: col.G(),
-		B: col.B(),
-		A: 0xFF,
-	}
-	if res.A == 0 { // Avoid division by zero if original alpha is zero
-		return color.RGBA{0, 0, 0, 0}
-	}
-	scale := float32(col.A()) / float32(res.A)
-	return color.RGBA{
-		uint8(float32(res.R) * scale),
-		uint8(float32(res.G) * scale),
-		uint8(float32(res.B) * scale),
-		col.A(),
-	}
+	return col.RGBA()
 }
 
 // RGBAPremulToRGBA converts a premultiplied RGBA color to a RGBA color.
 func RGBAPremulToRGBA(col color.RGBA) *rgba.RGBA {
 	if col.A == 0 {
-		return vars.COLOR_TRANSPARENT_RGBA // If alpha is zero, return fully transparent black (0, 0, 0, 0).
+		return rgba.New(0, 0, 0, 0) // If alpha is zero, return fully transparent black (0, 0, 0, 0).
 	}
 	scale := 255.0 / float32(col.A)
 	return rgba.New(
@@ -0,0 +1,92 @@
+package convert
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/color/oklab"
+	"github.com/toxyl/gfx/color/oklch"
+	"github.com/toxyl/gfx/color/rgba"
+)
+
+// SRGBToLinear converts a gamma-encoded sRGB channel value in the range [0, 1] to linear light.
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToSRGB converts a linear light channel value in the range [0, 1] to gamma-encoded sRGB.
+func LinearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// RGBToOKLab converts sRGB red, green and blue in the range [0, 1] to OKLab lightness l (in the range [0, 1])
+// and the opponent axes a (green-red) and b (blue-yellow), which stay roughly within [-0.4, 0.4].
+func RGBToOKLab(r, g, b float64) (l, a, bb float64) {
+	r, g, b = SRGBToLinear(r), SRGBToLinear(g), SRGBToLinear(b)
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc,
+		1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc,
+		0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+}
+
+// OKLabToLinearRGB converts OKLab lightness l and the opponent axes a and bb to linear red, green and blue.
+// Colors outside of the sRGB gamut have channels outside of the range [0, 1].
+func OKLabToLinearRGB(l, a, bb float64) (r, g, b float64) {
+	lc := l + 0.3963377774*a + 0.2158037573*bb
+	mc := l - 0.1055613458*a - 0.0638541728*bb
+	sc := l - 0.0894841775*a - 1.2914855480*bb
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+	return +4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc,
+		-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc,
+		-0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+}
+
+// OKLabToRGB converts OKLab lightness l and the opponent axes a and bb to sRGB red, green and blue.
+// Colors outside of the sRGB gamut are clamped to the range [0, 1].
+func OKLabToRGB(l, a, bb float64) (r, g, b float64) {
+	r, g, b = OKLabToLinearRGB(l, a, bb)
+	return linearToSRGBClamped(r), linearToSRGBClamped(g), linearToSRGBClamped(b)
+}
+
+// RGBToOKLCh converts sRGB red, green and blue in the range [0, 1] to OKLCh lightness (in the range [0, 1]),
+// chroma (up to about 0.32 for sRGB colors) and hue (in degrees [0, 360)).
+func RGBToOKLCh(r, g, b float64) (l, c, h float64) {
+	return LabToLCh(RGBToOKLab(r, g, b))
+}
+
+// OKLChToRGB converts OKLCh to sRGB red, green and blue.
+// Colors outside of the sRGB gamut are clamped to the range [0, 1].
+func OKLChToRGB(l, c, h float64) (r, g, b float64) {
+	return OKLabToRGB(LChToLab(l, c, h))
+}
+
+// RGBAToOKLab converts a RGBA color to an OKLab color.
+func RGBAToOKLab(col *rgba.RGBA) *oklab.OKLab {
+	l, a, b := RGBToOKLab(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return oklab.New(l, a, b, col.A()/255.0)
+}
+
+// OKLabToRGBA converts an OKLab color to a RGBA color.
+func OKLabToRGBA(col *oklab.OKLab) *rgba.RGBA {
+	r, g, b := OKLabToRGB(col.L(), col.GreenRed(), col.BlueYellow())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
+
+// RGBAToOKLCh converts a RGBA color to an OKLCh color.
+func RGBAToOKLCh(col *rgba.RGBA) *oklch.OKLCh {
+	l, c, h := RGBToOKLCh(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return oklch.New(l, c, h, col.A()/255.0)
+}
+
+// OKLChToRGBA converts an OKLCh color to a RGBA color.
+func OKLChToRGBA(col *oklch.OKLCh) *rgba.RGBA {
+	r, g, b := OKLChToRGB(col.L(), col.C(), col.H())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
@@ -0,0 +1,147 @@
+package convert
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/color/lab"
+	"github.com/toxyl/gfx/color/lch"
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/color/xyz"
+)
+
+// D65 reference white of CIE XYZ, Lab and LCh.
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// RGBToXYZ converts sRGB red, green and blue in the range [0, 1] to CIE XYZ (D65), Y is in the range [0, 1].
+func RGBToXYZ(r, g, b float64) (x, y, z float64) {
+	r, g, b = SRGBToLinear(r), SRGBToLinear(g), SRGBToLinear(b)
+	return 0.4124564*r + 0.3575761*g + 0.1804375*b,
+		0.2126729*r + 0.7151522*g + 0.0721750*b,
+		0.0193339*r + 0.1191920*g + 0.9503041*b
+}
+
+// XYZToLinearRGB converts CIE XYZ (D65) to linear red, green and blue.
+// Colors outside of the sRGB gamut have channels outside of the range [0, 1].
+func XYZToLinearRGB(x, y, z float64) (r, g, b float64) {
+	return 3.2404542*x - 1.5371385*y - 0.4985314*z,
+		-0.9692660*x + 1.8760108*y + 0.0415560*z,
+		0.0556434*x - 0.2040259*y + 1.0572252*z
+}
+
+// XYZToRGB converts CIE XYZ (D65) to sRGB red, green and blue.
+// Colors outside of the sRGB gamut are clamped to the range [0, 1].
+func XYZToRGB(x, y, z float64) (r, g, b float64) {
+	r, g, b = XYZToLinearRGB(x, y, z)
+	return linearToSRGBClamped(r), linearToSRGBClamped(g), linearToSRGBClamped(b)
+}
+
+func linearToSRGBClamped(v float64) float64 { return LinearToSRGB(math.Min(1, math.Max(0, v))) }
+
+func labF(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d*d*d {
+		return math.Cbrt(t)
+	}
+	return t/(3*d*d) + 4.0/29
+}
+
+func labFInv(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d {
+		return t * t * t
+	}
+	return 3 * d * d * (t - 4.0/29)
+}
+
+// XYZToLab converts CIE XYZ (D65) to CIELAB lightness l (in the range [0, 100]) and the opponent axes a (green-red)
+// and bb (blue-yellow), which stay roughly within [-128, 127] for sRGB colors.
+func XYZToLab(x, y, z float64) (l, a, bb float64) {
+	fx, fy, fz := labF(x/whiteX), labF(y/whiteY), labF(z/whiteZ)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// LabToXYZ converts CIELAB lightness l and the opponent axes a and bb to CIE XYZ (D65).
+func LabToXYZ(l, a, bb float64) (x, y, z float64) {
+	fy := (l + 16) / 116
+	return whiteX * labFInv(fy+a/500), whiteY * labFInv(fy), whiteZ * labFInv(fy-bb/200)
+}
+
+// RGBToLab converts sRGB red, green and blue in the range [0, 1] to CIELAB.
+func RGBToLab(r, g, b float64) (l, a, bb float64) {
+	return XYZToLab(RGBToXYZ(r, g, b))
+}
+
+// LabToRGB converts CIELAB to sRGB red, green and blue.
+// Colors outside of the sRGB gamut are clamped to the range [0, 1].
+func LabToRGB(l, a, bb float64) (r, g, b float64) {
+	return XYZToRGB(LabToXYZ(l, a, bb))
+}
+
+// LabToLCh converts the opponent axes a and b of CIELAB or OKLab to chroma c and hue h (in degrees [0, 360)),
+// the lightness is passed through.
+func LabToLCh(l, a, b float64) (l2, c, h float64) {
+	h = math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return l, math.Hypot(a, b), h
+}
+
+// LChToLab converts chroma c and hue h (in degrees) to the opponent axes a and b of CIELAB or OKLab,
+// the lightness is passed through.
+func LChToLab(l, c, h float64) (l2, a, b float64) {
+	sin, cos := math.Sincos(h * math.Pi / 180)
+	return l, c * cos, c * sin
+}
+
+// RGBToLCh converts sRGB red, green and blue in the range [0, 1] to CIE LCh(ab) lightness (in the range [0, 100]),
+// chroma (up to about 134 for sRGB colors) and hue (in degrees [0, 360)).
+func RGBToLCh(r, g, b float64) (l, c, h float64) {
+	return LabToLCh(RGBToLab(r, g, b))
+}
+
+// LChToRGB converts CIE LCh(ab) to sRGB red, green and blue.
+// Colors outside of the sRGB gamut are clamped to the range [0, 1].
+func LChToRGB(l, c, h float64) (r, g, b float64) {
+	return LabToRGB(LChToLab(l, c, h))
+}
+
+// RGBAToXYZ converts a RGBA color to a CIE XYZ color.
+func RGBAToXYZ(col *rgba.RGBA) *xyz.XYZ {
+	x, y, z := RGBToXYZ(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return xyz.New(x, y, z, col.A()/255.0)
+}
+
+// XYZToRGBA converts a CIE XYZ color to a RGBA color.
+func XYZToRGBA(col *xyz.XYZ) *rgba.RGBA {
+	r, g, b := XYZToRGB(col.X(), col.Y(), col.Z())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
+
+// RGBAToLab converts a RGBA color to a CIELAB color.
+func RGBAToLab(col *rgba.RGBA) *lab.Lab {
+	l, a, b := RGBToLab(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return lab.New(l, a, b, col.A()/255.0)
+}
+
+// LabToRGBA converts a CIELAB color to a RGBA color.
+func LabToRGBA(col *lab.Lab) *rgba.RGBA {
+	r, g, b := LabToRGB(col.L(), col.GreenRed(), col.BlueYellow())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
+
+// RGBAToLCh converts a RGBA color to a CIE LCh color.
+func RGBAToLCh(col *rgba.RGBA) *lch.LCh {
+	l, c, h := RGBToLCh(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return lch.New(l, c, h, col.A()/255.0)
+}
+
+// LChToRGBA converts a CIE LCh color to a RGBA color.
+func LChToRGBA(col *lch.LCh) *rgba.RGBA {
+	r, g, b := LChToRGB(col.L(), col.C(), col.H())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
@@ -0,0 +1,36 @@
+package convert
+
+import (
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/color/ycbcr"
+	"github.com/toxyl/gfx/math"
+)
+
+// RGBToYCbCr converts red, green and blue in the range [0, 1] to full range YCbCr (ITU-R BT.601, as used by JPEG).
+// All values are in the range [0, 1], the chroma components are centered around 0.5.
+func RGBToYCbCr(r, g, b float64) (y, cb, cr float64) {
+	return 0.299*r + 0.587*g + 0.114*b,
+		0.5 - 0.168736*r - 0.331264*g + 0.5*b,
+		0.5 + 0.5*r - 0.418688*g - 0.081312*b
+}
+
+// YCbCrToRGB converts full range YCbCr (ITU-R BT.601) to red, green and blue.
+// Colors outside of the RGB gamut are clamped to the range [0, 1].
+func YCbCrToRGB(y, cb, cr float64) (r, g, b float64) {
+	cb, cr = cb-0.5, cr-0.5
+	return math.Clamp(y+1.402*cr, 0, 1),
+		math.Clamp(y-0.344136*cb-0.714136*cr, 0, 1),
+		math.Clamp(y+1.772*cb, 0, 1)
+}
+
+// RGBAToYCbCr converts a RGBA color to a YCbCr color.
+func RGBAToYCbCr(col *rgba.RGBA) *ycbcr.YCbCr {
+	y, cb, cr := RGBToYCbCr(col.R()/255.0, col.G()/255.0, col.B()/255.0)
+	return ycbcr.New(y, cb, cr, col.A()/255.0)
+}
+
+// YCbCrToRGBA converts a YCbCr color to a RGBA color.
+func YCbCrToRGBA(col *ycbcr.YCbCr) *rgba.RGBA {
+	r, g, b := YCbCrToRGB(col.Y(), col.Cb(), col.Cr())
+	return rgba.New(r*0xFF, g*0xFF, b*0xFF, col.A()*0xFF)
+}
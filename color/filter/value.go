@@ -23,11 +23,9 @@ func NewFilterValue(min, max, tolerance float64) FilterValue {
 	return fv
 }
 
+// ToFilterValue returns the range val +/- tolerance, a feather larger than tolerance is reduced to tolerance.
 func ToFilterValue(val, tolerance, feather float64) FilterValue {
-	if feather > tolerance {
-		fmt.Printf("feather exceeds tolerance, decreasing feather from %f to %f\n", feather, tolerance)
-		feather = tolerance
-	}
+	feather = math.Min(feather, tolerance)
 	// Calculate min and max range based on value and tolerance
 	mn := val - tolerance
 	mx := val + tolerance
@@ -0,0 +1,28 @@
+package hsva
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// HSVA represents a color in the HSV (hue, saturation, value) color space.
+type HSVA struct {
+	Hue   float64 `yaml:"h"` // Hue, in degrees [0, 360)
+	Sat   float64 `yaml:"s"` // Saturation, as a fraction [0, 1]
+	Val   float64 `yaml:"v"` // Value, as a fraction [0, 1]
+	Alpha float64 `yaml:"a"` // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](h, s, v, a N) *HSVA {
+	return &HSVA{Hue: math.Wrap(float64(h), 0.0, 360.0), Sat: float64(s), Val: float64(v), Alpha: float64(a)}
+}
+
+func (hsva *HSVA) String() string {
+	return fmt.Sprintf("h: %f, s: %f, v: %f, a: %f", hsva.Hue, hsva.Sat, hsva.Val, hsva.Alpha)
+}
+
+func (hsva *HSVA) H() float64 { return hsva.Hue }
+func (hsva *HSVA) S() float64 { return hsva.Sat }
+func (hsva *HSVA) V() float64 { return hsva.Val }
+func (hsva *HSVA) A() float64 { return hsva.Alpha }
@@ -0,0 +1,28 @@
+package lab
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// Lab represents a color in the CIELAB color space with the D65 white point.
+type Lab struct {
+	Lightness float64 `yaml:"l"`           // Lightness, in the range [0, 100]
+	ValueA    float64 `yaml:"green-red"`   // Green (negative) to red (positive), roughly in the range [-128, 127]
+	ValueB    float64 `yaml:"blue-yellow"` // Blue (negative) to yellow (positive), roughly in the range [-128, 127]
+	Alpha     float64 `yaml:"a"`           // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](l, a, b, alpha N) *Lab {
+	return &Lab{Lightness: float64(l), ValueA: float64(a), ValueB: float64(b), Alpha: float64(alpha)}
+}
+
+func (lab *Lab) String() string {
+	return fmt.Sprintf("l: %f, green-red: %f, blue-yellow: %f, a: %f", lab.Lightness, lab.ValueA, lab.ValueB, lab.Alpha)
+}
+
+func (lab *Lab) L() float64          { return lab.Lightness }
+func (lab *Lab) GreenRed() float64   { return lab.ValueA }
+func (lab *Lab) BlueYellow() float64 { return lab.ValueB }
+func (lab *Lab) A() float64          { return lab.Alpha }
@@ -0,0 +1,28 @@
+package lch
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// LCh represents a color in the CIE LCh(ab) color space, the polar form of CIELAB.
+type LCh struct {
+	Lightness float64 `yaml:"l"` // Lightness, in the range [0, 100]
+	Chroma    float64 `yaml:"c"` // Chroma, up to about 134 for sRGB colors
+	Hue       float64 `yaml:"h"` // Hue, in degrees [0, 360)
+	Alpha     float64 `yaml:"a"` // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](l, c, h, a N) *LCh {
+	return &LCh{Lightness: float64(l), Chroma: float64(c), Hue: math.Wrap(float64(h), 0.0, 360.0), Alpha: float64(a)}
+}
+
+func (lch *LCh) String() string {
+	return fmt.Sprintf("l: %f, c: %f, h: %f, a: %f", lch.Lightness, lch.Chroma, lch.Hue, lch.Alpha)
+}
+
+func (lch *LCh) L() float64 { return lch.Lightness }
+func (lch *LCh) C() float64 { return lch.Chroma }
+func (lch *LCh) H() float64 { return lch.Hue }
+func (lch *LCh) A() float64 { return lch.Alpha }
@@ -0,0 +1,28 @@
+package oklab
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// OKLab represents a color in the perceptually uniform OKLab color space.
+type OKLab struct {
+	Lightness float64 `yaml:"l"`           // Lightness, as a fraction [0, 1]
+	ValueA    float64 `yaml:"green-red"`   // Green (negative) to red (positive), roughly in the range [-0.4, 0.4]
+	ValueB    float64 `yaml:"blue-yellow"` // Blue (negative) to yellow (positive), roughly in the range [-0.4, 0.4]
+	Alpha     float64 `yaml:"a"`           // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](l, a, b, alpha N) *OKLab {
+	return &OKLab{Lightness: float64(l), ValueA: float64(a), ValueB: float64(b), Alpha: float64(alpha)}
+}
+
+func (oklab *OKLab) String() string {
+	return fmt.Sprintf("l: %f, green-red: %f, blue-yellow: %f, a: %f", oklab.Lightness, oklab.ValueA, oklab.ValueB, oklab.Alpha)
+}
+
+func (oklab *OKLab) L() float64          { return oklab.Lightness }
+func (oklab *OKLab) GreenRed() float64   { return oklab.ValueA }
+func (oklab *OKLab) BlueYellow() float64 { return oklab.ValueB }
+func (oklab *OKLab) A() float64          { return oklab.Alpha }
@@ -0,0 +1,28 @@
+package oklch
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// OKLCh represents a color in the OKLCh color space, the polar form of OKLab.
+type OKLCh struct {
+	Lightness float64 `yaml:"l"` // Lightness, as a fraction [0, 1]
+	Chroma    float64 `yaml:"c"` // Chroma, up to about 0.32 for sRGB colors
+	Hue       float64 `yaml:"h"` // Hue, in degrees [0, 360)
+	Alpha     float64 `yaml:"a"` // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](l, c, h, a N) *OKLCh {
+	return &OKLCh{Lightness: float64(l), Chroma: float64(c), Hue: math.Wrap(float64(h), 0.0, 360.0), Alpha: float64(a)}
+}
+
+func (oklch *OKLCh) String() string {
+	return fmt.Sprintf("l: %f, c: %f, h: %f, a: %f", oklch.Lightness, oklch.Chroma, oklch.Hue, oklch.Alpha)
+}
+
+func (oklch *OKLCh) L() float64 { return oklch.Lightness }
+func (oklch *OKLCh) C() float64 { return oklch.Chroma }
+func (oklch *OKLCh) H() float64 { return oklch.Hue }
+func (oklch *OKLCh) A() float64 { return oklch.Alpha }
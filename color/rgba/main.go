@@ -7,58 +7,53 @@ import (
 	"github.com/toxyl/gfx/math"
 )
 
-// RGBA is similar to color.RGBA but doesn't store RGB with premultiplied alpha
+// RGBA is similar to color.RGBA but doesn't store RGB with premultiplied alpha.
+// Channels are stored as float64 in the range [0, 255], so no precision is lost between operations.
 type RGBA struct {
-	Red   uint8 `yaml:"r"`
-	Green uint8 `yaml:"g"`
-	Blue  uint8 `yaml:"b"`
-	Alpha uint8 `yaml:"a"`
+	Red   float64 `yaml:"r"`
+	Green float64 `yaml:"g"`
+	Blue  float64 `yaml:"b"`
+	Alpha float64 `yaml:"a"`
 }
 
 func New[N math.Number](r, g, b, a N) *RGBA {
 	rgba := RGBA{
-		Red:   uint8(math.Round(r)),
-		Green: uint8(math.Round(g)),
-		Blue:  uint8(math.Round(b)),
-		Alpha: uint8(math.Round(a)),
+		Red:   math.Clamp(float64(r), 0, 255),
+		Green: math.Clamp(float64(g), 0, 255),
+		Blue:  math.Clamp(float64(b), 0, 255),
+		Alpha: math.Clamp(float64(a), 0, 255),
 	}
 	return &rgba
 }
 
 func (rgba *RGBA) String() string {
-	return fmt.Sprintf("r: %d, g: %d, b: %d, a: %d", rgba.Red, rgba.Green, rgba.Blue, rgba.Alpha)
+	return fmt.Sprintf("r: %f, g: %f, b: %f, a: %f", rgba.Red, rgba.Green, rgba.Blue, rgba.Alpha)
 }
 
-func (rgba *RGBA) R() uint8 { return rgba.Red }
-func (rgba *RGBA) G() uint8 { return rgba.Green }
-func (rgba *RGBA) B() uint8 { return rgba.Blue }
-func (rgba *RGBA) A() uint8 { return rgba.Alpha }
+func (rgba *RGBA) R() float64 { return rgba.Red }
+func (rgba *RGBA) G() float64 { return rgba.Green }
+func (rgba *RGBA) B() float64 { return rgba.Blue }
+func (rgba *RGBA) A() float64 { return rgba.Alpha }
 
-func (rgba *RGBA) SetR(v uint8) *RGBA { rgba.Red = v; return rgba }
-func (rgba *RGBA) SetG(v uint8) *RGBA { rgba.Green = v; return rgba }
-func (rgba *RGBA) SetB(v uint8) *RGBA { rgba.Blue = v; return rgba }
-func (rgba *RGBA) SetA(v uint8) *RGBA { rgba.Alpha = v; return rgba }
+func (rgba *RGBA) SetR(v float64) *RGBA { rgba.Red = math.Clamp(v, 0, 255); return rgba }
+func (rgba *RGBA) SetG(v float64) *RGBA { rgba.Green = math.Clamp(v, 0, 255); return rgba }
+func (rgba *RGBA) SetB(v float64) *RGBA { rgba.Blue = math.Clamp(v, 0, 255); return rgba }
+func (rgba *RGBA) SetA(v float64) *RGBA { rgba.Alpha = math.Clamp(v, 0, 255); return rgba }
 
 func (rgba *RGBA) RGB() RGBA {
 	return RGBA{Red: rgba.Red, Green: rgba.Green, Blue: rgba.Blue, Alpha: 0xFF}
 }
 
-// RGBA returns a version of the color that is alpha-premultiplied.
+// RGBA returns a version of the color that is alpha-premultiplied and quantized to 8 bits.
 func (rgba *RGBA) RGBA() color.RGBA {
-	c := color.RGBA{
-		R: rgba.Red,
-		G: rgba.Green,
-		B: rgba.Blue,
-		A: 0xFF,
-	}
-	if c.A == 0 { // Avoid division by zero if original alpha is zero
+	if rgba.Alpha == 0 {
 		return color.RGBA{0, 0, 0, 0}
 	}
-	scale := float32(rgba.Alpha) / float32(c.A)
+	scale := rgba.Alpha / 255.0
 	return color.RGBA{
-		R: uint8(float32(c.R) * scale),
-		G: uint8(float32(c.G) * scale),
-		B: uint8(float32(c.B) * scale),
-		A: rgba.Alpha,
+		R: uint8(math.Round(rgba.Red * scale)),
+		G: uint8(math.Round(rgba.Green * scale)),
+		B: uint8(math.Round(rgba.Blue * scale)),
+		A: uint8(math.Round(rgba.Alpha)),
 	}
 }
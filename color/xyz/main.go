@@ -0,0 +1,28 @@
+package xyz
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// XYZ represents a color in the CIE 1931 XYZ color space with the D65 white point.
+type XYZ struct {
+	ValueX float64 `yaml:"x"` // X, roughly in the range [0, 0.95] for sRGB colors
+	ValueY float64 `yaml:"y"` // Y (relative luminance), as a fraction [0, 1]
+	ValueZ float64 `yaml:"z"` // Z, roughly in the range [0, 1.09] for sRGB colors
+	Alpha  float64 `yaml:"a"` // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](x, y, z, a N) *XYZ {
+	return &XYZ{ValueX: float64(x), ValueY: float64(y), ValueZ: float64(z), Alpha: float64(a)}
+}
+
+func (xyz *XYZ) String() string {
+	return fmt.Sprintf("x: %f, y: %f, z: %f, a: %f", xyz.ValueX, xyz.ValueY, xyz.ValueZ, xyz.Alpha)
+}
+
+func (xyz *XYZ) X() float64 { return xyz.ValueX }
+func (xyz *XYZ) Y() float64 { return xyz.ValueY }
+func (xyz *XYZ) Z() float64 { return xyz.ValueZ }
+func (xyz *XYZ) A() float64 { return xyz.Alpha }
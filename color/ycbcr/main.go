@@ -0,0 +1,28 @@
+package ycbcr
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// YCbCr represents a color in the full range YCbCr color space (ITU-R BT.601, as used by JPEG).
+type YCbCr struct {
+	Luma  float64 `yaml:"y"`  // Luma, as a fraction [0, 1]
+	Blue  float64 `yaml:"cb"` // Blue-difference chroma, as a fraction [0, 1] centered around 0.5
+	Red   float64 `yaml:"cr"` // Red-difference chroma, as a fraction [0, 1] centered around 0.5
+	Alpha float64 `yaml:"a"`  // Alpha, as a fraction [0, 1]
+}
+
+func New[N math.Number](y, cb, cr, a N) *YCbCr {
+	return &YCbCr{Luma: float64(y), Blue: float64(cb), Red: float64(cr), Alpha: float64(a)}
+}
+
+func (ycbcr *YCbCr) String() string {
+	return fmt.Sprintf("y: %f, cb: %f, cr: %f, a: %f", ycbcr.Luma, ycbcr.Blue, ycbcr.Red, ycbcr.Alpha)
+}
+
+func (ycbcr *YCbCr) Y() float64  { return ycbcr.Luma }
+func (ycbcr *YCbCr) Cb() float64 { return ycbcr.Blue }
+func (ycbcr *YCbCr) Cr() float64 { return ycbcr.Red }
+func (ycbcr *YCbCr) A() float64  { return ycbcr.Alpha }
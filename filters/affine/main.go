@@ -0,0 +1,47 @@
+package affine
+
+import (
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("affine", []*meta.FilterMetaDataArg{
+	{Name: "translate-x", Default: 0.0},
+	{Name: "translate-y", Default: 0.0},
+	{Name: "rotate", Default: 0.0},
+	{Name: "scale-x", Default: 0.0},
+	{Name: "scale-y", Default: 0.0},
+	{Name: "shear-x", Default: 0.0},
+	{Name: "shear-y", Default: 0.0},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply performs a composite affine transformation on the image with a single resampling step in the following order:
+//  1. Scale (around the effective center)
+//  2. Shear (around the effective center)
+//  3. Rotate (around the effective center)
+//  4. Translate
+//
+// The parameters:
+//   - translate-x, translate-y: percentages (-1..1) of the image size to translate by, fractions are sampled sub-pixel.
+//   - rotate: rotation angle in degrees, positive angles rotate clockwise.
+//   - scale-x, scale-y: percentages to scale the image; 0 means no change, 0.5 means factor 1.5, -0.5 means factor 0.5.
+//   - shear-x, shear-y: shear factors; shear-x shifts rows horizontally by shear-x pixels per pixel of distance from the center.
+//   - offset-x, offset-y: offsets (-1..1) from the image center that define the center of the transformation.
+//   - resample: the resampling kernel.
+//   - edge: what is sampled outside of the source image (transparent, clamp, wrap or mirror).
+func Apply(img *image.Image, translateX, translateY, rotate, scaleX, scaleY, shearX, shearY, offsetX, offsetY float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	hw := float64(img.CW())
+	hh := float64(img.CH())
+	m := image.Scaling(1+scaleX, 1+scaleY).
+		Shear(shearX, shearY).
+		Rotate(rotate).
+		Around(hw+offsetX*hw, hh+offsetY*hh).
+		Translate(translateX*float64(img.W()), translateY*float64(img.H()))
+	img.Set(img.Warp(m, kernel, edge).Get())
+	return img
+}
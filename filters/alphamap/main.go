@@ -1,6 +1,7 @@
 package alphamap
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/toxyl/gfx/color/hsla"
@@ -9,13 +10,18 @@ import (
 )
 
 var Meta = meta.New("alpha-map", []*meta.FilterMetaDataArg{
-	{Name: "source", Default: "l"},
+	{Name: "source", Default: "l", Values: []string{"s", "l", "s*l"}},
 	{Name: "lower", Default: 0.0},
 	{Name: "upper", Default: 0.0},
 })
 
+// Apply maps the saturation (s), luminance (l) or both (s*l) of each pixel to its alpha channel.
+// If source is none of these, the image is returned unchanged.
 func Apply(i *image.Image, source string, lowerThreshold, upperThreshold float64) *image.Image {
 	alphaSrc := strings.ToLower(source)
+	if !slices.Contains(Meta.Args[0].Values, alphaSrc) {
+		return i
+	}
 	minVal := lowerThreshold
 	maxVal := upperThreshold
 	invert := minVal > maxVal
@@ -31,8 +37,6 @@ func Apply(i *image.Image, source string, lowerThreshold, upperThreshold float64
 			val = col.L()
 		case "s*l":
 			val = col.S() * col.L()
-		default:
-			panic("invalid alpha source, available options are: s, l, s*l")
 		}
 
 		if (invert && val <= minVal) || (!invert && val >= maxVal) {
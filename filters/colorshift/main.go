@@ -1,19 +1,22 @@
 package colorshift
 
 import (
-	"github.com/toxyl/gfx/color/hsla"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/math"
 )
 
 var Meta = meta.New("color-shift", []*meta.FilterMetaDataArg{
 	{Name: "hue", Default: 0.0},
 	{Name: "sat", Default: 0.0},
 	{Name: "lum", Default: 0.0},
+	{Name: "space", Default: string(image.SPACE_HSL), Values: image.ColorSpaceNames()},
 })
 
-func Apply(i *image.Image, hue, sat, lum float64) *image.Image {
-	return i.ProcessHSLA(0, 0, i.W(), i.H(), func(x, y int, hsla *hsla.HSLA) (x2, y2 int, col2 *hsla.HSLA) {
-		return x, y, hsla.Shift(hue, sat, lum, 0)
+func Apply(i *image.Image, hue, sat, lum float64, space image.ColorSpace) *image.Image {
+	return i.ProcessPixelsIn(space, func(x, y int, hsla []float64) {
+		hsla[0] += hue
+		hsla[1] = math.Clamp(hsla[1]+sat, 0.0, 1.0)
+		hsla[2] = math.Clamp(hsla[2]+lum, 0.0, 1.0)
 	})
 }
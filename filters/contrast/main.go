@@ -1,9 +1,6 @@
 package contrast
 
 import (
-	"math"
-
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
 )
@@ -15,17 +12,11 @@ var Meta = meta.New("contrast", []*meta.FilterMetaDataArg{
 func Apply(img *image.Image, adjustment float64) *image.Image {
 	// Precompute scaling factor
 	factor := (259 * (adjustment + 1)) / (255 * (1 - adjustment))
+	const mid = 128.0 / 255.0
 
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		adjust := func(value uint8) uint8 {
-			return uint8(math.Min(255, math.Max(0, factor*(float64(value)-128)+128)))
-		}
-
-		return x, y, rgba.New(
-			adjust(col.R()),
-			adjust(col.G()),
-			adjust(col.B()),
-			col.A(),
-		)
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		px[0] = float32(factor*(float64(px[0])-mid) + mid)
+		px[1] = float32(factor*(float64(px[1])-mid) + mid)
+		px[2] = float32(factor*(float64(px[2])-mid) + mid)
 	})
 }
@@ -0,0 +1,40 @@
+package displace
+
+import (
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var channels = []string{
+	string(image.CHANNEL_R), string(image.CHANNEL_G), string(image.CHANNEL_B), string(image.CHANNEL_A),
+	string(image.CHANNEL_H), string(image.CHANNEL_S), string(image.CHANNEL_L),
+}
+
+var Meta = meta.New("displace", []*meta.FilterMetaDataArg{
+	{Name: "map", Default: ""},
+	{Name: "strength", Default: 0.0},
+	{Name: "channel-x", Default: string(image.CHANNEL_R), Values: channels},
+	{Name: "channel-y", Default: string(image.CHANNEL_G), Values: channels},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.CLAMP), Values: resample.EdgeNames()},
+})
+
+// Apply displaces the pixels of the image by offsets read from the displacement map dmap.
+//
+// The parameters:
+//   - map: the displacement map, resolved by the caller (a file, URL or the output of a named filter).
+//   - strength: the largest offset as a percentage (0..1) of the image size, e.g. 0.05 = 5%.
+//   - channel-x, channel-y: the channels of the map that control the horizontal and vertical offset,
+//     the middle of the channel's range means no offset.
+//   - resample: the resampling kernel used to sample the displaced positions.
+//   - edge: what is sampled outside of the image (transparent, clamp, wrap or mirror).
+//
+// If dmap is nil the image is left unchanged.
+func Apply(img, dmap *image.Image, strength float64, chX, chY image.Channel, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	if dmap == nil {
+		return img
+	}
+	img.Set(img.Displace(dmap, strength, chX, chY, kernel, edge).Get())
+	return img
+}
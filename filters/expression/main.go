@@ -0,0 +1,104 @@
+package expression
+
+import (
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/math"
+	"github.com/toxyl/gfx/math/expr"
+)
+
+var Meta = meta.New("expr", []*meta.FilterMetaDataArg{
+	{Name: "r", Default: ""},
+	{Name: "g", Default: ""},
+	{Name: "b", Default: ""},
+	{Name: "a", Default: ""},
+	{Name: "h", Default: ""},
+	{Name: "s", Default: ""},
+	{Name: "l", Default: ""},
+	{Name: "seed", Default: 0.0},
+})
+
+// Vars are the variables expressions can use, in the order Eval expects them:
+// the channels of the pixel (r, g, b, a, s and l in the range [0, 1], h in degrees [0, 360)),
+// its position and the size of the image (w is short for width, h is the hue).
+var Vars = []string{"r", "g", "b", "a", "h", "s", "l", "x", "y", "w", "width", "height"}
+
+// Filter sets the channels of each pixel to the results of expressions.
+type Filter struct {
+	exprs [7]*expr.Expr // r, g, b, a, h, s, l, nil keeps the channel
+	hsl   bool          // at least one of h, s and l is set
+}
+
+// Compile compiles the expressions of the output channels, empty expressions keep the channel unchanged.
+// Besides the functions of the expr package they can call noise(x, y), Perlin noise in the range [0, 1]
+// with features about 1 unit apart that is generated with seed.
+func Compile(r, g, b, a, h, s, l string, seed int64) (*Filter, error) {
+	funcs := expr.Funcs{
+		"noise": func(x, y float64) float64 { return image.Noise(image.NOISE_PERLIN, x, y, seed) },
+	}
+	f := &Filter{}
+	for k, src := range []string{r, g, b, a, h, s, l} {
+		if src == "" {
+			continue
+		}
+		e, err := expr.CompileFuncs(src, funcs, Vars...)
+		if err != nil {
+			return nil, err
+		}
+		f.exprs[k] = e
+		f.hsl = f.hsl || k >= 4
+	}
+	return f, nil
+}
+
+// Apply evaluates the expressions for every pixel of img. All expressions see the original pixel,
+// the results for h, s and l are applied after those for r, g and b. Results that are not a number
+// (like sqrt(-1)) keep the channel unchanged.
+func (f *Filter) Apply(img *image.Image) *image.Image {
+	buf := img.Get()
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	_ = executor.Rows(img.Context(), 0, h, func(y0, y1 int) {
+		v := make([]float64, len(Vars))
+		v[9], v[10], v[11] = float64(w), float64(w), float64(h)
+		var res [7]float64
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				px := buf.PixelAt(buf.Rect.Min.X+x, buf.Rect.Min.Y+y)
+				v[0], v[1], v[2], v[3] = float64(px[0]), float64(px[1]), float64(px[2]), float64(px[3])
+				v[4], v[5], v[6] = convert.RGBToHSL(v[0], v[1], v[2])
+				v[7], v[8] = float64(x), float64(y)
+				for k, e := range f.exprs {
+					if res[k] = v[k]; e != nil {
+						if c := e.Eval(v); c == c { // NaN keeps the channel
+							res[k] = c
+						}
+					}
+				}
+				if f.hsl {
+					var hsl [3]float64
+					hsl[0], hsl[1], hsl[2] = convert.RGBToHSL(math.Clamp(res[0], 0, 1), math.Clamp(res[1], 0, 1), math.Clamp(res[2], 0, 1))
+					for k := range hsl {
+						if f.exprs[4+k] != nil {
+							hsl[k] = res[4+k]
+						}
+					}
+					res[0], res[1], res[2] = convert.HSLToRGB(math.Wrap(hsl[0], 0, 360), math.Clamp(hsl[1], 0, 1), math.Clamp(hsl[2], 0, 1))
+				}
+				buf.SetPixel(buf.Rect.Min.X+x, buf.Rect.Min.Y+y, [4]float32{float32(res[0]), float32(res[1]), float32(res[2]), float32(res[3])})
+			}
+		}
+	})
+	return img
+}
+
+// Apply sets the channels of each pixel to the results of expressions, see Compile.
+// It returns the error of Compile and leaves the image unchanged if an expression is invalid.
+func Apply(img *image.Image, r, g, b, a, h, s, l string, seed float64) (*image.Image, error) {
+	f, err := Compile(r, g, b, a, h, s, l, int64(seed))
+	if err != nil {
+		return img, err
+	}
+	return f.Apply(img), nil
+}
@@ -21,6 +21,7 @@ var Meta = meta.New("extract", []*meta.FilterMetaDataArg{
 	{Name: "lum", Default: 0.50},
 	{Name: "lum-tolerance", Default: 0.50},
 	{Name: "lum-feather", Default: 0.0},
+	{Name: "space", Default: string(image.SPACE_HSL), Values: image.ColorSpaceNames()},
 })
 
 // prepHueRange normalizes and adjusts the boundaries of a FuzzyRange to operate
@@ -246,8 +247,13 @@ type FuzzyRangeHSLA struct {
 	lum FuzzyRange
 }
 
+// alpha returns how much the color given by hue, saturation and lightness is within the ranges.
+func (f *FuzzyRangeHSLA) alpha(h, s, l float64) float64 {
+	return f.hue.calcWrapped(h) * f.lum.calc(l) * f.sat.calc(s)
+}
+
 func (f *FuzzyRangeHSLA) Calc(c *hsla.HSLA) *hsla.HSLA {
-	alpha := f.hue.calcWrapped(c.H()) * f.lum.calc(c.L()) * f.sat.calc(c.S())
+	alpha := f.alpha(c.H(), c.S(), c.L())
 	if alpha <= 0 {
 		return vars.COLOR_TRANSPARENT // Fully transparent if any component is out of range
 	}
@@ -255,11 +261,24 @@ func (f *FuzzyRangeHSLA) Calc(c *hsla.HSLA) *hsla.HSLA {
 	return c
 }
 
-func Apply(i *image.Image, cf *filter.ColorFilter) *image.Image {
+// Apply keeps the pixels whose color is within the ranges of cf, their alpha becomes the degree of the match.
+// Saturation and lightness of cf are those of the given color space (see image.ColorSpace.FromRGB).
+func Apply(i *image.Image, cf *filter.ColorFilter, space image.ColorSpace) *image.Image {
 	fr := FuzzyRangeHSLA{
 		hue: prepHueRange(FuzzyRange{cf.MinThres.H(), cf.Min.H(), cf.Max.H(), cf.MaxThres.H(), false, false}),
 		sat: FuzzyRange{math.Clamp(cf.MinThres.S(), 0.0, 1.0), math.Clamp(cf.Min.S(), 0.0, 1.0), math.Clamp(cf.Max.S(), 0.0, 1.0), math.Clamp(cf.MaxThres.S(), 0.0, 1.0), false, false},
 		lum: FuzzyRange{math.Clamp(cf.MinThres.L(), 0.0, 1.0), math.Clamp(cf.Min.L(), 0.0, 1.0), math.Clamp(cf.Max.L(), 0.0, 1.0), math.Clamp(cf.MaxThres.L(), 0.0, 1.0), false, false},
 	}
+	if space != image.SPACE_HSL {
+		return i.ProcessPixels(func(x, y int, px []float32) {
+			h, s, l := space.FromRGB(float64(px[0]), float64(px[1]), float64(px[2]))
+			alpha := fr.alpha(h, s, l)
+			if alpha <= 0 {
+				px[0], px[1], px[2], px[3] = 0, 0, 0, 0
+				return
+			}
+			px[3] = float32(alpha)
+		})
+	}
 	return i.ProcessHSLA(0, 0, i.W(), i.H(), func(x, y int, col *hsla.HSLA) (x2 int, y2 int, col2 *hsla.HSLA) { return x, y, fr.Calc(col) })
 }
@@ -0,0 +1,38 @@
+package frompolar
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("from-polar", []*meta.FilterMetaDataArg{
+	{Name: "radius-start", Default: 0.0},
+	{Name: "radius-end", Default: 1.0},
+	{Name: "angle-start", Default: 0.0},
+	{Name: "angle-end", Default: 360.0},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "width", Default: 0.0},
+	{Name: "height", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+})
+
+// Apply unwraps a disk into a rectangular strip with angles from left to right and radii from top to bottom,
+// which is the inverse of the to-polar filter.
+//
+// The parameters:
+//   - radius-start, radius-end: 0..1 (0% to 100%) of half the smaller image dimension, the ring to unwrap.
+//   - angle-start, angle-end: angular range in degrees, clockwise from the positive x-axis.
+//   - offset-x, offset-y: offsets (-1..1) from the image center that define the center of the disk.
+//   - width, height: size of the result in pixels, 0 keeps the size of the image.
+//   - resample: the resampling kernel.
+func Apply(img *image.Image, radiusStart, radiusEnd, angleStart, angleEnd, offsetX, offsetY, width, height float64, kernel resample.Kernel) *image.Image {
+	hw := float64(img.W()) / 2.0
+	hh := float64(img.H()) / 2.0
+	maxR := math.Min(hw, hh)
+	img.Set(img.FromPolar(hw+offsetX*hw, hh+offsetY*hh, radiusStart*maxR, radiusEnd*maxR, angleStart, angleEnd, int(width), int(height), kernel).Get())
+	return img
+}
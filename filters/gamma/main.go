@@ -3,7 +3,6 @@ package gamma
 import (
 	"math"
 
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
 )
@@ -13,14 +12,10 @@ var Meta = meta.New("gamma", []*meta.FilterMetaDataArg{
 })
 
 func Apply(img *image.Image, adjustment float64) *image.Image {
-	// Precompute gamma correction lookup table
-	lut := make([]uint8, 256)
 	invGamma := 1.0 / (adjustment + 1)
-	for i := range 256 {
-		lut[i] = uint8(math.Min(255, math.Pow(float64(i)/255.0, invGamma)*255.0))
-	}
-
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		return x, y, rgba.New(lut[col.R()], lut[col.G()], lut[col.B()], col.A())
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		px[0] = float32(math.Pow(float64(px[0]), invGamma))
+		px[1] = float32(math.Pow(float64(px[1]), invGamma))
+		px[2] = float32(math.Pow(float64(px[2]), invGamma))
 	})
 }
@@ -1,7 +1,6 @@
 package gray
 
 import (
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
 )
@@ -9,8 +8,8 @@ import (
 var Meta = meta.New("gray", []*meta.FilterMetaDataArg{})
 
 func Apply(img *image.Image) *image.Image {
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		gray := uint8(0.299*float64(col.R()) + 0.587*float64(col.G()) + 0.114*float64(col.B()))
-		return x, y, rgba.New(gray, gray, gray, col.A())
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		gray := float32(0.299*float64(px[0]) + 0.587*float64(px[1]) + 0.114*float64(px[2]))
+		px[0], px[1], px[2] = gray, gray, gray
 	})
 }
@@ -1,7 +1,6 @@
 package invert
 
 import (
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
 )
@@ -9,12 +8,9 @@ import (
 var Meta = meta.New("invert", []*meta.FilterMetaDataArg{})
 
 func Apply(img *image.Image) *image.Image {
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		return x, y, rgba.New(
-			255-col.R(),
-			255-col.G(),
-			255-col.B(),
-			col.A(),
-		)
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		px[0] = 1 - px[0]
+		px[1] = 1 - px[1]
+		px[2] = 1 - px[2]
 	})
 }
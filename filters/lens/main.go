@@ -0,0 +1,25 @@
+package lens
+
+import (
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("lens", []*meta.FilterMetaDataArg{
+	{Name: "k1", Default: 0.0},
+	{Name: "k2", Default: 0.0},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply adds or corrects barrel and pincushion distortion around a center defined by offsets (-1..1)
+// from the image center. k1 and k2 are the radial distortion coefficients, positive values add barrel
+// distortion (correcting pincushion distortion), negative values add pincushion distortion (correcting barrel distortion).
+func Apply(img *image.Image, k1, k2, offsetX, offsetY float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	hw, hh := float64(img.CW()), float64(img.CH())
+	img.Set(img.LensDistortion(hw+offsetX*hw, hh+offsetY*hh, k1, k2, kernel, edge).Get())
+	return img
+}
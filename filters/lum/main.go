@@ -1,7 +1,6 @@
 package lum
 
 import (
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
 	"github.com/toxyl/gfx/math"
@@ -9,26 +8,29 @@ import (
 
 var Meta = meta.New("lum", []*meta.FilterMetaDataArg{
 	{Name: "shift", Default: 0.0},
+	{Name: "space", Default: string(image.SPACE_RGB), Values: append([]string{string(image.SPACE_RGB)}, image.ColorSpaceNames()...)},
 })
 
-func Apply(img *image.Image, shift float64) *image.Image {
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		r := float64(col.R())
-		g := float64(col.G())
-		b := float64(col.B())
+// Apply adds shift to the luma (or lightness) in space, image.SPACE_RGB scales the RGB channels to shift the luma of the pixel.
+func Apply(img *image.Image, shift float64, space image.ColorSpace) *image.Image {
+	if space != image.SPACE_RGB {
+		return img.ProcessPixelsIn(space, func(x, y int, hsla []float64) { hsla[2] += shift })
+	}
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		r := float64(px[0])
+		g := float64(px[1])
+		b := float64(px[2])
 
 		// Calculate luminance (Y) using the formula Y = 0.299*R + 0.587*G + 0.114*B
 		Y := 0.299*r + 0.587*g + 0.114*b
 
 		// Adjust luminance
-		Y = math.Clamp(Y+shift*255.0, 0.0, 255.0)
+		Y = math.Clamp(Y+shift, 0.0, 1.0)
 
 		// Calculate the new RGB values based on the adjusted luminance
 		factor := Y / (0.299*r + 0.587*g + 0.114*b)
-		r = math.Clamp(r*factor, 0.0, 255.0)
-		g = math.Clamp(g*factor, 0.0, 255.0)
-		b = math.Clamp(b*factor, 0.0, 255.0)
-
-		return x, y, rgba.New(uint8(r), uint8(g), uint8(b), col.A())
+		px[0] = float32(r * factor)
+		px[1] = float32(g * factor)
+		px[2] = float32(b * factor)
 	})
 }
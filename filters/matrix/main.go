@@ -0,0 +1,30 @@
+package matrix
+
+import (
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("matrix", []*meta.FilterMetaDataArg{
+	{Name: "a", Default: 1.0},
+	{Name: "b", Default: 0.0},
+	{Name: "c", Default: 0.0},
+	{Name: "d", Default: 0.0},
+	{Name: "e", Default: 1.0},
+	{Name: "f", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply transforms the image with the affine matrix
+//
+//	| a b c |
+//	| d e f |
+//
+// which maps the source pixel (x, y) to (a*x + b*y + c, d*x + e*y + f). Translations (c and f) are in pixels.
+// The edge mode decides what is sampled outside of the source image (transparent, clamp, wrap or mirror).
+func Apply(img *image.Image, a, b, c, d, e, f float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	img.Set(img.Warp(image.Affine{a, b, c, d, e, f}, kernel, edge).Get())
+	return img
+}
@@ -3,6 +3,7 @@ package meta
 type FilterMetaDataArg struct {
 	Name    string
 	Default any
+	Values  []string // allowed values of string args, empty means any value is allowed
 }
 
 type FilterMetaData struct {
@@ -0,0 +1,34 @@
+package perspective
+
+import (
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("perspective", []*meta.FilterMetaDataArg{
+	{Name: "x0", Default: 0.0},
+	{Name: "y0", Default: 0.0},
+	{Name: "x1", Default: 1.0},
+	{Name: "y1", Default: 0.0},
+	{Name: "x2", Default: 1.0},
+	{Name: "y2", Default: 1.0},
+	{Name: "x3", Default: 0.0},
+	{Name: "y3", Default: 1.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply stretches the quadrilateral with the corners (x0, y0) top-left, (x1, y1) top-right,
+// (x2, y2) bottom-right and (x3, y3) bottom-left onto the whole image, which corrects keystone distortion.
+// Coordinates are 0..1 (0% to 100%) of the image width and height, measured from the upper left corner.
+// The defaults select the whole image and leave it unchanged.
+func Apply(img *image.Image, x0, y0, x1, y1, x2, y2, x3, y3 float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	w, h := float64(img.W()), float64(img.H())
+	// pixel centers are at integer coordinates, so the outer edges of the image are half a pixel further out
+	px := func(x, y float64) [2]float64 { return [2]float64{x*w - 0.5, y*h - 0.5} }
+	from := [4][2]float64{px(x0, y0), px(x1, y1), px(x2, y2), px(x3, y3)}
+	to := [4][2]float64{px(0, 0), px(1, 0), px(1, 1), px(0, 1)}
+	img.Set(img.Perspective(from, to, kernel, edge).Get())
+	return img
+}
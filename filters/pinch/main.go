@@ -0,0 +1,27 @@
+package pinch
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("pinch", []*meta.FilterMetaDataArg{
+	{Name: "amount", Default: 0.0},
+	{Name: "radius", Default: 0.5},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply pinches (amount 0..1) or bulges (amount -1..0) the image around a center defined by offsets (-1..1)
+// from the image center. The radius is a percentage (0..1) of the maximum image dimension.
+func Apply(img *image.Image, amount, radius, offsetX, offsetY float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	hw, hh := float64(img.CW()), float64(img.CH())
+	r := radius * math.Max(float64(img.W()), float64(img.H()))
+	img.Set(img.Pinch(hw+offsetX*hw, hh+offsetY*hh, amount, r, kernel, edge).Get())
+	return img
+}
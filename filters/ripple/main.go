@@ -0,0 +1,28 @@
+package ripple
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("ripple", []*meta.FilterMetaDataArg{
+	{Name: "amplitude", Default: 0.0},
+	{Name: "wavelength", Default: 0.1},
+	{Name: "phase", Default: 0.0},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply moves the image along concentric sine waves around a center defined by offsets (-1..1) from the image center.
+// amplitude and wavelength are percentages (0..1) of the maximum image dimension, phase is in degrees.
+func Apply(img *image.Image, amplitude, wavelength, phase, offsetX, offsetY float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	hw, hh := float64(img.CW()), float64(img.CH())
+	size := math.Max(float64(img.W()), float64(img.H()))
+	img.Set(img.Ripple(hw+offsetX*hw, hh+offsetY*hh, amplitude*size, wavelength*size, phase, kernel, edge).Get())
+	return img
+}
@@ -3,17 +3,19 @@ package rotate
 import (
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
 )
 
 var Meta = meta.New("rotate", []*meta.FilterMetaDataArg{
 	{Name: "angle", Default: 0.0},
 	{Name: "offset-x", Default: 0.0},
 	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
 })
 
-func Apply(img *image.Image, angle, offsetX, offsetY float64) *image.Image {
+func Apply(img *image.Image, angle, offsetX, offsetY float64, kernel resample.Kernel) *image.Image {
 	hw := float64(img.CW())
 	hh := float64(img.CH())
-	img.Set(img.Rotate(angle, hw+offsetX*hw, hh+offsetY*hh).Get())
+	img.Set(img.RotateWith(angle, hw+offsetX*hw, hh+offsetY*hh, kernel).Get())
 	return img
 }
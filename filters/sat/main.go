@@ -1,25 +1,27 @@
 package sat
 
 import (
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
-	"github.com/toxyl/gfx/math"
 )
 
 var Meta = meta.New("sat", []*meta.FilterMetaDataArg{
 	{Name: "shift", Default: 0.0},
+	{Name: "space", Default: string(image.SPACE_RGB), Values: append([]string{string(image.SPACE_RGB)}, image.ColorSpaceNames()...)},
 })
 
-func Apply(img *image.Image, shift float64) *image.Image {
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		r := float64(col.R())
-		g := float64(col.G())
-		b := float64(col.B())
+// Apply scales the saturation (or chroma) by 1+shift in space, image.SPACE_RGB scales the distance of the RGB channels to the luma of the pixel.
+func Apply(img *image.Image, shift float64, space image.ColorSpace) *image.Image {
+	if space != image.SPACE_RGB {
+		return img.ProcessPixelsIn(space, func(x, y int, hsla []float64) { hsla[1] *= 1 + shift })
+	}
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		r := float64(px[0])
+		g := float64(px[1])
+		b := float64(px[2])
 		gray := 0.299*r + 0.587*g + 0.114*b
-		adjust := func(value float64) uint8 {
-			return uint8(math.Clamp(gray+(1-(-shift))*(value-gray), 0.0, 255.0))
-		}
-		return x, y, rgba.New(adjust(r), adjust(g), adjust(b), col.A())
+		px[0] = float32(gray + (1-(-shift))*(r-gray))
+		px[1] = float32(gray + (1-(-shift))*(g-gray))
+		px[2] = float32(gray + (1-(-shift))*(b-gray))
 	})
 }
@@ -1,10 +1,8 @@
 package satcontrast
 
 import (
-	"github.com/toxyl/gfx/color/hsla"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
-	"github.com/toxyl/gfx/math"
 )
 
 var Meta = meta.New("sat-contrast", []*meta.FilterMetaDataArg{
@@ -12,9 +10,7 @@ var Meta = meta.New("sat-contrast", []*meta.FilterMetaDataArg{
 })
 
 func Apply(i *image.Image, adjustment float64) *image.Image {
-	return i.ProcessHSLA(0, 0, i.W(), i.H(), func(x, y int, col *hsla.HSLA) (x2 int, y2 int, col2 *hsla.HSLA) {
-		s := col.S()
-		newS := math.Clamp(0.5+(s-0.5)*(1.0-adjustment), 0, 1)
-		return x, y, col.SetS(newS)
+	return i.ProcessPixelsHSLA(func(x, y int, hsla []float64) {
+		hsla[1] = 0.5 + (hsla[1]-0.5)*(1.0-adjustment)
 	})
 }
@@ -1,9 +1,6 @@
 package sepia
 
 import (
-	"math"
-
-	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
 )
@@ -11,15 +8,13 @@ import (
 var Meta = meta.New("sepia", []*meta.FilterMetaDataArg{})
 
 func Apply(img *image.Image) *image.Image {
-	return img.ProcessRGBA(0, 0, img.W(), img.H(), func(x, y int, col *rgba.RGBA) (x2 int, y2 int, col2 *rgba.RGBA) {
-		r := float64(col.R())
-		g := float64(col.G())
-		b := float64(col.B())
-
-		newR := math.Min(255, 0.393*r+0.769*g+0.189*b)
-		newG := math.Min(255, 0.349*r+0.686*g+0.168*b)
-		newB := math.Min(255, 0.272*r+0.534*g+0.131*b)
+	return img.ProcessPixels(func(x, y int, px []float32) {
+		r := float64(px[0])
+		g := float64(px[1])
+		b := float64(px[2])
 
-		return x, y, rgba.New(uint8(newR), uint8(newG), uint8(newB), col.A())
+		px[0] = float32(0.393*r + 0.769*g + 0.189*b)
+		px[1] = float32(0.349*r + 0.686*g + 0.168*b)
+		px[2] = float32(0.272*r + 0.534*g + 0.131*b)
 	})
 }
@@ -0,0 +1,28 @@
+package swirl
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("swirl", []*meta.FilterMetaDataArg{
+	{Name: "angle", Default: 0.0},
+	{Name: "radius", Default: 0.5},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply twists the image around a center defined by offsets (-1..1) from the image center.
+// The center is rotated by angle degrees (positive = clockwise), the rotation fades out towards the radius,
+// which is a percentage (0..1) of the maximum image dimension.
+func Apply(img *image.Image, angle, radius, offsetX, offsetY float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	hw, hh := float64(img.CW()), float64(img.CH())
+	r := radius * math.Max(float64(img.W()), float64(img.H()))
+	img.Set(img.Swirl(hw+offsetX*hw, hh+offsetY*hh, angle, r, kernel, edge).Get())
+	return img
+}
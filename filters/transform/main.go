@@ -4,6 +4,7 @@ package transform
 import (
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
 )
 
 var Meta = meta.New("transform", []*meta.FilterMetaDataArg{
@@ -13,6 +14,7 @@ var Meta = meta.New("transform", []*meta.FilterMetaDataArg{
 	{Name: "scale", Default: 0.0},
 	{Name: "offset-x", Default: 0.0},
 	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
 })
 
 // Apply performs a composite transformation on the image in the following order:
@@ -26,10 +28,11 @@ var Meta = meta.New("transform", []*meta.FilterMetaDataArg{
 //   - scale: percentage to scale the image; 0 means no change,
 //     0.5 means scale up by 50% (factor 1.5), -0.5 means scale down by 50% (factor 0.5).
 //   - offset-x, offset-y: offsets (-1..1) from the image center that define the rotation/scaling center.
-func Apply(img *image.Image, translateX, translateY, rotate, scale, offsetX, offsetY float64) *image.Image {
+//   - resample: the resampling kernel used for all steps.
+func Apply(img *image.Image, translateX, translateY, rotate, scale, offsetX, offsetY float64, kernel resample.Kernel) *image.Image {
 	// Compute absolute translation offsets relative to the image center.
-	absTx := int(translateX * float64(img.CW()))
-	absTy := int(translateY * float64(img.CH()))
+	absTx := translateX * float64(img.CW())
+	absTy := translateY * float64(img.CH())
 	// Compute effective center for rotation and scaling.
 	cx := img.CW() + int(offsetX*float64(img.CW()))
 	cy := img.CH() + int(offsetY*float64(img.CH()))
@@ -37,8 +40,8 @@ func Apply(img *image.Image, translateX, translateY, rotate, scale, offsetX, off
 	factor := 1.0 + scale
 
 	// First, apply translation with wrap-around.
-	img.Set(img.Translate(absTx, absTy, true).Get())
+	img.Set(img.TranslateWith(absTx, absTy, true, kernel).Get())
 	// Then, apply combined rotation and scaling.
-	img.Set(img.TransformRotateScale(rotate, factor, cx, cy).Get())
+	img.Set(img.TransformRotateScaleWith(rotate, factor, cx, cy, kernel).Get())
 	return img
 }
@@ -0,0 +1,34 @@
+package wave
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/filters/meta"
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+var Meta = meta.New("wave", []*meta.FilterMetaDataArg{
+	{Name: "amplitude", Default: 0.0},
+	{Name: "wavelength", Default: 0.1},
+	{Name: "angle", Default: 0.0},
+	{Name: "phase", Default: 0.0},
+	{Name: "offset-x", Default: 0.0},
+	{Name: "offset-y", Default: 0.0},
+	{Name: "resample", Default: string(resample.NEAREST), Values: resample.AllNames()},
+	{Name: "edge", Default: string(resample.TRANSPARENT), Values: resample.EdgeNames()},
+})
+
+// Apply shifts the image sideways along a sine wave.
+//
+// The parameters:
+//   - amplitude, wavelength: percentages (0..1) of the maximum image dimension.
+//   - angle: direction the wave travels in, in degrees (0 = left to right, positive angles rotate clockwise).
+//   - phase: phase of the wave at the center, in degrees.
+//   - offset-x, offset-y: offsets (-1..1) from the image center that define the center.
+func Apply(img *image.Image, amplitude, wavelength, angle, phase, offsetX, offsetY float64, kernel resample.Kernel, edge resample.Edge) *image.Image {
+	hw, hh := float64(img.CW()), float64(img.CH())
+	size := math.Max(float64(img.W()), float64(img.H()))
+	img.Set(img.Wave(hw+offsetX*hw, hh+offsetY*hh, amplitude*size, wavelength*size, angle, phase, kernel, edge).Get())
+	return img
+}
@@ -6,6 +6,7 @@ require (
 	github.com/gofiber/fiber/v2 v2.52.5
 	github.com/toxyl/errors v0.0.0-20240410073853-96b96b437ed5
 	github.com/toxyl/flo v0.0.0-20240412132929-869b69ff6976
+	golang.org/x/image v0.25.0
 )
 
 require (
@@ -21,5 +22,6 @@ require (
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
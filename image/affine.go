@@ -0,0 +1,146 @@
+package image
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+// Affine is a 2D affine transformation matrix in row-major order:
+//
+//	| A B C |
+//	| D E F |
+//	| 0 0 1 |
+//
+// It maps the point (x, y) to (A*x + B*y + C, D*x + E*y + F). Coordinates are in pixels with
+// the y-axis pointing down, integer coordinates address pixel centers.
+type Affine [6]float64
+
+// Identity returns the matrix that leaves all points unchanged.
+func Identity() Affine {
+	return Affine{1, 0, 0, 0, 1, 0}
+}
+
+// Translation returns a matrix that moves points by (tx, ty) pixels.
+func Translation(tx, ty float64) Affine {
+	return Affine{1, 0, tx, 0, 1, ty}
+}
+
+// Scaling returns a matrix that scales points by sx and sy relative to the origin.
+func Scaling(sx, sy float64) Affine {
+	return Affine{sx, 0, 0, 0, sy, 0}
+}
+
+// Rotation returns a matrix that rotates points by angle degrees around the origin.
+// Because the y-axis points down, positive angles rotate clockwise on screen.
+func Rotation(angle float64) Affine {
+	theta := angle * math.Pi / 180.0
+	cos, sin := math.Cos(theta), math.Sin(theta)
+	return Affine{cos, -sin, 0, sin, cos, 0}
+}
+
+// Shearing returns a matrix that shifts x by shx*y and y by shy*x.
+func Shearing(shx, shy float64) Affine {
+	return Affine{1, shx, 0, shy, 1, 0}
+}
+
+// Mul returns the product m * n, i.e. a matrix that applies n first and m second.
+func (m Affine) Mul(n Affine) Affine {
+	return Affine{
+		m[0]*n[0] + m[1]*n[3], m[0]*n[1] + m[1]*n[4], m[0]*n[2] + m[1]*n[5] + m[2],
+		m[3]*n[0] + m[4]*n[3], m[3]*n[1] + m[4]*n[4], m[3]*n[2] + m[4]*n[5] + m[5],
+	}
+}
+
+// Then returns a matrix that applies m first and n second.
+func (m Affine) Then(n Affine) Affine {
+	return n.Mul(m)
+}
+
+// Translate returns m followed by a translation by (tx, ty).
+func (m Affine) Translate(tx, ty float64) Affine {
+	return m.Then(Translation(tx, ty))
+}
+
+// Scale returns m followed by a scaling by sx and sy.
+func (m Affine) Scale(sx, sy float64) Affine {
+	return m.Then(Scaling(sx, sy))
+}
+
+// Rotate returns m followed by a rotation by angle degrees.
+func (m Affine) Rotate(angle float64) Affine {
+	return m.Then(Rotation(angle))
+}
+
+// Shear returns m followed by a shear by shx and shy.
+func (m Affine) Shear(shx, shy float64) Affine {
+	return m.Then(Shearing(shx, shy))
+}
+
+// Around returns a matrix that applies m relative to the point (cx, cy) instead of the origin,
+// e.g. Rotation(45).Around(cx, cy) rotates around (cx, cy).
+func (m Affine) Around(cx, cy float64) Affine {
+	return Translation(-cx, -cy).Then(m).Translate(cx, cy)
+}
+
+// Det returns the determinant of the linear part of m, it is 0 if m is not invertible.
+func (m Affine) Det() float64 {
+	return m[0]*m[4] - m[1]*m[3]
+}
+
+// Invert returns the inverse of m and true, or the identity and false if m is not invertible.
+func (m Affine) Invert() (Affine, bool) {
+	det := m.Det()
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Identity(), false
+	}
+	a, b := m[4]/det, -m[1]/det
+	d, e := -m[3]/det, m[0]/det
+	return Affine{a, b, -(a*m[2] + b*m[5]), d, e, -(d*m[2] + e*m[5])}, true
+}
+
+// Apply maps the point (x, y) through m.
+func (m Affine) Apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[1]*y + m[2], m[3]*x + m[4]*y + m[5]
+}
+
+// Warp transforms the image with the matrix m, which maps source to destination coordinates.
+// The output image has the same dimensions as the source image. Each destination pixel is mapped
+// back into the source and sampled there with the given kernel, samples that fall outside of the
+// source are handled according to edge. If m is not invertible the result is transparent.
+// The kernel isn't widened when m shrinks the image, so strong downscaling can alias.
+// Rows are processed on the shared executor and processing stops early if the context of the image is cancelled.
+func (i *Image) Warp(m Affine, kernel resample.Kernel, edge resample.Edge) *Image {
+	inv, ok := m.Invert()
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		sx, sy := inv.Apply(x, y)
+		return sx, sy, ok
+	}, kernel, edge)
+}
+
+// Remap creates an image of the same size where every pixel is sampled at the source position returned by inverse,
+// which returns false for pixels that have no source position, those stay transparent. inverse must be safe for
+// concurrent use. Samples outside of the source are handled according to edge. Warp, WarpPerspective and the
+// distortions are built on it. Rows are processed on the shared executor and processing stops early if the
+// context of the image is cancelled.
+func (i *Image) Remap(inverse func(x, y float64) (sx, sy float64, ok bool), kernel resample.Kernel, edge resample.Edge) *Image {
+	ctx := i.Context()
+	src := i.Get()
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+	kernel = resample.Parse(string(kernel))
+	edge = resample.ParseEdge(string(edge))
+	_ = executor.Rows(ctx, 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				if sx, sy, ok := inverse(float64(x), float64(y)); ok {
+					dst.SetPixel(x, y, src.sample(sx, sy, kernel, edge))
+				}
+			}
+		}
+	})
+	return &Image{raw: dst, path: i.path, mu: &sync.Mutex{}}
+}
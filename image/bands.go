@@ -0,0 +1,128 @@
+package image
+
+import (
+	"context"
+	"image"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/toxyl/gfx/image/executor"
+)
+
+// BandNormalization defines how the results of band maths are mapped to the range [0, 1].
+type BandNormalization string
+
+const (
+	NORMALIZE_NONE  BandNormalization = "none"  // results are used as they are
+	NORMALIZE_RANGE BandNormalization = "range" // results in a given range are stretched to [0, 1]
+	NORMALIZE_AUTO  BandNormalization = "auto"  // the range of the results (optionally without outliers) is stretched to [0, 1]
+)
+
+// BandNormalizations returns the names of all band normalizations.
+func BandNormalizations() []string {
+	return []string{string(NORMALIZE_NONE), string(NORMALIZE_RANGE), string(NORMALIZE_AUTO)}
+}
+
+// ParseBandNormalization returns the band normalization with the given name (case-insensitive), "" if it is unknown.
+func ParseBandNormalization(name string) BandNormalization {
+	n := BandNormalization(strings.ToLower(strings.TrimSpace(name)))
+	if !slices.Contains(BandNormalizations(), string(n)) {
+		return ""
+	}
+	return n
+}
+
+// BandMath computes an image from the pixels of several images (the bands), e.g. the ratio of two wavelengths.
+// fn is called for the red, green and blue channel of every pixel with the values (range [0, 1]) of that
+// channel in all bands, in the order of bands. It may be called concurrently but never shares v.
+// The alpha of the result is the smallest alpha of the bands, bands larger than the smallest one are cropped.
+//
+// The results are mapped to [0, 1] according to norm, values outside of it are clipped:
+//   - NORMALIZE_NONE: results are used as they are.
+//   - NORMALIZE_RANGE: results from lo to hi are stretched to [0, 1].
+//   - NORMALIZE_AUTO: results from the clip-th to the (100-clip)-th percentile are stretched to [0, 1],
+//     so clip (a percentage from 0 to 50) removes outliers. Transparent pixels are ignored.
+//
+// Pixels where fn doesn't return a finite number for every channel (e.g. for a division by 0) are transparent.
+// It returns the context's error if ctx is cancelled.
+func BandMath(ctx context.Context, bands []*Image, fn func(v []float64) float64, norm BandNormalization, lo, hi, clip float64) (*Image, error) {
+	if len(bands) == 0 {
+		return New(0, 0), nil
+	}
+	bufs := make([]*Buffer, len(bands))
+	w, h := bands[0].W(), bands[0].H()
+	for k, b := range bands {
+		bufs[k] = b.Get()
+		w, h = min(w, b.W()), min(h, b.H())
+	}
+	res := make([]float64, w*h*4) // r, g, b and alpha of the results
+	err := executor.Rows(ctx, 0, h, func(y0, y1 int) {
+		v := make([]float64, len(bufs))
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				i := (y*w + x) * 4
+				res[i+3] = 1
+				for _, b := range bufs {
+					res[i+3] = math.Min(res[i+3], float64(b.Pix[b.PixOffset(b.Rect.Min.X+x, b.Rect.Min.Y+y)+3]))
+				}
+				for c := range 3 {
+					for k, b := range bufs {
+						v[k] = float64(b.Pix[b.PixOffset(b.Rect.Min.X+x, b.Rect.Min.Y+y)+c])
+					}
+					res[i+c] = fn(v)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch norm {
+	case NORMALIZE_RANGE:
+	case NORMALIZE_AUTO:
+		values := []float32{}
+		for i := 0; i < len(res); i += 4 {
+			if res[i+3] == 0 {
+				continue
+			}
+			for _, v := range res[i : i+3] {
+				if !math.IsNaN(v) && !math.IsInf(v, 0) {
+					values = append(values, float32(v))
+				}
+			}
+		}
+		slices.Sort(values)
+		clip = math.Min(50, math.Max(0, clip))
+		lo, hi = percentile(values, clip), percentile(values, 100-clip)
+	default:
+		lo, hi = 0, 1
+	}
+	scale := 0.0
+	if hi != lo {
+		scale = 1 / (hi - lo)
+	}
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+	_ = executor.Rows(ctx, 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				i := (y*w + x) * 4
+				px := [4]float32{0, 0, 0, float32(res[i+3])}
+				for c, v := range res[i : i+3] {
+					if math.IsNaN(v) || math.IsInf(v, 0) {
+						px[3] = 0
+						break
+					}
+					px[c] = float32((v - lo) * scale)
+				}
+				dst.SetPixel(x, y, px)
+			}
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Image{raw: dst, path: "", mu: &sync.Mutex{}}, nil
+}
@@ -0,0 +1,117 @@
+package image
+
+import (
+	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/math"
+)
+
+// pixelFunc blends the source pixel s onto the destination pixel d at (x, y).
+// Both are non-premultiplied R, G, B and A values in the range [0, 1], the result is stored in d.
+type pixelFunc func(x, y int, d, s []float32)
+
+// pixelBlender returns a pixelFunc that gives the same results as blend.CompositeIn.
+// Normal and separable blend modes work directly on the channels (see channelBlender),
+// all other modes use blend.CompositeIn. seed selects the pixels that pass when dissolving.
+func pixelBlender(mode blend.BlendMode, op blend.CompositeOp, alpha float64, space blend.BlendSpace, seed uint64) pixelFunc {
+	if _, found := blend.BlendModes[mode]; !found {
+		mode = blend.NORMAL
+	}
+	if mode == blend.ERASE && op != "" {
+		mode, op = blend.NORMAL, blend.DST_OUT
+	}
+	channel, found := blend.Channels[mode]
+	if !found && mode != blend.NORMAL {
+		return compositeBlender(mode, op, alpha, space, seed)
+	}
+	var fn blend.CustomFunc
+	if channel != nil {
+		fn = func(a, b, _ float64) float64 { return channel(a*0xFF, b*0xFF) / 0xFF }
+	}
+	return channelBlender(fn, op, alpha, space)
+}
+
+// channelBlender returns a pixelFunc that blends each channel with fn and composites with the operator op
+// (or the legacy over formula if op is unknown), fn == nil blends like blend.NORMAL.
+func channelBlender(fn blend.CustomFunc, op blend.CompositeOp, alpha float64, space blend.BlendSpace) pixelFunc {
+	if fn == nil {
+		fn = func(_, b, _ float64) float64 { return b }
+	}
+	linear := space == blend.SPACE_LINEAR
+	load := func(px []float32) (c [3]float64) {
+		for k := range c {
+			if c[k] = float64(px[k]); linear {
+				c[k] = convert.SRGBToLinear(c[k])
+			}
+		}
+		return c
+	}
+	store := func(px []float32, c [3]float64, a float64) {
+		for k := range c {
+			if linear {
+				c[k] = convert.LinearToSRGB(c[k])
+			}
+			px[k] = float32(c[k])
+		}
+		px[3] = float32(a)
+	}
+
+	opFunc, found := blend.CompositeOps[op]
+	if !found {
+		// legacy over formula, see blend.RGBA
+		return func(_, _ int, d, s []float32) {
+			ab := float64(d[3])
+			resAlpha := ab + float64(s[3])*(1-ab)
+			if resAlpha == 0 {
+				d[0], d[1], d[2], d[3] = 0, 0, 0, 0
+				return
+			}
+			as := float64(s[3]) * alpha
+			cb := load(d)
+			if as != 0 {
+				cs := load(s)
+				for k := range cb {
+					cb[k] = math.Clamp(cb[k]*(1-as)+fn(cb[k], cs[k], as)*as, 0, 1)
+				}
+			}
+			store(d, cb, resAlpha)
+		}
+	}
+	return func(_, _ int, d, s []float32) {
+		ab, as := float64(d[3]), float64(s[3])*alpha
+		fa, fb := opFunc(as, ab)
+		resAlpha := as*fa + ab*fb
+		if resAlpha <= 0 {
+			d[0], d[1], d[2], d[3] = 0, 0, 0, 0
+			return
+		}
+		cb, cs := load(d), load(s)
+		for k := range cb {
+			c := (1-ab)*cs[k] + ab*math.Clamp(fn(cb[k], cs[k], as), 0, 1)
+			cb[k] = math.Clamp((c*as*fa+cb[k]*ab*fb)/resAlpha, 0, 1)
+		}
+		store(d, cb, math.Min(resAlpha, 1))
+	}
+}
+
+// compositeBlender returns a pixelFunc that uses blend.CompositeIn, it also handles the dithering of blend.DISSOLVE.
+func compositeBlender(mode blend.BlendMode, op blend.CompositeOp, alpha float64, space blend.BlendSpace, seed uint64) pixelFunc {
+	return func(x, y int, d, s []float32) {
+		m, a, as := mode, alpha, float64(s[3])
+		if mode == blend.DISSOLVE {
+			// dissolving shows either the opaque or the fully transparent source pixel, alpha only defines how many of them pass
+			m, a, as = blend.NORMAL, 1.0, 0.0
+			if blend.Dissolve(seed, x, y, float64(s[3])*alpha) {
+				as = 1.0
+			}
+		}
+		c := blend.CompositeIn(
+			rgba.New(float64(d[0])*0xFF, float64(d[1])*0xFF, float64(d[2])*0xFF, float64(d[3])*0xFF),
+			rgba.New(float64(s[0])*0xFF, float64(s[1])*0xFF, float64(s[2])*0xFF, as*0xFF),
+			m, op, a, space,
+		)
+		px := rgbaToPixel(c)
+		copy(d, px[:])
+	}
+}
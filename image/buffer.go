@@ -0,0 +1,187 @@
+package image
+
+import (
+	"image"
+	"image/color"
+)
+
+// Buffer is a high-bit-depth pixel buffer that stores four float32 values (R, G, B, A) per pixel.
+// Channels are non-premultiplied and range from 0 to 1. Fully transparent pixels are always stored
+// as transparent black, so they behave the same way as in a premultiplied buffer.
+//
+// Buffer implements draw.Image, so it can be used with the standard library, but quantization
+// only happens when converting it to an 8-bit image (see NRGBA).
+type Buffer struct {
+	Pix    []float32       // Pixel values in R, G, B, A order.
+	Stride int             // Number of values between vertically adjacent pixels.
+	Rect   image.Rectangle // Bounds of the buffer.
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 || v != v { // v != v catches NaN
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func (b *Buffer) ColorModel() color.Model { return color.NRGBA64Model }
+func (b *Buffer) Bounds() image.Rectangle { return b.Rect }
+
+// PixOffset returns the index of the first value of the pixel at (x, y).
+func (b *Buffer) PixOffset(x, y int) int {
+	return (y-b.Rect.Min.Y)*b.Stride + (x-b.Rect.Min.X)*4
+}
+
+// PixelAt returns the non-premultiplied R, G, B and A values of the pixel at (x, y).
+// Pixels outside the buffer are transparent black.
+func (b *Buffer) PixelAt(x, y int) [4]float32 {
+	if !(image.Point{x, y}.In(b.Rect)) {
+		return [4]float32{}
+	}
+	i := b.PixOffset(x, y)
+	return [4]float32{b.Pix[i], b.Pix[i+1], b.Pix[i+2], b.Pix[i+3]}
+}
+
+// SetPixel sets the pixel at (x, y) to the given non-premultiplied R, G, B and A values.
+// Values are clamped to the range [0, 1], coordinates outside the buffer are ignored.
+func (b *Buffer) SetPixel(x, y int, px [4]float32) {
+	if !(image.Point{x, y}.In(b.Rect)) {
+		return
+	}
+	i := b.PixOffset(x, y)
+	a := clamp01(px[3])
+	if a == 0 {
+		b.Pix[i], b.Pix[i+1], b.Pix[i+2], b.Pix[i+3] = 0, 0, 0, 0
+		return
+	}
+	b.Pix[i] = clamp01(px[0])
+	b.Pix[i+1] = clamp01(px[1])
+	b.Pix[i+2] = clamp01(px[2])
+	b.Pix[i+3] = a
+}
+
+func (b *Buffer) At(x, y int) color.Color {
+	px := b.PixelAt(x, y)
+	return color.NRGBA64{
+		R: uint16(px[0]*0xFFFF + 0.5),
+		G: uint16(px[1]*0xFFFF + 0.5),
+		B: uint16(px[2]*0xFFFF + 0.5),
+		A: uint16(px[3]*0xFFFF + 0.5),
+	}
+}
+
+func (b *Buffer) Set(x, y int, c color.Color) {
+	b.SetPixel(x, y, fromColor(c))
+}
+
+// Fill sets all pixels of the buffer that are within r to px.
+func (b *Buffer) Fill(r image.Rectangle, px [4]float32) {
+	r = r.Canon().Intersect(b.Rect)
+	if r.Empty() {
+		return
+	}
+	// fill the first row and then copy it to all others
+	first := b.PixOffset(r.Min.X, r.Min.Y)
+	for x := r.Min.X; x < r.Max.X; x++ {
+		b.SetPixel(x, r.Min.Y, px)
+	}
+	row := b.Pix[first : first+r.Dx()*4]
+	for y := r.Min.Y + 1; y < r.Max.Y; y++ {
+		i := b.PixOffset(r.Min.X, y)
+		copy(b.Pix[i:i+len(row)], row)
+	}
+}
+
+// Clone returns a deep copy of the buffer.
+func (b *Buffer) Clone() *Buffer {
+	pix := make([]float32, len(b.Pix))
+	copy(pix, b.Pix)
+	return &Buffer{Pix: pix, Stride: b.Stride, Rect: b.Rect}
+}
+
+// NRGBA quantizes the buffer to an 8-bit image.
+func (b *Buffer) NRGBA() *image.NRGBA {
+	res := image.NewNRGBA(b.Rect)
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		src := b.Pix[b.PixOffset(b.Rect.Min.X, y):]
+		dst := res.Pix[res.PixOffset(b.Rect.Min.X, y):]
+		for i := 0; i < b.Rect.Dx()*4; i++ {
+			dst[i] = uint8(src[i]*0xFF + 0.5)
+		}
+	}
+	return res
+}
+
+// fromColor converts any color to non-premultiplied float32 values.
+// color.Color.RGBA returns 16-bit premultiplied values, so no precision is lost for 8-bit and 16-bit colors.
+func fromColor(c color.Color) [4]float32 {
+	if n, ok := c.(color.NRGBA64); ok {
+		return [4]float32{float32(n.R) / 0xFFFF, float32(n.G) / 0xFFFF, float32(n.B) / 0xFFFF, float32(n.A) / 0xFFFF}
+	}
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return [4]float32{}
+	}
+	fa := float32(a)
+	return [4]float32{float32(r) / fa, float32(g) / fa, float32(b) / fa, fa / 0xFFFF}
+}
+
+// NewBuffer returns a transparent buffer with the given bounds.
+func NewBuffer(r image.Rectangle) *Buffer {
+	return &Buffer{
+		Pix:    make([]float32, 4*r.Dx()*r.Dy()),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// toBuffer converts any image to a buffer whose bounds start at (0, 0).
+func toBuffer(img image.Image) *Buffer {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	res := NewBuffer(image.Rect(0, 0, w, h))
+
+	switch t := img.(type) {
+	case *Buffer:
+		for y := range h {
+			copy(res.Pix[res.PixOffset(0, y):res.PixOffset(w, y)], t.Pix[t.PixOffset(b.Min.X, b.Min.Y+y):])
+		}
+	case *image.NRGBA:
+		for y := range h {
+			src := t.Pix[t.PixOffset(b.Min.X, b.Min.Y+y):]
+			for x := range w {
+				s := src[x*4 : x*4+4]
+				res.SetPixel(x, y, [4]float32{float32(s[0]) / 0xFF, float32(s[1]) / 0xFF, float32(s[2]) / 0xFF, float32(s[3]) / 0xFF})
+			}
+		}
+	case *image.NRGBA64:
+		for y := range h {
+			for x := range w {
+				res.SetPixel(x, y, fromColor(t.NRGBA64At(b.Min.X+x, b.Min.Y+y)))
+			}
+		}
+	case *image.RGBA:
+		for y := range h {
+			src := t.Pix[t.PixOffset(b.Min.X, b.Min.Y+y):]
+			for x := range w {
+				s := src[x*4 : x*4+4]
+				if s[3] == 0 {
+					continue
+				}
+				a := float32(s[3])
+				res.SetPixel(x, y, [4]float32{float32(s[0]) / a, float32(s[1]) / a, float32(s[2]) / a, a / 0xFF})
+			}
+		}
+	default:
+		// covers RGBA64, Gray, Gray16, Alpha, Alpha16, CMYK, YCbCr, NYCbCrA and Paletted images
+		for y := range h {
+			for x := range w {
+				res.SetPixel(x, y, fromColor(img.At(b.Min.X+x, b.Min.Y+y)))
+			}
+		}
+	}
+	return res
+}
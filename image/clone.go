@@ -1,15 +1,11 @@
 package image
 
 import (
-	"image"
-	"image/draw"
 	"sync"
 )
 
 func (i *Image) Clone() *Image {
 	i.Lock()
 	defer i.Unlock()
-	rgbaImage := image.NewRGBA(i.raw.Bounds())
-	draw.Draw(rgbaImage, rgbaImage.Bounds(), i.raw, image.Point{}, draw.Src)
-	return &Image{raw: rgbaImage, path: i.path, mu: &sync.Mutex{}}
+	return &Image{raw: i.raw.Clone(), path: i.path, ctx: i.ctx, mu: &sync.Mutex{}}
 }
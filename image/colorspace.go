@@ -0,0 +1,130 @@
+package image
+
+import (
+	"strings"
+
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/math"
+)
+
+// ColorSpace is a cylindrical color space (hue, saturation or chroma and lightness) that color adjustments can work in.
+// SPACE_RGB is for adjustments that can work on the RGB channels directly, the others treat it like SPACE_HSL.
+type ColorSpace string
+
+const (
+	SPACE_RGB   ColorSpace = "rgb"   // the RGB channels relative to their luma
+	SPACE_HSL   ColorSpace = "hsl"   // saturation and lightness of HSL
+	SPACE_HSV   ColorSpace = "hsv"   // saturation and value of HSV
+	SPACE_LCH   ColorSpace = "lch"   // chroma and lightness of CIE LCh(ab)
+	SPACE_OKLCH ColorSpace = "oklch" // chroma and lightness of OKLCh, changing the hue keeps the perceived lightness
+)
+
+// maximum chroma of sRGB colors, used to scale the chroma of LCh and OKLCh to about [0, 1]
+const (
+	maxChromaLCh   = 134.0
+	maxChromaOKLCh = 0.32
+)
+
+// ColorSpaceNames returns the names of all color spaces.
+func ColorSpaceNames() []string {
+	return []string{string(SPACE_HSL), string(SPACE_HSV), string(SPACE_LCH), string(SPACE_OKLCH)}
+}
+
+// ParseColorSpace returns the color space with the given name (one of ColorSpaceNames or SPACE_RGB),
+// unknown names return SPACE_HSL.
+func ParseColorSpace(name string) ColorSpace {
+	switch s := ColorSpace(strings.ToLower(strings.TrimSpace(name))); s {
+	case SPACE_RGB, SPACE_HSV, SPACE_LCH, SPACE_OKLCH:
+		return s
+	}
+	return SPACE_HSL
+}
+
+// FromRGB converts red, green and blue in the range [0, 1] to hue (in degrees [0, 360)), saturation and lightness.
+// For LCh and OKLCh the saturation is the chroma scaled so that 1 is about the most saturated sRGB color
+// and the lightness is scaled to [0, 1].
+func (s ColorSpace) FromRGB(r, g, b float64) (h, sat, l float64) {
+	switch s {
+	case SPACE_HSV:
+		return convert.RGBToHSV(r, g, b)
+	case SPACE_LCH:
+		l, c, h := convert.RGBToLCh(r, g, b)
+		return h, c / maxChromaLCh, l / 100
+	case SPACE_OKLCH:
+		l, c, h := convert.RGBToOKLCh(r, g, b)
+		return h, c / maxChromaOKLCh, l
+	}
+	return convert.RGBToHSL(r, g, b)
+}
+
+// ToRGB converts hue, saturation and lightness as returned by FromRGB back to red, green and blue.
+// The hue is wrapped and saturation and lightness are clamped. Colors outside of the sRGB gamut keep their
+// hue and lightness in LCh and OKLCh, their chroma is reduced until they fit.
+func (s ColorSpace) ToRGB(h, sat, l float64) (r, g, b float64) {
+	h = math.Wrap(h, 0.0, 360.0)
+	l = math.Clamp(l, 0.0, 1.0)
+	switch s {
+	case SPACE_HSV:
+		return convert.HSVToRGB(h, math.Clamp(sat, 0.0, 1.0), l)
+	case SPACE_LCH:
+		linear := func(c float64) (float64, float64, float64) {
+			return convert.XYZToLinearRGB(convert.LabToXYZ(convert.LChToLab(l*100, c, h)))
+		}
+		return convert.LChToRGB(l*100, fitChroma(math.Max(sat, 0)*maxChromaLCh, linear), h)
+	case SPACE_OKLCH:
+		linear := func(c float64) (float64, float64, float64) {
+			return convert.OKLabToLinearRGB(convert.LChToLab(l, c, h))
+		}
+		return convert.OKLChToRGB(l, fitChroma(math.Max(sat, 0)*maxChromaOKLCh, linear), h)
+	}
+	return convert.HSLToRGB(h, math.Clamp(sat, 0.0, 1.0), l)
+}
+
+// fitChroma returns the largest chroma up to c for which linear returns a color inside the sRGB gamut.
+func fitChroma(c float64, linear func(c float64) (r, g, b float64)) float64 {
+	const eps = 1e-6
+	inGamut := func(c float64) bool {
+		r, g, b := linear(c)
+		return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+	}
+	if inGamut(c) {
+		return c
+	}
+	lo, hi := 0.0, c
+	for range 20 {
+		if mid := (lo + hi) / 2; inGamut(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// ProcessPixelsIn is like ProcessPixelsHSLA but fn receives the pixel as hue, saturation, lightness
+// and alpha in the given color space (see ColorSpace.FromRGB).
+func (i *Image) ProcessPixelsIn(space ColorSpace, fn func(x, y int, hsla []float64)) *Image {
+	if space == SPACE_HSL {
+		return i.ProcessPixelsHSLA(fn)
+	}
+	buf := i.Get()
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	_ = executor.Rows(i.Context(), 0, h, func(y0, y1 int) {
+		hsla := make([]float64, 4)
+		for y := y0; y < y1; y++ {
+			off := buf.PixOffset(buf.Rect.Min.X, buf.Rect.Min.Y+y)
+			row := buf.Pix[off : off+w*4]
+			for x := 0; x < w; x++ {
+				px := row[x*4 : x*4+4 : x*4+4]
+				hsla[0], hsla[1], hsla[2] = space.FromRGB(float64(px[0]), float64(px[1]), float64(px[2]))
+				hsla[3] = float64(px[3])
+				fn(x, y, hsla)
+				r, g, b := space.ToRGB(hsla[0], hsla[1], hsla[2])
+				px[0], px[1], px[2], px[3] = float32(r), float32(g), float32(b), float32(hsla[3])
+				normalize(px)
+			}
+		}
+	})
+	return i
+}
@@ -3,8 +3,6 @@ package image
 import (
 	"image"
 	"sync"
-
-	"github.com/toxyl/gfx/color/rgba"
 )
 
 // Crop creates a clone of the image and crops it according to the `fullCrop` parameter:
@@ -19,10 +17,10 @@ func (i *Image) Crop(x, y, w, h int, fullCrop bool) *Image {
 		// this is a "real" crop operation
 		// where the output image
 		// dimensions match the crop area
-		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		dst := NewBuffer(image.Rect(0, 0, w, h))
 		for y1 := range h {
 			for x1 := range w {
-				dst.Set(x1, y1, i.raw.RGBAAt(x+x1, y+y1))
+				dst.SetPixel(x1, y1, i.raw.PixelAt(x+x1, y+y1))
 			}
 		}
 		return &Image{raw: dst, path: i.path, mu: &sync.Mutex{}}
@@ -32,10 +30,10 @@ func (i *Image) Crop(x, y, w, h int, fullCrop bool) *Image {
 	// outside the crop area to be transparent
 	iw := i.W()
 	ih := i.H()
-	dst := image.NewRGBA(image.Rect(0, 0, iw, ih))
+	dst := NewBuffer(image.Rect(0, 0, iw, ih))
 	for y1 := range h {
 		for x1 := range w {
-			dst.Set(x+x1, y+y1, i.raw.RGBAAt(x+x1, y+y1))
+			dst.SetPixel(x+x1, y+y1, i.raw.PixelAt(x+x1, y+y1))
 		}
 	}
 	return &Image{raw: dst, path: i.path, mu: &sync.Mutex{}}
@@ -47,12 +45,10 @@ func (i *Image) Crop(x, y, w, h int, fullCrop bool) *Image {
 func (i *Image) CropCircle(centerX, centerY, radius int, fullCrop bool) *Image {
 	i.Lock()
 	defer i.Unlock()
-	transp := rgba.New(0, 0, 0, 0).RGBA()
-
 	if fullCrop {
 		// Define the bounding box for the circle.
 		diameter := 2 * radius
-		dst := image.NewRGBA(image.Rect(0, 0, diameter, diameter))
+		dst := NewBuffer(image.Rect(0, 0, diameter, diameter))
 		// The top-left of the new image corresponds to (centerX - radius, centerY - radius) in the original.
 		startX := centerX - radius
 		startY := centerY - radius
@@ -66,13 +62,8 @@ func (i *Image) CropCircle(centerX, centerY, radius int, fullCrop bool) *Image {
 				if dx*dx+dy*dy <= radius*radius {
 					origX := startX + x
 					origY := startY + y
-					// Only copy if the corresponding coordinate is within the original image.
-					if origX >= 0 && origX < i.raw.Bounds().Dx() && origY >= 0 && origY < i.raw.Bounds().Dy() {
-						dst.Set(x, y, i.raw.At(origX, origY))
-					} else {
-						// Out-of-bounds: set transparent.
-						dst.Set(x, y, transp)
-					}
+					// Out-of-bounds coordinates stay transparent.
+					dst.SetPixel(x, y, i.raw.PixelAt(origX, origY))
 				}
 			}
 		}
@@ -83,13 +74,13 @@ func (i *Image) CropCircle(centerX, centerY, radius int, fullCrop bool) *Image {
 	// but set pixels outside the circle to transparent.
 	iw := i.raw.Bounds().Dx()
 	ih := i.raw.Bounds().Dy()
-	dst := image.NewRGBA(image.Rect(0, 0, iw, ih))
+	dst := NewBuffer(image.Rect(0, 0, iw, ih))
 	for y := range ih {
 		for x := range iw {
 			dx := x - centerX
 			dy := y - centerY
 			if dx*dx+dy*dy <= radius*radius {
-				dst.Set(x, y, i.raw.At(x, y))
+				dst.SetPixel(x, y, i.raw.PixelAt(x, y))
 			}
 		}
 	}
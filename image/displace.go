@@ -0,0 +1,48 @@
+package image
+
+import (
+	"image"
+	"sync"
+
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+// Displace replaces every pixel with the pixel at an offset read from the displacement map dmap. Channel chX
+// of the map controls the horizontal and chY the vertical offset: the middle of the channel's range means no offset,
+// the ends of the range mean -strength and +strength times the width or height of the image, so with
+// strength 0.05 pixels are taken from up to 5% of the image size away in each direction. Transparent parts of the map
+// don't displace. The map is stretched to the size of the image and interpolated bilinearly.
+// Displaced positions are sampled with the given kernel, positions outside of the image are handled
+// according to edge. Rows are processed on the shared executor and processing stops early if the context
+// of the image is cancelled.
+func (i *Image) Displace(dmap *Image, strength float64, chX, chY Channel, kernel resample.Kernel, edge resample.Edge) *Image {
+	ctx := i.Context()
+	src := i.Get()
+	m := dmap.Get()
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	mw, mh := m.Bounds().Dx(), m.Bounds().Dy()
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+	kernel = resample.Parse(string(kernel))
+	edge = resample.ParseEdge(string(edge))
+
+	// offset converts a channel value to an offset in [-1, 1]
+	offset := func(ch Channel, px [4]float32) float64 {
+		lo, hi := ch.Range()
+		return ((ch.value(px)-lo)/(hi-lo)*2 - 1) * float64(px[3])
+	}
+	sx, sy := float64(mw)/float64(w), float64(mh)/float64(h)
+	ax, ay := strength*float64(w), strength*float64(h)
+	_ = executor.Rows(ctx, 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			my := (float64(y)+0.5)*sy - 0.5
+			for x := range w {
+				mx := (float64(x)+0.5)*sx - 0.5
+				px := m.sample(mx, my, resample.BILINEAR, resample.CLAMP)
+				dx, dy := offset(chX, px)*ax, offset(chY, px)*ay
+				dst.SetPixel(x, y, src.sample(float64(x)+dx, float64(y)+dy, kernel, edge))
+			}
+		}
+	})
+	return &Image{raw: dst, path: i.path, mu: &sync.Mutex{}}
+}
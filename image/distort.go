@@ -0,0 +1,89 @@
+package image
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/image/resample"
+)
+
+// Swirl twists the image around (centerX, centerY). Pixels at the center are rotated by angle degrees
+// (positive angles rotate clockwise), the rotation fades out smoothly towards radius (in pixels),
+// pixels beyond it are left unchanged.
+func (i *Image) Swirl(centerX, centerY, angle, radius float64, kernel resample.Kernel, edge resample.Edge) *Image {
+	theta := angle * math.Pi / 180.0
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		dx, dy := x-centerX, y-centerY
+		r := math.Hypot(dx, dy)
+		if r >= radius {
+			return x, y, true
+		}
+		t := 1 - r/radius
+		sin, cos := math.Sincos(-theta * t * t)
+		return centerX + dx*cos - dy*sin, centerY + dx*sin + dy*cos, true
+	}, kernel, edge)
+}
+
+// Pinch pulls the image towards (centerX, centerY) within radius (in pixels). amount (-1..1) controls the strength,
+// positive values pinch and negative values bulge. Pixels beyond the radius are left unchanged.
+func (i *Image) Pinch(centerX, centerY, amount, radius float64, kernel resample.Kernel, edge resample.Edge) *Image {
+	amount = math.Max(-1, math.Min(1, amount))
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		dx, dy := x-centerX, y-centerY
+		r := math.Hypot(dx, dy)
+		if r >= radius || r == 0 {
+			return x, y, true
+		}
+		f := math.Pow(math.Sin(math.Pi/2*r/radius), -amount)
+		return centerX + dx*f, centerY + dy*f, true
+	}, kernel, edge)
+}
+
+// Wave shifts the image sideways along a sine wave. The wave travels in the direction of angle (in degrees,
+// 0 = left to right, positive angles rotate clockwise) and has its phase (in degrees) at (centerX, centerY).
+// amplitude and wavelength are in pixels.
+func (i *Image) Wave(centerX, centerY, amplitude, wavelength, angle, phase float64, kernel resample.Kernel, edge resample.Edge) *Image {
+	if wavelength == 0 {
+		return i.Clone()
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180.0)
+	k := 2 * math.Pi / wavelength
+	p := phase * math.Pi / 180.0
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		d := amplitude * math.Sin(((x-centerX)*cos+(y-centerY)*sin)*k+p)
+		return x - d*sin, y + d*cos, true
+	}, kernel, edge)
+}
+
+// Ripple moves the image along concentric sine waves around (centerX, centerY), like ripples on water.
+// amplitude and wavelength are in pixels, phase is in degrees.
+func (i *Image) Ripple(centerX, centerY, amplitude, wavelength, phase float64, kernel resample.Kernel, edge resample.Edge) *Image {
+	if wavelength == 0 {
+		return i.Clone()
+	}
+	k := 2 * math.Pi / wavelength
+	p := phase * math.Pi / 180.0
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		dx, dy := x-centerX, y-centerY
+		r := math.Hypot(dx, dy)
+		if r == 0 {
+			return x, y, true
+		}
+		f := 1 + amplitude*math.Sin(r*k+p)/r
+		return centerX + dx*f, centerY + dy*f, true
+	}, kernel, edge)
+}
+
+// LensDistortion applies radial (barrel or pincushion) distortion around (centerX, centerY) with the
+// Brown-Conrady coefficients k1 and k2: a pixel at the normalized distance r from the center is sampled
+// at r * (1 + k1*r^2 + k2*r^4), where r is 1 in the corners of the image. Positive coefficients add barrel
+// distortion, which corrects pincushion distortion, negative coefficients add pincushion distortion,
+// which corrects barrel distortion.
+func (i *Image) LensDistortion(centerX, centerY, k1, k2 float64, kernel resample.Kernel, edge resample.Edge) *Image {
+	norm := math.Hypot(float64(i.W()), float64(i.H())) / 2
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		dx, dy := x-centerX, y-centerY
+		r2 := (dx*dx + dy*dy) / (norm * norm)
+		f := 1 + k1*r2 + k2*r2*r2
+		return centerX + dx*f, centerY + dy*f, true
+	}, kernel, edge)
+}
@@ -1,18 +1,66 @@
 package image
 
 import (
+	"image"
+
 	"github.com/toxyl/gfx/color/blend"
 	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/image/executor"
 )
 
 func (i *Image) Draw(src *Image, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH int, mode blend.BlendMode, alpha float64) *Image {
-	s := src.Crop(srcX, srcY, srcW, srcH, true)
+	return i.DrawIn(src, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH, mode, alpha, blend.SPACE_SRGB)
+}
+
+// DrawIn is like Draw but blends in the given space, with blend.SPACE_LINEAR colors are mixed in linear light.
+func (i *Image) DrawIn(src *Image, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH int, mode blend.BlendMode, alpha float64, space blend.BlendSpace) *Image {
+	return i.DrawComposite(src, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH, mode, "", alpha, space)
+}
+
+// DrawComposite is like DrawIn but composites with the Porter-Duff operator op, see blend.Composite.
+// The operator applies to the whole destination rectangle, so operators like src-in also clear
+// the destination where the source is transparent. blend.DISSOLVE dissolves with seed 0, see DrawDissolve.
+func (i *Image) DrawComposite(src *Image, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH int, mode blend.BlendMode, op blend.CompositeOp, alpha float64, space blend.BlendSpace) *Image {
+	return i.draw(src, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH, pixelBlender(mode, op, alpha, space, 0))
+}
+
+// DrawDissolve is like DrawComposite with blend.DISSOLVE, seed selects the pixels that pass (see blend.Dissolve).
+func (i *Image) DrawDissolve(src *Image, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH int, op blend.CompositeOp, alpha float64, space blend.BlendSpace, seed uint64) *Image {
+	return i.draw(src, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH, pixelBlender(blend.DISSOLVE, op, alpha, space, seed))
+}
+
+// DrawCustom is like DrawComposite but blends each channel with the user-defined function fn.
+// As with separable blend modes the result of fn is mixed with the destination by the alpha of the source.
+func (i *Image) DrawCustom(src *Image, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH int, fn blend.CustomFunc, op blend.CompositeOp, alpha float64, space blend.BlendSpace) *Image {
+	return i.draw(src, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH, channelBlender(fn, op, alpha, space))
+}
+
+// draw blends src onto the image with fn, parts of the destination outside of the image are skipped.
+func (i *Image) draw(src *Image, srcX, srcY, srcW, srcH, dstX, dstY, dstW, dstH int, fn pixelFunc) *Image {
+	s := src
+	if src == i || srcX != 0 || srcY != 0 || srcW != src.W() || srcH != src.H() {
+		s = src.Crop(srcX, srcY, srcW, srcH, true)
+	}
 	if srcW != dstW || srcH != dstH {
 		s = s.Resize(dstW, dstH)
 	}
-	return i.mergeHSLA(s, 0, 0, dstW, dstH, func(x, y int, srcCol *hsla.HSLA) (x2 int, y2 int, col2 *hsla.HSLA) {
-		return x + dstX, y + dstY, blend.HSLA(i.GetHSLA(x+dstX, y+dstY), srcCol, mode, alpha)
+	raw, srcRaw := i.Get(), s.Get()
+	r := image.Rect(dstX, dstY, dstX+dstW, dstY+dstH).Intersect(raw.Rect)
+	if r.Empty() {
+		return i
+	}
+	_ = executor.Rows(i.Context(), r.Min.Y, r.Max.Y, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			d := raw.Pix[raw.PixOffset(r.Min.X, y):]
+			s := srcRaw.Pix[srcRaw.PixOffset(srcRaw.Rect.Min.X+r.Min.X-dstX, srcRaw.Rect.Min.Y+y-dstY):]
+			for x, o := r.Min.X, 0; x < r.Max.X; x, o = x+1, o+4 {
+				px := d[o : o+4 : o+4]
+				fn(x, y, px, s[o:o+4:o+4])
+				normalize(px)
+			}
+		}
 	})
+	return i
 }
 
 func (i *Image) DrawLineV(x, yStart, yEnd, thickness int, col *hsla.HSLA, mode blend.BlendMode) {
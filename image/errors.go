@@ -0,0 +1,11 @@
+package image
+
+// IOError is returned when an image (or any other file) can't be read or written.
+type IOError struct {
+	Op   string // Operation that failed, e.g. "load" or "save".
+	Path string // File path or URL.
+	Err  error  // Underlying error.
+}
+
+func (e *IOError) Error() string { return "failed to " + e.Op + " " + e.Path + ": " + e.Err.Error() }
+func (e *IOError) Unwrap() error { return e.Err }
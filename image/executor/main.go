@@ -0,0 +1,133 @@
+// Package executor runs pixel processing on a process-wide pool of workers.
+//
+// Work is split into bands of rows. The calling goroutine always processes bands itself and hands
+// bands to pool workers that are idle, so the number of goroutines doing pixel work is bounded by the
+// pool size (see SetConcurrency) no matter how many images are processed at the same time. A context
+// can lower the limit for the work started with it (see WithLimit).
+package executor
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// bandsPerWorker is the number of bands per available worker, more bands balance uneven work better.
+const bandsPerWorker = 4
+
+var (
+	mu      sync.Mutex
+	once    sync.Once
+	jobs    = make(chan func())
+	workers int
+)
+
+func worker() {
+	for job := range jobs {
+		if job == nil {
+			return
+		}
+		job()
+	}
+}
+
+func start() {
+	once.Do(func() {
+		if Concurrency() == 0 {
+			SetConcurrency(0)
+		}
+	})
+}
+
+// SetConcurrency sets the number of pool workers, n < 1 uses one worker per CPU.
+// Shrinking the pool waits until the surplus workers have finished their current band.
+func SetConcurrency(n int) {
+	if n < 1 {
+		n = runtime.NumCPU()
+	}
+	mu.Lock()
+	for ; workers < n; workers++ {
+		go worker()
+	}
+	surplus := workers - n
+	workers -= max(surplus, 0)
+	mu.Unlock()
+	// stopping workers must not hold the lock, busy workers might need it to finish their band
+	for range surplus {
+		jobs <- nil
+	}
+}
+
+// Concurrency returns the number of pool workers.
+func Concurrency() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return workers
+}
+
+type limitKey struct{}
+
+// WithLimit returns a copy of ctx that limits work started with it to n goroutines, including the caller.
+func WithLimit(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, limitKey{}, n)
+}
+
+// Limit returns the number of goroutines that may work on bands started with ctx.
+func Limit(ctx context.Context) int {
+	start()
+	n := Concurrency()
+	if l, ok := ctx.Value(limitKey{}).(int); ok && l > 0 && l < n {
+		n = l
+	}
+	return n
+}
+
+// Rows calls fn for bands of consecutive rows that together cover [y0, y1), fn must be safe for concurrent use.
+// Rows returns when all bands are done. Bands that haven't started when ctx is cancelled are skipped and
+// ctx.Err() is returned. Rows can be nested because the caller never waits for a busy worker.
+func Rows(ctx context.Context, y0, y1 int, fn func(y0, y1 int)) error {
+	n := y1 - y0
+	if n <= 0 {
+		return ctx.Err()
+	}
+	limit := Limit(ctx)
+	size := (n + limit*bandsPerWorker - 1) / (limit * bandsPerWorker)
+	bands := (n + size - 1) / size
+
+	var (
+		next atomic.Int64
+		wg   sync.WaitGroup
+		done = ctx.Done()
+	)
+	work := func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			b := int(next.Add(1)) - 1
+			if b >= bands {
+				return
+			}
+			start := y0 + b*size
+			fn(start, min(start+size, y1))
+		}
+	}
+	job := func() {
+		defer wg.Done()
+		work()
+	}
+	for range min(limit, bands) - 1 {
+		wg.Add(1)
+		select {
+		case jobs <- job:
+		default:
+			wg.Done() // no idle worker, the caller processes the remaining bands
+		}
+	}
+	work()
+	wg.Wait()
+	return ctx.Err()
+}
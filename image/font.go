@@ -2,9 +2,7 @@ package image
 
 import (
 	_ "embed"
-	"regexp"
-	"strconv"
-	"strings"
+	"sync"
 
 	"github.com/toxyl/gfx/color/blend"
 	"github.com/toxyl/gfx/color/hsla"
@@ -14,11 +12,25 @@ import (
 //go:embed font.png
 var fontBytes []byte
 
+// Font provides the glyphs that DrawTextWith renders.
+type Font interface {
+	// Glyph returns the glyph for r as an image whose alpha channel is the coverage of the glyph, the offset of the
+	// image relative to the pen position on the top of the line and the horizontal advance to the next glyph.
+	// The glyph image must not be modified.
+	Glyph(r rune) (glyph *Image, dx, dy int, advance float64)
+	// Kern returns the adjustment of the advance between the runes a and b.
+	Kern(a, b rune) float64
+	// LineHeight returns the distance between the tops of two lines.
+	LineHeight() float64
+}
+
 type spriteFont struct {
 	image      *Image
 	charWidth  int
 	charHeight int
 	columns    int
+	once       sync.Once
+	glyphs     []*Image
 }
 
 var (
@@ -30,6 +42,32 @@ var (
 	}
 )
 
+// SpriteFont returns the embedded 9x10 pixel font, which DrawText uses.
+// It only has glyphs for ASCII 33 to 128, other characters are drawn as space.
+func SpriteFont() Font {
+	return font
+}
+
+func (sf *spriteFont) Glyph(r rune) (*Image, int, int, float64) {
+	charIndex := int(r) - 33
+	if charIndex <= 0 || charIndex >= sf.columns*6 {
+		charIndex = 95 // Default to space character for unsupported characters
+	}
+	sf.once.Do(func() {
+		sf.glyphs = make([]*Image, sf.columns*6)
+		for i := range sf.glyphs {
+			sx := (i % sf.columns) * sf.charWidth
+			sy := (i / sf.columns) * sf.charHeight
+			sf.glyphs[i] = New(sf.charWidth, sf.charHeight)
+			sf.glyphs[i].Draw(sf.image, sx, sy, sf.charWidth, sf.charHeight, 0, 0, sf.charWidth, sf.charHeight, blend.NORMAL, 1)
+		}
+	})
+	return sf.glyphs[charIndex], 0, 0, float64(sf.charWidth)
+}
+
+func (sf *spriteFont) Kern(a, b rune) float64 { return 0 }
+func (sf *spriteFont) LineHeight() float64    { return float64(sf.charHeight) }
+
 func (i *Image) drawFontWithOutline(src *Image, dstX, dstY int, col hsla.HSLA, glow bool, mode blend.BlendMode) {
 	outlineCol := col
 	if glow {
@@ -63,6 +101,10 @@ func (i *Image) drawFontWithOutline(src *Image, dstX, dstY int, col hsla.HSLA, g
 			colSrc.SetH(col.H())
 			colSrc.SetS(col.S())
 			colSrc.SetL(col.L())
+			if colSrc.A() < 1 {
+				// anti-aliased edges of vector glyphs fade into the outline
+				colSrc = blend.HSLA(&outlineCol, colSrc, blend.NORMAL, 1.0)
+			}
 		}
 		return x, y, colSrc
 	})
@@ -76,104 +118,26 @@ func (i *Image) drawFontWithOutline(src *Image, dstX, dstY int, col hsla.HSLA, g
 
 }
 
-func (sf *spriteFont) render(text string, col hsla.HSLA, glow bool) *Image {
-	reColor := regexp.MustCompile(`^\[:(gon|goff|white|black|gray|color|r|g|b|c|m|y|):\]`)
-	reColorHSL := regexp.MustCompile(`^\[:(\d{1,3}):(\d\.\d+):(\d\.\d+):\]`)
-	cleanText := reColorHSL.ReplaceAllString(text, "")
-	cleanText = reColor.ReplaceAllString(cleanText, "")
-	initialColor := col
-
-	w := int(float64(len(cleanText)) * float64(sf.charWidth))
-	h := int(float64(sf.charHeight)) + strings.Count(text, "\n")*sf.charHeight
-
-	res := New(w, h)
-	yOffset := 0
-	xOffset := 0
-	nextI := 0
-	for i, char := range text {
-		if i < nextI {
-			continue
-		}
-		if char == '\n' {
-			yOffset += sf.charHeight
-			xOffset = 0
-			continue
-		}
-		if char == '[' {
-			if reColor.MatchString(text[i:]) {
-				matches := reColor.FindAllStringSubmatch(text[i:], 1)
-				if len(matches) > 0 {
-					m := matches[0]
-					switch m[1] {
-					case "":
-						col = initialColor
-					case "white":
-						col.SetS(0)
-						col.SetL(1)
-					case "black":
-						col.SetS(0)
-						col.SetL(0)
-					case "gray":
-						col.SetS(0)
-					case "color":
-						col.SetS(0.5)
-					case "r":
-						col.SetH(0)
-					case "g":
-						col.SetH(120)
-					case "b":
-						col.SetH(240)
-					case "c":
-						col.SetH(180)
-					case "m":
-						col.SetH(300)
-					case "y":
-						col.SetH(60)
-					case "gon":
-						glow = true
-					case "goff":
-						glow = false
-					}
-					nextI = i + len(m[0])
-					continue
-				}
-			}
-			if reColorHSL.MatchString(text[i:]) {
-				matches := reColorHSL.FindAllStringSubmatch(text[i:], 1)
-				if len(matches) > 0 {
-					m := matches[0]
-					if h, err := strconv.Atoi(m[1]); err == nil {
-						col.SetH(float64(h))
-					}
-					if s, err := strconv.ParseFloat(m[2], 64); err == nil {
-						col.SetS(float64(s))
-					}
-					if l, err := strconv.ParseFloat(m[3], 64); err == nil {
-						col.SetL(float64(l))
-					}
-					nextI = i + len(m[0])
-					continue
-				}
-			}
-		}
-		charIndex := int(char) - 33
-		if charIndex <= 0 || charIndex >= sf.columns*6 {
-			charIndex = 95 // Default to space character for unsupported characters
-		}
-
-		sx := (charIndex % sf.columns) * sf.charWidth
-		sy := (charIndex / sf.columns) * sf.charHeight
-		charImg := New(sf.charWidth, sf.charHeight)
-		charImg.Draw(sf.image, sx, sy, sf.charWidth, sf.charHeight, 0, 0, sf.charWidth, sf.charHeight, blend.NORMAL, 1)
-		res.drawFontWithOutline(charImg, xOffset, yOffset, col, glow, blend.NORMAL)
-
-		xOffset += sf.charWidth
+// renderText draws the text onto a new image and returns it with the position of its top left corner
+// relative to the top left of the first line.
+func renderText(f Font, text string, col hsla.HSLA, glow bool) (*Image, int, int) {
+	glyphs, bounds := layoutText(f, text, col, glow, 0, false, ALIGN_LEFT)
+	res := New(max(1, bounds.Dx()), max(1, bounds.Dy()))
+	for _, g := range glyphs {
+		res.drawFontWithOutline(g.glyph, g.x-bounds.Min.X, g.y-bounds.Min.Y, g.col, g.glow, blend.NORMAL)
 	}
-
-	return res
+	return res, bounds.Min.X, bounds.Min.Y
 }
 
+// DrawText draws text with the sprite font, x and y are the top left of the first line.
+// Newlines start a new line and inline markup changes the color (e.g. `[:r:]`, `[:120:0.5:0.5:]`, `[::]` resets it)
+// or toggles the glow (`[:gon:]`, `[:goff:]`). Glyphs are outlined, with glow the outline is a darker shade of the color.
 func (i *Image) DrawText(text string, x, y int, col hsla.HSLA, glow bool, mode blend.BlendMode) *Image {
-	src := font.render(text, col, glow)
-	return i.Draw(src, 0, 0, src.W(), src.H(), x, y, src.W(), src.H(), mode, col.A())
+	return i.DrawTextWith(font, text, x, y, col, glow, mode)
+}
+
+// DrawTextWith is like DrawText but uses the font f, e.g. a VectorFont.
+func (i *Image) DrawTextWith(f Font, text string, x, y int, col hsla.HSLA, glow bool, mode blend.BlendMode) *Image {
+	src, dx, dy := renderText(f, text, col, glow)
+	return i.Draw(src, 0, 0, src.W(), src.H(), x+dx, y+dy, src.W(), src.H(), mode, col.A())
 }
@@ -0,0 +1,56 @@
+package image
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/image/resample"
+)
+
+// FromPolar unwraps the ring around (centerX, centerY) between radiusStart and radiusEnd (in pixels)
+// into a rectangular image of size w x h, which is the inverse of ToPolar.
+// Columns map to angles from angleStart (left) to angleEnd (right) in degrees, measured clockwise from the
+// positive x-axis like ToPolar does. If angleEnd is smaller than angleStart the range wraps around 360 degrees.
+// Rows map to radii from radiusStart (top) to radiusEnd (bottom). A w or h smaller than 1 uses the width or height
+// of the source image. The source is sampled with the given kernel, samples outside of it are transparent.
+func (i *Image) FromPolar(centerX, centerY, radiusStart, radiusEnd, angleStart, angleEnd float64, w, h int, kernel resample.Kernel) *Image {
+	ctx := i.Context()
+	src := i.Get()
+	if w < 1 {
+		w = src.Bounds().Dx()
+	}
+	if h < 1 {
+		h = src.Bounds().Dy()
+	}
+	if angleEnd < angleStart {
+		angleEnd += 360
+	}
+	kernel = resample.Parse(string(kernel))
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+
+	// Like ToPolar, the first and last column/row map exactly to the ends of the ranges.
+	angleStep := 0.0
+	if w > 1 {
+		angleStep = (angleEnd - angleStart) / float64(w-1)
+	}
+	radiusStep := 0.0
+	if h > 1 {
+		radiusStep = (radiusEnd - radiusStart) / float64(h-1)
+	}
+	sin, cos := make([]float64, w), make([]float64, w)
+	for x := range w {
+		theta := (angleStart + float64(x)*angleStep) * math.Pi / 180.0
+		sin[x], cos[x] = math.Sin(theta), math.Cos(theta)
+	}
+	_ = executor.Rows(ctx, 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			r := radiusStart + float64(y)*radiusStep
+			for x := range w {
+				dst.SetPixel(x, y, src.sample(centerX+r*cos[x], centerY+r*sin[x], kernel, resample.TRANSPARENT))
+			}
+		}
+	})
+	return &Image{raw: dst, path: i.path, mu: &sync.Mutex{}}
+}
@@ -0,0 +1,190 @@
+package image
+
+import (
+	"context"
+	"image"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/image/executor"
+)
+
+// gradientSteps is the number of colors precomputed for a gradient, enough to avoid visible banding with 8-bit output.
+const gradientSteps = 1024
+
+// Interpolation defines the color space colors are interpolated in.
+type Interpolation string
+
+const (
+	INTERPOLATE_RGB   Interpolation = "rgb"   // interpolates red, green and blue
+	INTERPOLATE_HSL   Interpolation = "hsl"   // interpolates hue (along the shorter way around the color wheel), saturation and lightness
+	INTERPOLATE_OKLAB Interpolation = "oklab" // interpolates in the perceptually uniform OKLab space, avoids the muddy midpoints of RGB
+)
+
+// InterpolationNames returns the names of all interpolation modes.
+func InterpolationNames() []string {
+	return []string{string(INTERPOLATE_RGB), string(INTERPOLATE_HSL), string(INTERPOLATE_OKLAB)}
+}
+
+// ParseInterpolation returns the interpolation mode with the given name (case-insensitive), INTERPOLATE_RGB if it is unknown.
+func ParseInterpolation(name string) Interpolation {
+	switch i := Interpolation(strings.ToLower(strings.TrimSpace(name))); i {
+	case INTERPOLATE_HSL, INTERPOLATE_OKLAB:
+		return i
+	}
+	return INTERPOLATE_RGB
+}
+
+// GradientStop is a color at a position (in the range [0, 1]) of a gradient.
+type GradientStop struct {
+	Position float64
+	Color    *hsla.HSLA
+}
+
+// Gradient is a sequence of color stops. Between two stops colors are interpolated according to Interpolation,
+// before the first and after the last stop the color of that stop is used. A gradient without stops is transparent.
+type Gradient struct {
+	Stops         []GradientStop
+	Interpolation Interpolation
+}
+
+// NewGradient returns a gradient with the given stops.
+func NewGradient(interpolation Interpolation, stops ...GradientStop) *Gradient {
+	return &Gradient{Stops: stops, Interpolation: interpolation}
+}
+
+// At returns the color of the gradient at position t.
+func (g *Gradient) At(t float64) *hsla.HSLA {
+	px := g.at(g.sorted(), ParseInterpolation(string(g.Interpolation)), t)
+	h, s, l := convert.RGBToHSL(float64(px[0]), float64(px[1]), float64(px[2]))
+	return hsla.New(h, s, l, float64(px[3]))
+}
+
+// sorted returns the stops ordered by position, stops at the same position keep their order.
+func (g *Gradient) sorted() []GradientStop {
+	stops := slices.Clone(g.Stops)
+	slices.SortStableFunc(stops, func(a, b GradientStop) int {
+		switch {
+		case a.Position < b.Position:
+			return -1
+		case a.Position > b.Position:
+			return 1
+		}
+		return 0
+	})
+	return stops
+}
+
+// at returns the pixel at position t of the sorted stops.
+func (g *Gradient) at(stops []GradientStop, interpolation Interpolation, t float64) [4]float32 {
+	n := len(stops)
+	switch {
+	case n == 0:
+		return [4]float32{}
+	case t <= stops[0].Position:
+		return hslaToPixel(stops[0].Color)
+	case t >= stops[n-1].Position:
+		return hslaToPixel(stops[n-1].Color)
+	}
+	k := 1
+	for stops[k].Position < t {
+		k++
+	}
+	a, b := stops[k-1], stops[k]
+	f := (t - a.Position) / (b.Position - a.Position)
+	return interpolate(a.Color, b.Color, f, interpolation)
+}
+
+// interpolate returns the color at f (in the range [0, 1]) between c1 and c2.
+// RGB and OKLab interpolate premultiplied values, so fading to a transparent color doesn't darken the colors in between.
+func interpolate(c1, c2 *hsla.HSLA, f float64, interpolation Interpolation) [4]float32 {
+	a := c1.A()*(1-f) + c2.A()*f
+	if interpolation == INTERPOLATE_HSL {
+		h1, h2 := c1.H(), c2.H()
+		// achromatic colors have no meaningful hue, so they take the hue of the other color
+		if c1.S() == 0 || c1.L() == 0 || c1.L() == 1 {
+			h1 = h2
+		} else if c2.S() == 0 || c2.L() == 0 || c2.L() == 1 {
+			h2 = h1
+		}
+		d := math.Mod(h2-h1+540, 360) - 180
+		h := math.Mod(h1+d*f+360, 360)
+		r, g, b := convert.HSLToRGB(h, c1.S()*(1-f)+c2.S()*f, c1.L()*(1-f)+c2.L()*f)
+		return [4]float32{float32(r), float32(g), float32(b), float32(a)}
+	}
+	if a == 0 {
+		return [4]float32{}
+	}
+	r1, g1, b1 := convert.HSLToRGB(c1.H(), c1.S(), c1.L())
+	r2, g2, b2 := convert.HSLToRGB(c2.H(), c2.S(), c2.L())
+	if interpolation == INTERPOLATE_OKLAB {
+		r1, g1, b1 = convert.RGBToOKLab(r1, g1, b1)
+		r2, g2, b2 = convert.RGBToOKLab(r2, g2, b2)
+	}
+	w1, w2 := c1.A()*(1-f)/a, c2.A()*f/a
+	r, g, b := r1*w1+r2*w2, g1*w1+g2*w2, b1*w1+b2*w2
+	if interpolation == INTERPOLATE_OKLAB {
+		r, g, b = convert.OKLabToRGB(r, g, b)
+	}
+	return [4]float32{float32(r), float32(g), float32(b), float32(a)}
+}
+
+// render creates a w x h image where every pixel gets the color at the gradient position fn returns for the center
+// of the pixel. Like the vector primitives it uses continuous coordinates, the center of pixel (x, y) is at (x+0.5, y+0.5).
+func (g *Gradient) render(w, h int, fn func(x, y float64) float64) *Image {
+	stops := g.sorted()
+	interpolation := ParseInterpolation(string(g.Interpolation))
+	lut := make([][4]float32, gradientSteps+1)
+	for k := range lut {
+		lut[k] = g.at(stops, interpolation, float64(k)/gradientSteps)
+	}
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+	_ = executor.Rows(context.Background(), 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				t := fn(float64(x)+0.5, float64(y)+0.5)
+				if math.IsNaN(t) {
+					continue
+				}
+				dst.SetPixel(x, y, lut[int(math.Round(math.Min(1, math.Max(0, t))*gradientSteps))])
+			}
+		}
+	})
+	return &Image{raw: dst, path: "", mu: &sync.Mutex{}}
+}
+
+// Linear creates a w x h image with the gradient running from (x0, y0) to (x1, y1) (in pixels).
+// Lines perpendicular to the gradient have the same color.
+func (g *Gradient) Linear(w, h int, x0, y0, x1, y1 float64) *Image {
+	dx, dy := x1-x0, y1-y0
+	l2 := dx*dx + dy*dy
+	return g.render(w, h, func(x, y float64) float64 {
+		if l2 == 0 {
+			return 0
+		}
+		return ((x-x0)*dx + (y-y0)*dy) / l2
+	})
+}
+
+// Radial creates a w x h image with the gradient running from the center (cx, cy) to the circle with radius r (in pixels).
+func (g *Gradient) Radial(w, h int, cx, cy, r float64) *Image {
+	return g.render(w, h, func(x, y float64) float64 {
+		if r == 0 {
+			return 1
+		}
+		return math.Hypot(x-cx, y-cy) / r
+	})
+}
+
+// Conic creates a w x h image with the gradient running once clockwise around (cx, cy),
+// starting at angle degrees (clockwise from the positive x-axis).
+func (g *Gradient) Conic(w, h int, cx, cy, angle float64) *Image {
+	return g.render(w, h, func(x, y float64) float64 {
+		a := math.Atan2(y-cy, x-cx)*180/math.Pi - angle
+		return math.Mod(math.Mod(a, 360)+360, 360) / 360
+	})
+}
@@ -1,6 +1,7 @@
 package image
 
 import (
+	"context"
 	"image"
 	"strings"
 
@@ -11,8 +12,8 @@ import (
 	"github.com/toxyl/gfx/png"
 )
 
-func loadFromURL(url string) (image.Image, error) {
-	imgData, err := net.Download(url)
+func loadFromURL(ctx context.Context, url string) (image.Image, error) {
+	imgData, err := net.DownloadContext(ctx, url)
 	if err != nil {
 		return nil, err
 	}
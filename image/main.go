@@ -1,122 +1,25 @@
 package image
 
 import (
+	"context"
 	"image"
-	"image/draw"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/toxyl/errors"
 	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/jpg"
 	"github.com/toxyl/gfx/net"
 	"github.com/toxyl/gfx/png"
 )
 
-func toRGBAImage(img image.Image) *image.RGBA {
-	switch t := img.(type) {
-	case *image.RGBA:
-		c := image.NewRGBA(t.Bounds())
-		draw.Draw(c, t.Bounds(), t, image.Point{}, draw.Src)
-		return c
-	case *image.NRGBA:
-		// Convert NRGBA to RGBA
-		c := image.NewRGBA(t.Bounds())
-		draw.Draw(c, t.Bounds(), t, image.Point{}, draw.Src)
-		return c
-	case *image.RGBA64:
-		// Convert RGBA64 to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				r, g, b, a := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(r>>8, g>>8, b>>8, a>>8).RGBA())
-			}
-		}
-		return c
-	case *image.NRGBA64:
-		// Convert NRGBA64 to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				r, g, b, a := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(r>>8, g>>8, b>>8, a>>8).RGBA())
-			}
-		}
-		return c
-	case *image.CMYK:
-		// Convert CMYK to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				r, g, b, a := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(r>>8, g>>8, b>>8, a>>8).RGBA())
-			}
-		}
-		return c
-	case *image.Alpha:
-		// Convert Alpha to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				_, _, _, a := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(0, 0, 0, a>>8).RGBA())
-			}
-		}
-		return c
-	case *image.Alpha16:
-		// Convert Alpha16 to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				_, _, _, a := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(0, 0, 0, a>>8).RGBA())
-			}
-		}
-		return c
-	case *image.Gray:
-		// Convert Gray to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				gray, _, _, _ := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(gray>>8, gray>>8, gray>>8, 255).RGBA())
-			}
-		}
-		return c
-	case *image.Gray16:
-		// Convert Gray16 to RGBA
-		c := image.NewRGBA(t.Bounds())
-		for y := 0; y < t.Bounds().Dy(); y++ {
-			for x := 0; x < t.Bounds().Dx(); x++ {
-				gray, _, _, _ := t.At(x, y).RGBA()
-				c.Set(x, y, rgba.New(gray>>8, gray>>8, gray>>8, 255).RGBA())
-			}
-		}
-		return c
-	case *image.YCbCr:
-		// Convert YCbCr to RGBA
-		c := image.NewRGBA(t.Bounds())
-		draw.Draw(c, t.Bounds(), t, image.Point{}, draw.Src)
-		return c
-	case *image.NYCbCrA:
-		// Convert NYCbCrA to RGBA
-		c := image.NewRGBA(t.Bounds())
-		draw.Draw(c, t.Bounds(), t, image.Point{}, draw.Src)
-		return c
-	case *image.Paletted:
-		// Convert Paletted to RGBA
-		c := image.NewRGBA(t.Bounds())
-		draw.Draw(c, t.Bounds(), t, image.Point{}, draw.Src)
-		return c
-	}
-	return img.(*image.RGBA)
-}
-
 type Image struct {
 	mu   *sync.Mutex
+	ctx  context.Context
 	path string
-	raw  *image.RGBA
+	raw  *Buffer
 }
 
 func (i *Image) Path() string {
@@ -126,16 +29,69 @@ func (i *Image) Path() string {
 		return i.path
 	}
 }
-func (i *Image) SaveAsPNG(path string) *Image { png.Save(i.raw, path); i.path = path; return i }
-func (i *Image) SaveAsJPG(path string) *Image { jpg.Save(i.raw, path); i.path = path; return i }
-func (i *Image) W() int                       { return i.raw.Bounds().Dx() }
-func (i *Image) H() int                       { return i.raw.Bounds().Dy() }
-func (i *Image) CW() int                      { return i.raw.Bounds().Dx() >> 1 }
-func (i *Image) CH() int                      { return i.raw.Bounds().Dy() >> 1 }
-func (i *Image) Lock()                        { i.mu.Lock() }
-func (i *Image) Unlock()                      { i.mu.Unlock() }
+func (i *Image) W() int  { return i.raw.Bounds().Dx() }
+func (i *Image) H() int  { return i.raw.Bounds().Dy() }
+func (i *Image) CW() int { return i.raw.Bounds().Dx() >> 1 }
+func (i *Image) CH() int { return i.raw.Bounds().Dy() >> 1 }
+func (i *Image) Lock()   { i.mu.Lock() }
+func (i *Image) Unlock() { i.mu.Unlock() }
+
+// Context returns the context of the image, it defaults to context.Background.
+func (i *Image) Context() context.Context {
+	i.Lock()
+	defer i.Unlock()
+	if i.ctx == nil {
+		return context.Background()
+	}
+	return i.ctx
+}
 
-func (i *Image) Set(img *image.RGBA) {
+// WithContext returns a copy of the image that shares the pixels of i but uses ctx, i keeps its context.
+// ProcessHSLA and ProcessRGBA of the copy stop processing rows once ctx is cancelled,
+// which leaves the image partially processed. Clones of the copy inherit ctx.
+func (i *Image) WithContext(ctx context.Context) *Image {
+	i.Lock()
+	defer i.Unlock()
+	return &Image{raw: i.raw, path: i.path, ctx: ctx, mu: &sync.Mutex{}}
+}
+
+// Save saves the image to path, the format is derived from the file extension (png, jpg or jpeg).
+func (i *Image) Save(path string) error {
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		err = png.Save(i.raw.NRGBA(), path)
+	case ".jpg", ".jpeg":
+		err = jpg.Save(i.raw.NRGBA(), path)
+	default:
+		err = errors.Newf("unknown format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return &IOError{Op: "save", Path: path, Err: err}
+	}
+	i.path = path
+	return nil
+}
+
+// SaveAsPNG saves the image as PNG. Errors are ignored, use Save if you need to handle them.
+func (i *Image) SaveAsPNG(path string) *Image {
+	if err := png.Save(i.raw.NRGBA(), path); err != nil {
+		return i
+	}
+	i.path = path
+	return i
+}
+
+// SaveAsJPG saves the image as JPG. Errors are ignored, use Save if you need to handle them.
+func (i *Image) SaveAsJPG(path string) *Image {
+	if err := jpg.Save(i.raw.NRGBA(), path); err != nil {
+		return i
+	}
+	i.path = path
+	return i
+}
+
+func (i *Image) Set(img *Buffer) {
 	if img == nil {
 		return
 	}
@@ -144,25 +100,30 @@ func (i *Image) Set(img *image.RGBA) {
 	i.raw = img
 }
 
-func (i *Image) Get() *image.RGBA {
+func (i *Image) Get() *Buffer {
 	i.Lock()
 	defer i.Unlock()
 	return i.raw
 }
 
 func New(w, h int) *Image {
-	i := &Image{raw: image.NewRGBA(image.Rect(0, 0, w, h)), path: "", mu: &sync.Mutex{}}
-	return i.FillRGBA(0, 0, w, h, rgba.New(0, 0, 0, 0))
+	return &Image{raw: NewBuffer(image.Rect(0, 0, w, h)), path: "", mu: &sync.Mutex{}}
 }
 
 func NewWithColor(w, h int, col rgba.RGBA) *Image {
-	i := &Image{raw: image.NewRGBA(image.Rect(0, 0, w, h)), path: "", mu: &sync.Mutex{}}
+	i := &Image{raw: NewBuffer(image.Rect(0, 0, w, h)), path: "", mu: &sync.Mutex{}}
 	return i.FillRGBA(0, 0, w, h, &col)
 }
 
-func NewFromURL(url string) *Image {
+// OpenURL downloads the image at url, failed downloads are retried twice.
+func OpenURL(url string) (*Image, error) {
+	return OpenURLContext(context.Background(), url)
+}
+
+// OpenURLContext is like OpenURL but stops downloading and retrying when ctx is cancelled.
+func OpenURLContext(ctx context.Context, url string) (*Image, error) {
 	if !net.IsURL(url) {
-		return nil
+		return nil, &IOError{Op: "load", Path: url, Err: errors.Newf("not a URL")}
 	}
 
 	const maxAttempts = 3
@@ -172,23 +133,58 @@ func NewFromURL(url string) *Image {
 	var err error
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		img, err = loadFromURL(url)
+		img, err = loadFromURL(ctx, url)
 		if err == nil {
-			return &Image{raw: toRGBAImage(img), path: url, mu: &sync.Mutex{}}
+			return &Image{raw: toBuffer(img), path: url, mu: &sync.Mutex{}}, nil
 		}
 
 		if attempt < maxAttempts-1 {
-			time.Sleep(delay)
+			select {
+			case <-ctx.Done():
+				return nil, &IOError{Op: "load", Path: url, Err: ctx.Err()}
+			case <-time.After(delay):
+			}
 			delay *= 2
 		}
 	}
 
+	return nil, &IOError{Op: "load", Path: url, Err: err}
+}
+
+// OpenFile loads the image stored at path.
+func OpenFile(path string) (*Image, error) {
+	i, err := loadFromFile(path)
+	if err != nil {
+		return nil, &IOError{Op: "load", Path: path, Err: err}
+	}
+	return &Image{raw: toBuffer(i), path: path, mu: &sync.Mutex{}}, nil
+}
+
+// Open loads the image from src which can either be a URL or a file path.
+func Open(src string) (*Image, error) {
+	return OpenContext(context.Background(), src)
+}
+
+// OpenContext is like Open but URLs are downloaded with OpenURLContext.
+func OpenContext(ctx context.Context, src string) (*Image, error) {
+	if net.IsURL(src) {
+		return OpenURLContext(ctx, src)
+	}
+	return OpenFile(src)
+}
+
+// NewFromURL is like OpenURL but returns nil if the image can't be loaded.
+func NewFromURL(url string) *Image {
+	if i, err := OpenURL(url); err == nil {
+		return i
+	}
 	return nil
 }
 
+// NewFromFile is like OpenFile but returns nil if the image can't be loaded.
 func NewFromFile(path string) *Image {
-	if i, err := loadFromFile(path); err == nil {
-		return &Image{raw: toRGBAImage(i), path: path, mu: &sync.Mutex{}}
+	if i, err := OpenFile(path); err == nil {
+		return i
 	}
 	return nil
 }
@@ -196,11 +192,11 @@ func NewFromFile(path string) *Image {
 // NewFromBytes generates an image from byte data using the given type. Available types: png, jpg and jpeg
 func NewFromBytes(typ string, b []byte) *Image {
 	if i, err := loadFromBytes(typ, b); err == nil {
-		return &Image{raw: toRGBAImage(i), path: "", mu: &sync.Mutex{}}
+		return &Image{raw: toBuffer(i), path: "", mu: &sync.Mutex{}}
 	}
 	return nil
 }
 
 func NewFromImage(img image.Image) *Image {
-	return &Image{raw: toRGBAImage(img), path: "", mu: &sync.Mutex{}}
+	return &Image{raw: toBuffer(img), path: "", mu: &sync.Mutex{}}
 }
@@ -1,32 +1,26 @@
 package image
 
 import (
-	"runtime"
-	"sync"
-
 	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/image/executor"
 )
 
+// mergeHSLA is like ProcessHSLA but fn receives the pixels of src instead of those of the image.
 func (i *Image) mergeHSLA(src *Image, startX, startY, endX, endY int, fn func(x, y int, col *hsla.HSLA) (x2, y2 int, col2 *hsla.HSLA)) *Image {
-	numCores := runtime.NumCPU()
-	sem := make(chan struct{}, numCores)
-	var wg sync.WaitGroup
-	dst := i.Clone()
-
-	for y := startY; y < endY; y++ {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(row int) {
-			defer func() {
-				<-sem
-				wg.Done()
-			}()
+	raw := i.Get()
+	srcRaw := src.Get()
+	dst := raw.scratch()
+	defer releaseScratch(dst)
+	_ = executor.Rows(i.Context(), startY, endY, func(y0, y1 int) {
+		col := &hsla.HSLA{}
+		for y := y0; y < y1; y++ {
 			for x := startX; x < endX; x++ {
-				dst.SetHSLA(fn(x, row, src.GetHSLA(x, row)))
+				pixelToHSLA(srcRaw.PixelAt(x, y), col)
+				x2, y2, col2 := fn(x, y, col)
+				dst.SetPixel(x2, y2, hslaToPixel(col2))
 			}
-		}(y)
-	}
-	wg.Wait()
-	i.Set(dst.raw)
+		}
+	})
+	copy(raw.Pix, dst.Pix)
 	return i
 }
@@ -0,0 +1,178 @@
+package image
+
+import (
+	"context"
+	"image"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/toxyl/gfx/image/executor"
+	gmath "github.com/toxyl/gfx/math"
+)
+
+// NoiseType defines the algorithm used to generate noise.
+type NoiseType string
+
+const (
+	NOISE_VALUE   NoiseType = "value"   // smoothly interpolated random values on a grid, blocky
+	NOISE_PERLIN  NoiseType = "perlin"  // gradient noise on a square grid
+	NOISE_SIMPLEX NoiseType = "simplex" // gradient noise on a triangular grid, fewer directional artifacts than perlin
+	NOISE_WORLEY  NoiseType = "worley"  // distance to the nearest of randomly placed points, looks like cells
+	NOISE_WHITE   NoiseType = "white"   // independent random values
+)
+
+// NoiseTypes returns the names of all noise types.
+func NoiseTypes() []string {
+	return []string{string(NOISE_VALUE), string(NOISE_PERLIN), string(NOISE_SIMPLEX), string(NOISE_WORLEY), string(NOISE_WHITE)}
+}
+
+// ParseNoiseType returns the noise type with the given name (case-insensitive), NOISE_VALUE if it is unknown.
+func ParseNoiseType(name string) NoiseType {
+	switch t := NoiseType(strings.ToLower(strings.TrimSpace(name))); t {
+	case NOISE_PERLIN, NOISE_SIMPLEX, NOISE_WORLEY, NOISE_WHITE:
+		return t
+	}
+	return NOISE_VALUE
+}
+
+// NewNoise creates an opaque w x h grayscale image filled with noise in the range [0, 1].
+// scale is the frequency of the first octave in features per pixel, e.g. 0.01 gives features of about 100 pixels
+// (white noise with scale 1 has a random value per pixel). Each of the octaves (at least 1) doubles the frequency
+// of the previous one and multiplies its amplitude by persistence, which adds finer detail.
+// The same seed always produces the same image.
+func NewNoise(w, h int, typ NoiseType, scale float64, octaves int, persistence float64, seed int64) *Image {
+	fn := noiseFunc(typ)
+	octaves = max(1, octaves)
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+	_ = executor.Rows(context.Background(), 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				sum, amp, total, freq := 0.0, 1.0, 0.0, scale
+				for o := range octaves {
+					sum += amp * fn((float64(x)+0.5)*freq, (float64(y)+0.5)*freq, uint64(seed)+uint64(o))
+					total += amp
+					amp *= persistence
+					freq *= 2
+				}
+				v := float32(0.5)
+				if total != 0 {
+					v = float32(math.Min(1, math.Max(0, sum/total*0.5+0.5)))
+				}
+				dst.SetPixel(x, y, [4]float32{v, v, v, 1})
+			}
+		}
+	})
+	return &Image{raw: dst, path: "", mu: &sync.Mutex{}}
+}
+
+// Noise returns the noise of the given type at (x, y) in the range [0, 1], features are about 1 unit apart.
+// It is a single octave of the noise NewNoise generates with a scale of 1.
+func Noise(typ NoiseType, x, y float64, seed int64) float64 {
+	return math.Min(1, math.Max(0, noiseFunc(typ)(x, y, uint64(seed))*0.5+0.5))
+}
+
+// noiseFunc returns the function that generates noise of the given type, its values are in the range [-1, 1].
+func noiseFunc(typ NoiseType) func(x, y float64, seed uint64) float64 {
+	switch ParseNoiseType(string(typ)) {
+	case NOISE_PERLIN:
+		return perlin
+	case NOISE_SIMPLEX:
+		return simplex
+	case NOISE_WORLEY:
+		return worley
+	case NOISE_WHITE:
+		return func(x, y float64, seed uint64) float64 {
+			return gmath.HashFloat(seed, int64(math.Floor(x)), int64(math.Floor(y)))*2 - 1
+		}
+	}
+	return valueNoise
+}
+
+// fade is the quintic interpolation curve used by Perlin noise, its first and second derivatives are 0 at 0 and 1.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp interpolates linearly between a and b.
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// gradients are the directions used by gradient noise.
+var gradients = [8][2]float64{
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+	{math.Sqrt2 / 2, math.Sqrt2 / 2}, {-math.Sqrt2 / 2, math.Sqrt2 / 2}, {math.Sqrt2 / 2, -math.Sqrt2 / 2}, {-math.Sqrt2 / 2, -math.Sqrt2 / 2},
+}
+
+func valueNoise(x, y float64, seed uint64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int64(x0), int64(y0)
+	u, v := fade(x-x0), fade(y-y0)
+	return lerp(
+		lerp(gmath.HashFloat(seed, ix, iy), gmath.HashFloat(seed, ix+1, iy), u),
+		lerp(gmath.HashFloat(seed, ix, iy+1), gmath.HashFloat(seed, ix+1, iy+1), u),
+		v,
+	)*2 - 1
+}
+
+func perlin(x, y float64, seed uint64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int64(x0), int64(y0)
+	fx, fy := x-x0, y-y0
+	dot := func(cx, cy int64, dx, dy float64) float64 {
+		g := gradients[gmath.Hash(seed, cx, cy)&7]
+		return g[0]*dx + g[1]*dy
+	}
+	u, v := fade(fx), fade(fy)
+	n := lerp(
+		lerp(dot(ix, iy, fx, fy), dot(ix+1, iy, fx-1, fy), u),
+		lerp(dot(ix, iy+1, fx, fy-1), dot(ix+1, iy+1, fx-1, fy-1), u),
+		v,
+	)
+	return n * math.Sqrt2 // unit gradients give values in [-sqrt(0.5), sqrt(0.5)]
+}
+
+func simplex(x, y float64, seed uint64) float64 {
+	const (
+		f2 = 0.36602540378443865 // (sqrt(3) - 1) / 2
+		g2 = 0.21132486540518713 // (3 - sqrt(3)) / 6
+	)
+	s := (x + y) * f2
+	i, j := math.Floor(x+s), math.Floor(y+s)
+	t := (i + j) * g2
+	x0, y0 := x-(i-t), y-(j-t)
+	var i1, j1 float64 // second corner of the triangle, relative to the first one
+	if x0 > y0 {
+		i1 = 1
+	} else {
+		j1 = 1
+	}
+	corner := func(ci, cj, dx, dy float64) float64 {
+		t := 0.5 - dx*dx - dy*dy
+		if t < 0 {
+			return 0
+		}
+		g := gradients[gmath.Hash(seed, int64(ci), int64(cj))&7]
+		t *= t
+		return t * t * (g[0]*dx + g[1]*dy)
+	}
+	n := corner(i, j, x0, y0) +
+		corner(i+i1, j+j1, x0-i1+g2, y0-j1+g2) +
+		corner(i+1, j+1, x0-1+2*g2, y0-1+2*g2)
+	return 99.2 * n // scales the largest possible value to about 1
+}
+
+func worley(x, y float64, seed uint64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int64(x0), int64(y0)
+	d := math.Inf(1)
+	for cy := iy - 1; cy <= iy+1; cy++ {
+		for cx := ix - 1; cx <= ix+1; cx++ {
+			px := float64(cx) + gmath.HashFloat(seed, cx, cy)
+			py := float64(cy) + gmath.HashFloat(seed^0xA5A5A5A5A5A5A5A5, cx, cy)
+			d = math.Min(d, math.Hypot(px-x, py-y))
+		}
+	}
+	return math.Min(1, d)*2 - 1
+}
@@ -0,0 +1,270 @@
+package image
+
+import (
+	"math"
+)
+
+// flatness is the largest distance (in pixels) between a curve and the line segments it is approximated with.
+const flatness = 0.1
+
+// point is a position in drawing coordinates.
+type point struct{ x, y float64 }
+
+// Path describes the outline of a shape made of straight lines, Bézier curves and elliptical arcs.
+// Drawing coordinates are continuous: the pixel (x, y) covers the square from (x, y) to (x+1, y+1),
+// so the center of the top left pixel is at (0.5, 0.5). Methods return the path to allow chaining.
+type Path struct {
+	subpaths [][]point
+	closed   []bool
+}
+
+// NewPath returns an empty path.
+func NewPath() *Path {
+	return &Path{}
+}
+
+// current returns the last point of the path, (0, 0) if the path is empty.
+func (p *Path) current() point {
+	if n := len(p.subpaths); n > 0 {
+		sp := p.subpaths[n-1]
+		return sp[len(sp)-1]
+	}
+	return point{}
+}
+
+// add appends pts to the current subpath, starting a new one if the path is empty or the current one is closed.
+func (p *Path) add(pts ...point) {
+	n := len(p.subpaths)
+	if n == 0 || p.closed[n-1] {
+		p.subpaths = append(p.subpaths, []point{p.current()})
+		p.closed = append(p.closed, false)
+		n++
+	}
+	p.subpaths[n-1] = append(p.subpaths[n-1], pts...)
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x, y float64) *Path {
+	p.subpaths = append(p.subpaths, []point{{x, y}})
+	p.closed = append(p.closed, false)
+	return p
+}
+
+// LineTo adds a straight line from the current point to (x, y).
+func (p *Path) LineTo(x, y float64) *Path {
+	p.add(point{x, y})
+	return p
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to (x, y) with the control point (cx, cy).
+func (p *Path) QuadTo(cx, cy, x, y float64) *Path {
+	p0, p1, p2 := p.current(), point{cx, cy}, point{x, y}
+	// Wang's formula gives the number of segments needed to stay within flatness.
+	dd := math.Hypot(p0.x-2*p1.x+p2.x, p0.y-2*p1.y+p2.y)
+	n := max(1, int(math.Ceil(math.Sqrt(dd/(4*flatness)))))
+	for k := 1; k <= n; k++ {
+		t := float64(k) / float64(n)
+		u := 1 - t
+		p.add(point{u*u*p0.x + 2*u*t*p1.x + t*t*p2.x, u*u*p0.y + 2*u*t*p1.y + t*t*p2.y})
+	}
+	return p
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to (x, y) with the control points (c1x, c1y) and (c2x, c2y).
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float64) *Path {
+	p0, p1, p2, p3 := p.current(), point{c1x, c1y}, point{c2x, c2y}, point{x, y}
+	dd := math.Max(
+		math.Hypot(p0.x-2*p1.x+p2.x, p0.y-2*p1.y+p2.y),
+		math.Hypot(p1.x-2*p2.x+p3.x, p1.y-2*p2.y+p3.y),
+	)
+	n := max(1, int(math.Ceil(math.Sqrt(3*dd/(4*flatness)))))
+	for k := 1; k <= n; k++ {
+		t := float64(k) / float64(n)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		p.add(point{a*p0.x + b*p1.x + c*p2.x + d*p3.x, a*p0.y + b*p1.y + c*p2.y + d*p3.y})
+	}
+	return p
+}
+
+// Arc adds an elliptical arc around (cx, cy) with the radii rx and ry from angleStart to angleEnd (in degrees,
+// clockwise from the positive x-axis). If the path has a current point, a line connects it to the start of the arc.
+// Use MoveTo before Arc to start a separate shape.
+func (p *Path) Arc(cx, cy, rx, ry, angleStart, angleEnd float64) *Path {
+	a0 := angleStart * math.Pi / 180.0
+	a1 := angleEnd * math.Pi / 180.0
+	r := math.Max(math.Abs(rx), math.Abs(ry))
+	step := math.Pi / 4
+	if r > flatness {
+		step = 2 * math.Acos(1-flatness/r)
+	}
+	n := max(1, int(math.Ceil(math.Abs(a1-a0)/step)))
+	start := point{cx + rx*math.Cos(a0), cy + ry*math.Sin(a0)}
+	if len(p.subpaths) == 0 || p.closed[len(p.closed)-1] {
+		p.MoveTo(start.x, start.y)
+	} else {
+		p.add(start)
+	}
+	for k := 1; k <= n; k++ {
+		a := a0 + (a1-a0)*float64(k)/float64(n)
+		p.add(point{cx + rx*math.Cos(a), cy + ry*math.Sin(a)})
+	}
+	return p
+}
+
+// Ellipse adds a closed ellipse around (cx, cy) with the radii rx and ry as a new subpath.
+func (p *Path) Ellipse(cx, cy, rx, ry float64) *Path {
+	p.MoveTo(cx+rx, cy)
+	return p.Arc(cx, cy, rx, ry, 0, 360).Close()
+}
+
+// Polygon adds a closed polygon through the points as a new subpath.
+func (p *Path) Polygon(points ...[2]float64) *Path {
+	for k, pt := range points {
+		if k == 0 {
+			p.MoveTo(pt[0], pt[1])
+			continue
+		}
+		p.LineTo(pt[0], pt[1])
+	}
+	if len(points) > 0 {
+		p.Close()
+	}
+	return p
+}
+
+// Close closes the current subpath with a straight line back to its first point.
+func (p *Path) Close() *Path {
+	if n := len(p.closed); n > 0 {
+		p.closed[n-1] = true
+	}
+	return p
+}
+
+// LineCap defines the shape of the ends of open subpaths when they are stroked.
+type LineCap string
+
+const (
+	CAP_BUTT   LineCap = "butt"   // the stroke ends exactly at the end point
+	CAP_ROUND  LineCap = "round"  // the stroke ends with a half circle around the end point
+	CAP_SQUARE LineCap = "square" // the stroke extends beyond the end point by half its width
+)
+
+// FillRule defines which parts of a path are inside.
+type FillRule string
+
+const (
+	FILL_NONZERO FillRule = "nonzero" // points the outline winds around at least once are inside
+	FILL_EVENODD FillRule = "evenodd" // points the outline winds around an odd number of times are inside
+)
+
+// polygon is a closed outline used for rasterization.
+type polygon []point
+
+// area returns the signed area of the polygon, it is positive for clockwise polygons (y-axis down).
+func (pg polygon) area() float64 {
+	a := 0.0
+	for k := range pg {
+		p0, p1 := pg[k], pg[(k+1)%len(pg)]
+		a += p0.x*p1.y - p1.x*p0.y
+	}
+	return a / 2
+}
+
+// clockwise returns the polygon with its points in clockwise order.
+func (pg polygon) clockwise() polygon {
+	if pg.area() < 0 {
+		for a, b := 0, len(pg)-1; a < b; a, b = a+1, b-1 {
+			pg[a], pg[b] = pg[b], pg[a]
+		}
+	}
+	return pg
+}
+
+// fill returns the subpaths as polygons, open subpaths are closed implicitly.
+func (p *Path) fill() []polygon {
+	res := make([]polygon, 0, len(p.subpaths))
+	for _, sp := range p.subpaths {
+		if len(sp) > 2 {
+			res = append(res, polygon(sp))
+		}
+	}
+	return res
+}
+
+// circle returns a clockwise polygon approximating a circle around c.
+func circle(c point, r float64) polygon {
+	n := 8
+	if r > flatness {
+		n = max(8, int(math.Ceil(2*math.Pi/(2*math.Acos(1-flatness/r)))))
+	}
+	pg := make(polygon, n)
+	for k := range pg {
+		sin, cos := math.Sincos(2 * math.Pi * float64(k) / float64(n))
+		pg[k] = point{c.x + r*cos, c.y + r*sin}
+	}
+	return pg
+}
+
+// stroke returns clockwise polygons that together cover the outline of the path drawn with the given width.
+// Rasterized with FILL_NONZERO their union is the stroke. Joins are round.
+func (p *Path) stroke(width float64, lineCap LineCap) []polygon {
+	hw := width / 2
+	res := []polygon{}
+	for k, sp := range p.subpaths {
+		closed := p.closed[k]
+		pts := make([]point, 0, len(sp)+1)
+		for _, pt := range sp {
+			if n := len(pts); n == 0 || pts[n-1] != pt {
+				pts = append(pts, pt)
+			}
+		}
+		if closed && len(pts) > 1 && pts[0] != pts[len(pts)-1] {
+			pts = append(pts, pts[0])
+		}
+		if len(pts) == 1 {
+			// a single point is only visible with round or square caps
+			switch lineCap {
+			case CAP_ROUND:
+				res = append(res, circle(pts[0], hw))
+			case CAP_SQUARE:
+				c := pts[0]
+				res = append(res, polygon{{c.x - hw, c.y - hw}, {c.x + hw, c.y - hw}, {c.x + hw, c.y + hw}, {c.x - hw, c.y + hw}})
+			}
+			continue
+		}
+		last := len(pts) - 2
+		for s := 0; s <= last; s++ {
+			a, b := pts[s], pts[s+1]
+			dx, dy := b.x-a.x, b.y-a.y
+			l := math.Hypot(dx, dy)
+			ux, uy := dx/l, dy/l
+			if !closed && lineCap == CAP_SQUARE {
+				if s == 0 {
+					a = point{a.x - ux*hw, a.y - uy*hw}
+				}
+				if s == last {
+					b = point{b.x + ux*hw, b.y + uy*hw}
+				}
+			}
+			nx, ny := -uy*hw, ux*hw
+			res = append(res, polygon{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}}.clockwise())
+
+			// round join with the next segment, skipped if the gap it fills is negligible
+			if s < last || closed {
+				next := pts[1]
+				if s < last {
+					next = pts[s+2]
+				}
+				turn := math.Abs(math.Atan2(ux*(next.y-b.y)-uy*(next.x-b.x), ux*(next.x-b.x)+uy*(next.y-b.y)))
+				if turn*hw > flatness {
+					res = append(res, circle(pts[s+1], hw))
+				}
+			}
+		}
+		if !closed && lineCap == CAP_ROUND {
+			res = append(res, circle(pts[0], hw), circle(pts[len(pts)-1], hw))
+		}
+	}
+	return res
+}
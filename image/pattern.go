@@ -0,0 +1,82 @@
+package image
+
+import (
+	"context"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/image/executor"
+)
+
+// newPattern creates a w x h image where every pixel mixes c1 and c2 according to the coverage of c2
+// that fn returns for the center of the pixel (at (x+0.5, y+0.5), like the vector primitives).
+func newPattern(w, h int, c1, c2 *hsla.HSLA, fn func(x, y float64) float64) *Image {
+	dst := NewBuffer(image.Rect(0, 0, w, h))
+	_ = executor.Rows(context.Background(), 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := range w {
+				dst.SetPixel(x, y, interpolate(c1, c2, fn(float64(x)+0.5, float64(y)+0.5), INTERPOLATE_RGB))
+			}
+		}
+	})
+	return &Image{raw: dst, path: "", mu: &sync.Mutex{}}
+}
+
+// band returns how much of the pixel centered at v lies within the second half of the bands of the given size,
+// which alternate between first and second half. Edges are anti-aliased over one pixel.
+func band(v, size float64) float64 {
+	u := math.Mod(v, 2*size)
+	if u < 0 {
+		u += 2 * size
+	}
+	// signed distance to the nearest edge, positive inside the second half
+	d := math.Min(u-size, 2*size-u)
+	if u < size {
+		d = -math.Min(u, size-u)
+	}
+	return math.Min(1, math.Max(0, d+0.5))
+}
+
+// NewCheckerboard creates a w x h image with a checkerboard of squares with the given size (in pixels),
+// the top left square has the color c1.
+func NewCheckerboard(w, h int, size float64, c1, c2 *hsla.HSLA) *Image {
+	return newPattern(w, h, c1, c2, func(x, y float64) float64 {
+		if size <= 0 {
+			return 0
+		}
+		bx, by := band(x, size), band(y, size)
+		return bx*(1-by) + by*(1-bx)
+	})
+}
+
+// NewStripes creates a w x h image with stripes of the given width (in pixels) that alternate between c1 and c2.
+// An angle of 0 gives vertical stripes, positive angles rotate them clockwise (in degrees).
+func NewStripes(w, h int, width, angle float64, c1, c2 *hsla.HSLA) *Image {
+	sin, cos := math.Sincos(angle * math.Pi / 180.0)
+	return newPattern(w, h, c1, c2, func(x, y float64) float64 {
+		if width <= 0 {
+			return 0
+		}
+		return band(x*cos+y*sin, width)
+	})
+}
+
+// NewGrid creates a w x h image filled with background and a grid of lines with the given color and thickness,
+// spaced size pixels apart. The first lines run along the top and left edges of the image.
+func NewGrid(w, h int, size, thickness float64, line, background *hsla.HSLA) *Image {
+	// coverage returns how much of the pixel centered at v is covered by the nearest line
+	coverage := func(v float64) float64 {
+		v -= thickness / 2
+		d := math.Abs(v - math.Round(v/size)*size)
+		return math.Max(0, math.Min(d+0.5, thickness/2)-math.Max(d-0.5, -thickness/2))
+	}
+	return newPattern(w, h, background, line, func(x, y float64) float64 {
+		if size <= 0 || thickness <= 0 {
+			return 0
+		}
+		cx, cy := coverage(x), coverage(y)
+		return 1 - (1-cx)*(1-cy)
+	})
+}
@@ -0,0 +1,132 @@
+package image
+
+import (
+	"math"
+
+	"github.com/toxyl/gfx/image/resample"
+)
+
+// Homography is a 3x3 projective transformation matrix in row-major order:
+//
+//	| A B C |
+//	| D E F |
+//	| G H I |
+//
+// It maps the point (x, y) to ((A*x + B*y + C) / w, (D*x + E*y + F) / w) with w = G*x + H*y + I.
+// Coordinates follow the same conventions as Affine.
+type Homography [9]float64
+
+// Homography returns m as a homography.
+func (m Affine) Homography() Homography {
+	return Homography{m[0], m[1], m[2], m[3], m[4], m[5], 0, 0, 1}
+}
+
+// QuadToQuad returns the homography that maps the four points of from to the four points of to,
+// e.g. the corners of a photographed screen to the corners of the image. It returns false if three of
+// the points of either quadrilateral lie on a line.
+func QuadToQuad(from, to [4][2]float64) (Homography, bool) {
+	// Each point pair gives two equations for the eight unknowns A..H (I is 1):
+	//   A*u + B*v + C - G*u*x - H*v*x = x
+	//   D*u + E*v + F - G*u*y - H*v*y = y
+	var a [8][9]float64
+	for k := range 4 {
+		u, v := from[k][0], from[k][1]
+		x, y := to[k][0], to[k][1]
+		a[2*k] = [9]float64{u, v, 1, 0, 0, 0, -u * x, -v * x, x}
+		a[2*k+1] = [9]float64{0, 0, 0, u, v, 1, -u * y, -v * y, y}
+	}
+	// Gaussian elimination with partial pivoting.
+	for c := range 8 {
+		p := c
+		for r := c + 1; r < 8; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[p][c]) {
+				p = r
+			}
+		}
+		if math.Abs(a[p][c]) < 1e-12 {
+			return Homography{1, 0, 0, 0, 1, 0, 0, 0, 1}, false
+		}
+		a[c], a[p] = a[p], a[c]
+		for r := range 8 {
+			if r == c || a[r][c] == 0 {
+				continue
+			}
+			f := a[r][c] / a[c][c]
+			for k := c; k < 9; k++ {
+				a[r][k] -= f * a[c][k]
+			}
+		}
+	}
+	var h Homography
+	for k := range 8 {
+		h[k] = a[k][8] / a[k][k]
+	}
+	h[8] = 1
+	// Scale by -1 if necessary, so that w is positive for the quadrilateral.
+	if h[6]*from[0][0]+h[7]*from[0][1]+h[8] < 0 {
+		for k := range h {
+			h[k] = -h[k]
+		}
+	}
+	return h, true
+}
+
+// Mul returns the product h * n, i.e. a homography that applies n first and h second.
+func (h Homography) Mul(n Homography) Homography {
+	var res Homography
+	for r := range 3 {
+		for c := range 3 {
+			res[r*3+c] = h[r*3]*n[c] + h[r*3+1]*n[3+c] + h[r*3+2]*n[6+c]
+		}
+	}
+	return res
+}
+
+// Invert returns the inverse of h and true, or the identity and false if h is not invertible.
+func (h Homography) Invert() (Homography, bool) {
+	a, b, c, d, e, f, g, k, l := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7], h[8]
+	det := a*(e*l-f*k) - b*(d*l-f*g) + c*(d*k-e*g)
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Homography{1, 0, 0, 0, 1, 0, 0, 0, 1}, false
+	}
+	return Homography{
+		(e*l - f*k) / det, (c*k - b*l) / det, (b*f - c*e) / det,
+		(f*g - d*l) / det, (a*l - c*g) / det, (c*d - a*f) / det,
+		(d*k - e*g) / det, (b*g - a*k) / det, (a*e - b*d) / det,
+	}, true
+}
+
+// Apply maps the point (x, y) through h. It returns false if the point is mapped to infinity
+// or lies behind the horizon of the projection (w <= 0).
+func (h Homography) Apply(x, y float64) (float64, float64, bool) {
+	w := h[6]*x + h[7]*y + h[8]
+	if w <= 0 {
+		return x, y, false
+	}
+	return (h[0]*x + h[1]*y + h[2]) / w, (h[3]*x + h[4]*y + h[5]) / w, true
+}
+
+// WarpPerspective is like Warp but transforms the image with the homography h.
+// Destination pixels that don't map to a point in front of the projection stay transparent,
+// "in front" means that h maps the source point with a positive w.
+func (i *Image) WarpPerspective(h Homography, kernel resample.Kernel, edge resample.Edge) *Image {
+	inv, ok := h.Invert()
+	return i.Remap(func(x, y float64) (float64, float64, bool) {
+		if !ok {
+			return x, y, false
+		}
+		return inv.Apply(x, y)
+	}, kernel, edge)
+}
+
+// Perspective maps the quadrilateral from onto the quadrilateral to (both in pixel coordinates and in the same
+// order, e.g. top-left, top-right, bottom-right, bottom-left) and warps the image accordingly. Mapping the
+// corners of a photographed screen or print to the corners of the image corrects keystone distortion.
+// If either quadrilateral is degenerate the result is transparent.
+func (i *Image) Perspective(from, to [4][2]float64, kernel resample.Kernel, edge resample.Edge) *Image {
+	h, ok := QuadToQuad(from, to)
+	if !ok {
+		h = Homography{}
+	}
+	return i.WarpPerspective(h, kernel, edge)
+}
@@ -0,0 +1,68 @@
+package image
+
+import (
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/math"
+)
+
+// ProcessPixels calls fn for every pixel of the image, bands of rows are processed concurrently.
+// px holds the non-premultiplied R, G, B and A values (range [0, 1]) of the pixel at (x, y) and is
+// modified in place, values are clamped once fn returns. Unlike ProcessRGBA nothing is allocated per
+// pixel, so fn must only change the pixel it is called for and must not keep px.
+// Processing stops early if the context of the image is cancelled (see WithContext).
+func (i *Image) ProcessPixels(fn func(x, y int, px []float32)) *Image {
+	buf := i.Get()
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	_ = executor.Rows(i.Context(), 0, h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			off := buf.PixOffset(buf.Rect.Min.X, buf.Rect.Min.Y+y)
+			row := buf.Pix[off : off+w*4]
+			for x := 0; x < w; x++ {
+				px := row[x*4 : x*4+4 : x*4+4]
+				fn(x, y, px)
+				normalize(px)
+			}
+		}
+	})
+	return i
+}
+
+// ProcessPixelsHSLA is like ProcessPixels but fn receives the pixel as hue (in degrees [0, 360)),
+// saturation, lightness and alpha (range [0, 1]). The hue is wrapped and all other values are
+// clamped once fn returns.
+func (i *Image) ProcessPixelsHSLA(fn func(x, y int, hsla []float64)) *Image {
+	buf := i.Get()
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	_ = executor.Rows(i.Context(), 0, h, func(y0, y1 int) {
+		hsla := make([]float64, 4)
+		for y := y0; y < y1; y++ {
+			off := buf.PixOffset(buf.Rect.Min.X, buf.Rect.Min.Y+y)
+			row := buf.Pix[off : off+w*4]
+			for x := 0; x < w; x++ {
+				px := row[x*4 : x*4+4 : x*4+4]
+				hsla[0], hsla[1], hsla[2] = convert.RGBToHSL(float64(px[0]), float64(px[1]), float64(px[2]))
+				hsla[3] = float64(px[3])
+				fn(x, y, hsla)
+				r, g, b := convert.HSLToRGB(
+					math.Wrap(hsla[0], 0.0, 360.0),
+					math.Clamp(hsla[1], 0.0, 1.0),
+					math.Clamp(hsla[2], 0.0, 1.0),
+				)
+				px[0], px[1], px[2], px[3] = float32(r), float32(g), float32(b), float32(hsla[3])
+				normalize(px)
+			}
+		}
+	})
+	return i
+}
+
+// normalize clamps the values of px to [0, 1] and turns fully transparent pixels into transparent black.
+func normalize(px []float32) {
+	a := clamp01(px[3])
+	if a == 0 {
+		px[0], px[1], px[2], px[3] = 0, 0, 0, 0
+		return
+	}
+	px[0], px[1], px[2], px[3] = clamp01(px[0]), clamp01(px[1]), clamp01(px[2]), a
+}
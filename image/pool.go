@@ -0,0 +1,22 @@
+package image
+
+import "sync"
+
+var scratchPool sync.Pool
+
+// scratch returns a pooled copy of b, return it with releaseScratch once it is no longer needed.
+func (b *Buffer) scratch() *Buffer {
+	s, _ := scratchPool.Get().(*Buffer)
+	if s == nil || cap(s.Pix) < len(b.Pix) {
+		s = &Buffer{Pix: make([]float32, len(b.Pix))}
+	}
+	s.Pix = s.Pix[:len(b.Pix)]
+	s.Stride = b.Stride
+	s.Rect = b.Rect
+	copy(s.Pix, b.Pix)
+	return s
+}
+
+func releaseScratch(b *Buffer) {
+	scratchPool.Put(b)
+}
@@ -1,56 +1,51 @@
 package image
 
 import (
-	"runtime"
-	"sync"
-
 	"github.com/toxyl/gfx/color/hsla"
 	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/image/executor"
 )
 
+// ProcessHSLA processes HSLA pixels in bands of rows using the shared executor.
+// fn always sees the pixels as they were before processing started and the color it receives
+// is reused for the next pixel, so fn must not keep it.
+// Processing stops early if the context of the image is cancelled (see WithContext).
 func (i *Image) ProcessHSLA(startX, startY, endX, endY int, fn func(x, y int, col *hsla.HSLA) (x2, y2 int, col2 *hsla.HSLA)) *Image {
-	numCores := runtime.NumCPU()
-	sem := make(chan struct{}, numCores) // Limit goroutines to the number of cores.
-	var wg sync.WaitGroup
-	dst := i.Clone()
-	for y := startY; y < endY; y++ {
-		wg.Add(1)
-		sem <- struct{}{} // Acquire a slot.
-		go func(row int) {
-			defer func() {
-				<-sem // Release the slot.
-				wg.Done()
-			}()
+	src := i.Get()
+	dst := src.scratch()
+	defer releaseScratch(dst)
+	_ = executor.Rows(i.Context(), startY, endY, func(y0, y1 int) {
+		col := &hsla.HSLA{}
+		for y := y0; y < y1; y++ {
 			for x := startX; x < endX; x++ {
-				dst.SetHSLA(fn(x, row, dst.GetHSLA(x, row)))
+				pixelToHSLA(src.PixelAt(x, y), col)
+				x2, y2, col2 := fn(x, y, col)
+				dst.SetPixel(x2, y2, hslaToPixel(col2))
 			}
-		}(y)
-	}
-	wg.Wait()
-	i.Set(dst.raw)
+		}
+	})
+	copy(src.Pix, dst.Pix)
 	return i
 }
 
-// ProcessRGBA processes RGBA pixels using goroutines per row.
+// ProcessRGBA processes RGBA pixels in bands of rows using the shared executor.
+// fn always sees the pixels as they were before processing started and the color it receives
+// is reused for the next pixel, so fn must not keep it.
+// Processing stops early if the context of the image is cancelled (see WithContext).
 func (i *Image) ProcessRGBA(startX, startY, endX, endY int, fn func(x, y int, col *rgba.RGBA) (x2, y2 int, col2 *rgba.RGBA)) *Image {
-	numCores := runtime.NumCPU()
-	sem := make(chan struct{}, numCores)
-	var wg sync.WaitGroup
-	dst := i.Clone()
-	for y := startY; y < endY; y++ {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(row int) {
-			defer func() {
-				<-sem
-				wg.Done()
-			}()
+	src := i.Get()
+	dst := src.scratch()
+	defer releaseScratch(dst)
+	_ = executor.Rows(i.Context(), startY, endY, func(y0, y1 int) {
+		col := &rgba.RGBA{}
+		for y := y0; y < y1; y++ {
 			for x := startX; x < endX; x++ {
-				dst.SetRGBA(fn(x, row, dst.GetRGBA(x, row)))
+				pixelToRGBA(src.PixelAt(x, y), col)
+				x2, y2, col2 := fn(x, y, col)
+				dst.SetPixel(x2, y2, rgbaToPixel(col2))
 			}
-		}(y)
-	}
-	wg.Wait()
-	i.Set(dst.raw)
+		}
+	})
+	copy(src.Pix, dst.Pix)
 	return i
 }
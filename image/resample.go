@@ -0,0 +1,155 @@
+package image
+
+import (
+	"image"
+	"math"
+
+	"github.com/toxyl/gfx/image/resample"
+)
+
+// Sample returns the pixel at the sub-pixel position (x, y) interpolated with the given kernel.
+// Integer coordinates address pixel centers, samples outside the buffer are transparent.
+func (b *Buffer) Sample(x, y float64, k resample.Kernel) [4]float32 {
+	return b.sample(x, y, k, resample.TRANSPARENT)
+}
+
+// sample is like Sample but handles samples outside the buffer according to the edge mode.
+func (b *Buffer) sample(x, y float64, k resample.Kernel, edge resample.Edge) [4]float32 {
+	w, h := b.Rect.Dx(), b.Rect.Dy()
+	at := func(px, py int) [4]float32 {
+		px, okX := edge.Index(px, w)
+		py, okY := edge.Index(py, h)
+		if !okX || !okY {
+			return [4]float32{}
+		}
+		return b.PixelAt(b.Rect.Min.X+px, b.Rect.Min.Y+py)
+	}
+
+	if resample.IsNearest(k) {
+		return at(int(math.Floor(x+0.5)), int(math.Floor(y+0.5)))
+	}
+
+	f := resample.Get(k)
+	x0, x1 := int(math.Ceil(x-f.Support)), int(math.Floor(x+f.Support))
+	y0, y1 := int(math.Ceil(y-f.Support)), int(math.Floor(y+f.Support))
+	var r, g, bl, a, sum float64
+	for sy := y0; sy <= y1; sy++ {
+		wy := f.Weight(float64(sy) - y)
+		if wy == 0 {
+			continue
+		}
+		for sx := x0; sx <= x1; sx++ {
+			wxy := f.Weight(float64(sx)-x) * wy
+			if wxy == 0 {
+				continue
+			}
+			sum += wxy
+			px := at(sx, sy)
+			if px[3] == 0 {
+				continue
+			}
+			pa := float64(px[3]) * wxy
+			r += float64(px[0]) * pa
+			g += float64(px[1]) * pa
+			bl += float64(px[2]) * pa
+			a += pa
+		}
+	}
+	if sum == 0 || a <= 0 {
+		return [4]float32{}
+	}
+	return [4]float32{float32(r / a), float32(g / a), float32(bl / a), float32(a / sum)}
+}
+
+// contribution holds the source pixels (and their weights) that make up one destination pixel of a resize.
+type contribution struct {
+	start   int
+	weights []float64
+}
+
+// contributions computes the contributions of srcSize source pixels to each of the dstSize destination pixels.
+// When downscaling, the kernel is stretched so that all source pixels are taken into account.
+func contributions(dstSize, srcSize int, f *resample.Filter) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	stretch := math.Max(1, scale)
+	support := f.Support * stretch
+	res := make([]contribution, dstSize)
+	for i := range dstSize {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := int(math.Ceil(center - support))
+		end := int(math.Floor(center + support))
+		weights := make([]float64, 0, end-start+1)
+		sum := 0.0
+		for j := start; j <= end; j++ {
+			w := f.Weight((float64(j) - center) / stretch)
+			weights = append(weights, w)
+			sum += w
+		}
+		if sum != 0 {
+			for j := range weights {
+				weights[j] /= sum
+			}
+		}
+		res[i] = contribution{start: start, weights: weights}
+	}
+	return res
+}
+
+// resize scales the buffer to w x h pixels using separable convolution with the given filter.
+// Interpolation happens on premultiplied values and pixels beyond the edges are clamped.
+func (b *Buffer) resize(w, h int, f *resample.Filter) *Buffer {
+	srcW, srcH := b.Rect.Dx(), b.Rect.Dy()
+	res := NewBuffer(image.Rect(0, 0, w, h))
+	if srcW <= 0 || srcH <= 0 {
+		return res
+	}
+	clamp := func(v, max int) int {
+		if v < 0 {
+			return 0
+		}
+		if v >= max {
+			return max - 1
+		}
+		return v
+	}
+
+	// horizontal pass, result is premultiplied
+	cx := contributions(w, srcW, f)
+	tmp := make([]float64, w*srcH*4)
+	for y := range srcH {
+		row := b.Pix[b.PixOffset(b.Rect.Min.X, b.Rect.Min.Y+y):]
+		for x, c := range cx {
+			var r, g, bl, a float64
+			for j, wt := range c.weights {
+				o := clamp(c.start+j, srcW) * 4
+				pa := float64(row[o+3]) * wt
+				r += float64(row[o]) * pa
+				g += float64(row[o+1]) * pa
+				bl += float64(row[o+2]) * pa
+				a += pa
+			}
+			o := (y*w + x) * 4
+			tmp[o], tmp[o+1], tmp[o+2], tmp[o+3] = r, g, bl, a
+		}
+	}
+
+	// vertical pass
+	cy := contributions(h, srcH, f)
+	for y, c := range cy {
+		for x := range w {
+			var r, g, bl, a float64
+			for j, wt := range c.weights {
+				o := (clamp(c.start+j, srcH)*w + x) * 4
+				r += tmp[o] * wt
+				g += tmp[o+1] * wt
+				bl += tmp[o+2] * wt
+				a += tmp[o+3] * wt
+			}
+			if a <= 0 {
+				continue
+			}
+			res.SetPixel(x, y, [4]float32{float32(r / a), float32(g / a), float32(bl / a), float32(a)})
+		}
+	}
+	return res
+}
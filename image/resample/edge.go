@@ -0,0 +1,54 @@
+package resample
+
+import "strings"
+
+// Edge type represents how samples outside of the source image are handled.
+type Edge string
+
+// Constants for edge modes
+const (
+	TRANSPARENT Edge = "transparent" // samples outside the image are transparent
+	CLAMP       Edge = "clamp"       // samples outside the image repeat the nearest edge pixel
+	WRAP        Edge = "wrap"        // the image repeats (tiling)
+	MIRROR      Edge = "mirror"      // the image repeats, every other copy is mirrored
+)
+
+// ParseEdge returns the edge mode with the given name (case-insensitive), unknown names return an empty edge mode.
+// Index treats unknown edge modes like TRANSPARENT.
+func ParseEdge(name string) Edge {
+	switch e := Edge(strings.ToLower(strings.TrimSpace(name))); e {
+	case TRANSPARENT, CLAMP, WRAP, MIRROR:
+		return e
+	}
+	return ""
+}
+
+// EdgeNames returns the names of all edge modes.
+func EdgeNames() []string {
+	return []string{string(TRANSPARENT), string(CLAMP), string(WRAP), string(MIRROR)}
+}
+
+// Index maps the pixel index i to an index in [0, n) according to the edge mode.
+// For TRANSPARENT, indices outside of the range are returned unchanged and ok is false.
+// Modes are matched exactly, use ParseEdge to normalize user input.
+func (e Edge) Index(i, n int) (idx int, ok bool) {
+	if i >= 0 && i < n {
+		return i, true
+	}
+	if n <= 0 {
+		return i, false
+	}
+	switch e {
+	case CLAMP:
+		return max(0, min(i, n-1)), true
+	case WRAP:
+		return (i%n + n) % n, true
+	case MIRROR:
+		i = (i%(2*n) + 2*n) % (2 * n)
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return i, true
+	}
+	return i, false
+}
@@ -0,0 +1,123 @@
+package resample
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/toxyl/gfx/math"
+)
+
+// Kernel type represents resampling kernel identifiers.
+type Kernel string
+
+// Constants for resampling kernels
+const (
+	NEAREST  Kernel = "nearest"
+	BOX      Kernel = "box"
+	BILINEAR Kernel = "bilinear"
+	BICUBIC  Kernel = "bicubic"
+	LANCZOS  Kernel = "lanczos"
+)
+
+// Filter describes a separable resampling kernel.
+type Filter struct {
+	Support float64                 // Radius of the kernel in source pixels.
+	Weight  func(x float64) float64 // Weight of a source pixel at distance x from the sampling position.
+}
+
+// Kernels map allows accessing resampling filters by name.
+var Kernels = map[Kernel]*Filter{
+	NEAREST:  {Support: 0.5, Weight: box},
+	BOX:      {Support: 0.5, Weight: box},
+	BILINEAR: {Support: 1, Weight: triangle},
+	BICUBIC:  {Support: 2, Weight: catmullRom},
+	LANCZOS:  {Support: 3, Weight: lanczos3},
+}
+
+// aliases maps alternative names to kernels.
+var aliases = map[string]Kernel{
+	"area":     BOX,
+	"linear":   BILINEAR,
+	"cubic":    BICUBIC,
+	"lanczos3": LANCZOS,
+}
+
+// Parse returns the kernel with the given name (case-insensitive, aliases like `lanczos3` or `linear` are accepted),
+// unknown names return an empty kernel.
+func Parse(name string) Kernel {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if k, ok := aliases[name]; ok {
+		return k
+	}
+	if _, ok := Kernels[Kernel(name)]; ok {
+		return Kernel(name)
+	}
+	return ""
+}
+
+// Get returns the filter for the given kernel, unknown kernels fall back to nearest-neighbor.
+func Get(k Kernel) *Filter {
+	if f, ok := Kernels[Parse(string(k))]; ok {
+		return f
+	}
+	return Kernels[NEAREST]
+}
+
+// IsNearest returns true if the kernel resolves to nearest-neighbor sampling, unknown kernels do.
+func IsNearest(k Kernel) bool {
+	k = Parse(string(k))
+	return k == NEAREST || k == ""
+}
+
+// Names returns the names of all kernels.
+func Names() []string {
+	return []string{string(NEAREST), string(BOX), string(BILINEAR), string(BICUBIC), string(LANCZOS)}
+}
+
+// AllNames returns the names of all kernels followed by their aliases, i.e. every name Parse accepts.
+func AllNames() []string {
+	return append(Names(), slices.Sorted(maps.Keys(aliases))...)
+}
+
+func box(x float64) float64 {
+	if x >= -0.5 && x < 0.5 {
+		return 1
+	}
+	return 0
+}
+
+func triangle(x float64) float64 {
+	x = math.Abs(x)
+	if x < 1 {
+		return 1 - x
+	}
+	return 0
+}
+
+// catmullRom implements the bicubic kernel with a = -0.5.
+func catmullRom(x float64) float64 {
+	x = math.Abs(x)
+	switch {
+	case x < 1:
+		return (1.5*x-2.5)*x*x + 1
+	case x < 2:
+		return ((-0.5*x+2.5)*x-4)*x + 2
+	}
+	return 0
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+func lanczos3(x float64) float64 {
+	if x > -3 && x < 3 {
+		return sinc(x) * sinc(x/3)
+	}
+	return 0
+}
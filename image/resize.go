@@ -4,28 +4,31 @@ import (
 	"image"
 	"sync"
 
-	"github.com/toxyl/gfx/color/rgba"
+	"github.com/toxyl/gfx/image/resample"
 )
 
+// Resize resizes the image to w x h pixels using nearest-neighbor sampling.
 func (i *Image) Resize(w, h int) *Image {
+	return i.ResizeWith(w, h, resample.NEAREST)
+}
+
+// ResizeWith resizes the image to w x h pixels using the given resampling kernel.
+func (i *Image) ResizeWith(w, h int, kernel resample.Kernel) *Image {
 	i.Lock()
 	defer i.Unlock()
+	if !resample.IsNearest(kernel) {
+		return &Image{raw: i.raw.resize(w, h, resample.Get(kernel)), path: i.path, mu: &sync.Mutex{}}
+	}
 	orgW, orgH := i.raw.Bounds().Max.X, i.raw.Bounds().Max.Y
 	scaleX, scaleY := float64(orgW)/float64(w), float64(orgH)/float64(h)
-	res := image.NewRGBA(image.Rect(0, 0, w, h))
+	res := NewBuffer(image.Rect(0, 0, w, h))
 	for y := 0; y < h; y++ {
 		for x := 0; x < w; x++ {
 			srcX, srcY := float64(x)*scaleX, float64(y)*scaleY
 			newX, newY := int(srcX), int(srcY)
-			r, g, b, a := 0.0, 0.0, 0.0, 0.0
 			if newX >= 0 && newX < orgW && newY >= 0 && newY < orgH {
-				rf, gf, bf, af := i.raw.At(newX, newY).RGBA()
-				r = float64(rf)
-				g = float64(gf)
-				b = float64(bf)
-				a = float64(af)
+				res.SetPixel(x, y, i.raw.PixelAt(newX, newY))
 			}
-			res.Set(x, y, rgba.New(uint8(r/257), uint8(g/257), uint8(b/257), uint8(a/257)).RGBA())
 		}
 	}
 	return &Image{raw: res, path: i.path, mu: &sync.Mutex{}}
@@ -0,0 +1,187 @@
+package image
+
+import (
+	"image"
+	"slices"
+
+	"github.com/toxyl/gfx/color/convert"
+	"github.com/toxyl/gfx/math"
+)
+
+// Channel selects the pixel values statistics are computed from.
+type Channel string
+
+const (
+	CHANNEL_R Channel = "r" // red, range [0, 1]
+	CHANNEL_G Channel = "g" // green, range [0, 1]
+	CHANNEL_B Channel = "b" // blue, range [0, 1]
+	CHANNEL_A Channel = "a" // alpha, range [0, 1]
+	CHANNEL_H Channel = "h" // hue, range [0, 360)
+	CHANNEL_S Channel = "s" // saturation, range [0, 1]
+	CHANNEL_L Channel = "l" // lightness, range [0, 1]
+)
+
+// Channels lists all channels in the order they are usually displayed.
+var Channels = []Channel{CHANNEL_R, CHANNEL_G, CHANNEL_B, CHANNEL_A, CHANNEL_H, CHANNEL_S, CHANNEL_L}
+
+// Range returns the smallest and largest value of the channel.
+func (c Channel) Range() (min, max float64) {
+	if c == CHANNEL_H {
+		return 0, 360
+	}
+	return 0, 1
+}
+
+// value returns the value of the channel for a non-premultiplied pixel.
+func (c Channel) value(px [4]float32) float64 {
+	switch c {
+	case CHANNEL_R:
+		return float64(px[0])
+	case CHANNEL_G:
+		return float64(px[1])
+	case CHANNEL_B:
+		return float64(px[2])
+	case CHANNEL_A:
+		return float64(px[3])
+	}
+	h, s, l := convert.RGBToHSL(float64(px[0]), float64(px[1]), float64(px[2]))
+	switch c {
+	case CHANNEL_H:
+		return h
+	case CHANNEL_S:
+		return s
+	}
+	return l
+}
+
+// Selection limits statistics to a region of the image and/or to the pixels where Mask isn't fully transparent.
+// The mask is aligned with the top left corner of the image. A zero-sized region selects the whole image.
+type Selection struct {
+	X, Y, W, H int
+	Mask       *Image
+}
+
+// values returns the values of ch for all selected pixels. Fully transparent pixels are skipped
+// unless ch is CHANNEL_A, because their color carries no information.
+func (i *Image) values(ch Channel, sel *Selection) []float32 {
+	b := i.Get()
+	r := b.Rect
+	var mask *Buffer
+	if sel != nil {
+		if sel.W > 0 && sel.H > 0 {
+			r = r.Intersect(image.Rect(sel.X, sel.Y, sel.X+sel.W, sel.Y+sel.H))
+		}
+		if sel.Mask != nil {
+			mask = sel.Mask.Get()
+		}
+	}
+	res := make([]float32, 0, r.Dx()*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if mask != nil && mask.PixelAt(x, y)[3] == 0 {
+				continue
+			}
+			px := b.PixelAt(x, y)
+			if px[3] == 0 && ch != CHANNEL_A {
+				continue
+			}
+			res = append(res, float32(ch.value(px)))
+		}
+	}
+	return res
+}
+
+// Histogram counts how many selected pixels fall into each of a number of equally sized bins
+// spanning the range of a channel.
+type Histogram struct {
+	Channel Channel
+	Min     float64 // lower bound of the first bin
+	Max     float64 // upper bound of the last bin
+	Bins    []int
+	Count   int // number of pixels counted
+}
+
+// Bin returns the index of the bin v falls into.
+func (h *Histogram) Bin(v float64) int {
+	n := len(h.Bins)
+	return math.Clamp(int((v-h.Min)/(h.Max-h.Min)*float64(n)), 0, n-1)
+}
+
+// Value returns the value at the center of bin b.
+func (h *Histogram) Value(b int) float64 {
+	return h.Min + (float64(b)+0.5)*(h.Max-h.Min)/float64(len(h.Bins))
+}
+
+// Histogram returns the histogram of a channel with the given number of bins (256 if bins < 1).
+// sel limits the pixels that are counted, nil counts all pixels.
+func (i *Image) Histogram(ch Channel, bins int, sel *Selection) *Histogram {
+	if bins < 1 {
+		bins = 256
+	}
+	lo, hi := ch.Range()
+	h := &Histogram{Channel: ch, Min: lo, Max: hi, Bins: make([]int, bins)}
+	for _, v := range i.values(ch, sel) {
+		h.Bins[h.Bin(float64(v))]++
+		h.Count++
+	}
+	return h
+}
+
+// Stats summarizes the values of a channel.
+type Stats struct {
+	Channel Channel
+	Count   int // number of pixels the statistics are based on
+	Min     float64
+	Max     float64
+	Mean    float64
+	StdDev  float64
+	Median  float64
+	values  []float32 // sorted values, used by Percentile
+}
+
+// Percentile returns the value below which p percent (0 to 100) of the values fall.
+// Values between two pixels are interpolated linearly. It returns 0 if there are no values.
+func (s *Stats) Percentile(p float64) float64 {
+	return percentile(s.values, p)
+}
+
+// percentile returns the value below which p percent (0 to 100) of the sorted values fall, see Stats.Percentile.
+func percentile(values []float32, p float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	pos := math.Clamp(p, 0, 100) / 100 * float64(n-1)
+	lo := int(pos)
+	if lo >= n-1 {
+		return float64(values[n-1])
+	}
+	f := pos - float64(lo)
+	return float64(values[lo])*(1-f) + float64(values[lo+1])*f
+}
+
+// Stats computes statistics of a channel, sel limits the pixels that are used, nil uses all pixels.
+// Fully transparent pixels are ignored for all channels except alpha. Hue is treated like any other
+// channel, so its mean doesn't account for hue wrapping around at 360 degrees.
+func (i *Image) Stats(ch Channel, sel *Selection) *Stats {
+	values := i.values(ch, sel)
+	slices.Sort(values)
+	s := &Stats{Channel: ch, Count: len(values), values: values}
+	if s.Count == 0 {
+		return s
+	}
+	var sum, sumSq float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	s.Mean = sum / float64(s.Count)
+	for _, v := range values {
+		d := float64(v) - s.Mean
+		sumSq += d * d
+	}
+	s.StdDev = math.Sqrt(sumSq / float64(s.Count))
+	s.Min = float64(values[0])
+	s.Max = float64(values[s.Count-1])
+	s.Median = s.Percentile(50)
+	return s
+}
@@ -0,0 +1,312 @@
+package image
+
+import (
+	"image"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/color/hsla"
+)
+
+var (
+	reColor    = regexp.MustCompile(`^\[:(gon|goff|white|black|gray|color|r|g|b|c|m|y|):\]`)
+	reColorHSL = regexp.MustCompile(`^\[:(\d{1,3}):(\d\.\d+):(\d\.\d+):\]`)
+)
+
+// TextAlign defines how the lines of a text are aligned horizontally.
+type TextAlign string
+
+const (
+	ALIGN_LEFT    TextAlign = "left"
+	ALIGN_CENTER  TextAlign = "center"
+	ALIGN_RIGHT   TextAlign = "right"
+	ALIGN_JUSTIFY TextAlign = "justify" // wrapped lines are stretched to the full width, the last line of a paragraph is aligned left
+)
+
+// VerticalAlign defines how a text is aligned vertically inside its box.
+type VerticalAlign string
+
+const (
+	VALIGN_TOP    VerticalAlign = "top"
+	VALIGN_MIDDLE VerticalAlign = "middle"
+	VALIGN_BOTTOM VerticalAlign = "bottom"
+)
+
+// TextBox describes how DrawTextBox lays out text inside a rectangle.
+type TextBox struct {
+	Font       Font          // nil uses the sprite font
+	Wrap       bool          // wraps lines at spaces (or inside words that don't fit) to the width of the box
+	Align      TextAlign     // defaults to ALIGN_LEFT
+	VAlign     VerticalAlign // defaults to VALIGN_TOP
+	Padding    int           // space between the edges of the box and the text
+	Background *hsla.HSLA    // fill of the box, nil draws no background
+}
+
+func (b TextBox) font() Font {
+	if b.Font == nil {
+		return font
+	}
+	return b.Font
+}
+
+// Measure returns the size of the box needed for text, including the padding.
+// With Wrap and maxWidth > 0 the text is wrapped to fit into maxWidth.
+func (b TextBox) Measure(text string, maxWidth int) (w, h int) {
+	width, wrap := 0.0, false
+	if b.Wrap && maxWidth > 0 {
+		width, wrap = float64(max(1, maxWidth-2*b.Padding)), true
+	}
+	_, bounds := layoutText(b.font(), text, hsla.HSLA{}, false, width, wrap, b.Align)
+	return bounds.Dx() + 2*b.Padding, bounds.Dy() + 2*b.Padding
+}
+
+// MeasureText returns the size of the area that DrawTextWith covers when drawing text with the font f
+// (nil uses the sprite font). Markup doesn't count towards the size.
+func MeasureText(f Font, text string) (w, h int) {
+	return TextBox{Font: f}.Measure(text, 0)
+}
+
+// DrawTextBox draws the background of the box at x, y with the size w x h and the text aligned inside it.
+// A width or height <= 0 is replaced by the measured size of the text. Text that doesn't fit isn't clipped.
+func (i *Image) DrawTextBox(b TextBox, text string, x, y, w, h int, col hsla.HSLA, glow bool, mode blend.BlendMode) *Image {
+	f := b.font()
+	width, wrap := 0.0, false
+	if w > 0 {
+		width, wrap = float64(max(1, w-2*b.Padding)), b.Wrap
+	}
+	glyphs, bounds := layoutText(f, text, col, glow, width, wrap, b.Align)
+	if w <= 0 {
+		w = bounds.Dx() + 2*b.Padding
+	}
+	if h <= 0 {
+		h = bounds.Dy() + 2*b.Padding
+	}
+	if b.Background != nil && b.Background.A() > 0 {
+		i.DrawRect(x, y, w, h, 0, b.Background, b.Background, blend.NORMAL)
+	}
+	src := New(max(1, bounds.Dx()), max(1, bounds.Dy()))
+	for _, g := range glyphs {
+		src.drawFontWithOutline(g.glyph, g.x-bounds.Min.X, g.y-bounds.Min.Y, g.col, g.glow, blend.NORMAL)
+	}
+	dy := 0
+	switch b.VAlign {
+	case VALIGN_MIDDLE:
+		dy = (h - 2*b.Padding - bounds.Dy()) / 2
+	case VALIGN_BOTTOM:
+		dy = h - 2*b.Padding - bounds.Dy()
+	}
+	return i.Draw(src, 0, 0, src.W(), src.H(), x+b.Padding+bounds.Min.X, y+b.Padding+dy, src.W(), src.H(), mode, col.A())
+}
+
+// styledRune is a rune of a text with the color and glow the markup before it selected.
+type styledRune struct {
+	r    rune
+	col  hsla.HSLA
+	glow bool
+}
+
+// parseMarkup removes the inline markup from text and returns its runes with their style.
+func parseMarkup(text string, col hsla.HSLA, glow bool) []styledRune {
+	initialColor := col
+	res := []styledRune{}
+	nextI := 0
+	for i, char := range text {
+		if i < nextI {
+			continue
+		}
+		if char == '[' {
+			if reColor.MatchString(text[i:]) {
+				matches := reColor.FindAllStringSubmatch(text[i:], 1)
+				if len(matches) > 0 {
+					m := matches[0]
+					switch m[1] {
+					case "":
+						col = initialColor
+					case "white":
+						col.SetS(0)
+						col.SetL(1)
+					case "black":
+						col.SetS(0)
+						col.SetL(0)
+					case "gray":
+						col.SetS(0)
+					case "color":
+						col.SetS(0.5)
+					case "r":
+						col.SetH(0)
+					case "g":
+						col.SetH(120)
+					case "b":
+						col.SetH(240)
+					case "c":
+						col.SetH(180)
+					case "m":
+						col.SetH(300)
+					case "y":
+						col.SetH(60)
+					case "gon":
+						glow = true
+					case "goff":
+						glow = false
+					}
+					nextI = i + len(m[0])
+					continue
+				}
+			}
+			if reColorHSL.MatchString(text[i:]) {
+				matches := reColorHSL.FindAllStringSubmatch(text[i:], 1)
+				if len(matches) > 0 {
+					m := matches[0]
+					if h, err := strconv.Atoi(m[1]); err == nil {
+						col.SetH(float64(h))
+					}
+					if s, err := strconv.ParseFloat(m[2], 64); err == nil {
+						col.SetS(float64(s))
+					}
+					if l, err := strconv.ParseFloat(m[3], 64); err == nil {
+						col.SetL(float64(l))
+					}
+					nextI = i + len(m[0])
+					continue
+				}
+			}
+		}
+		res = append(res, styledRune{r: char, col: col, glow: glow})
+	}
+	return res
+}
+
+// advances returns the horizontal advance of every rune of line, including the kerning to the rune before it.
+func advances(f Font, line []styledRune) []float64 {
+	res := make([]float64, len(line))
+	for i, sr := range line {
+		_, _, _, res[i] = f.Glyph(sr.r)
+		if i > 0 {
+			res[i] += f.Kern(line[i-1].r, sr.r)
+		}
+	}
+	return res
+}
+
+// wrapLine breaks line into lines that fit into width. Lines are broken at the last space that fits,
+// words that are wider than width are broken between runes. Spaces at the breaks are removed.
+func wrapLine(f Font, line []styledRune, width float64) [][]styledRune {
+	res := [][]styledRune{}
+	for len(line) > 0 {
+		adv := advances(f, line)
+		n, x := 0, 0.0
+		for n < len(line) && (n == 0 || x+adv[n] <= width) {
+			x += adv[n]
+			n++
+		}
+		if n < len(line) {
+			for j := n; j > 0; j-- {
+				if line[j].r == ' ' {
+					n = j
+					break
+				}
+			}
+		}
+		end := n
+		for end > 0 && line[end-1].r == ' ' {
+			end--
+		}
+		res = append(res, line[:end])
+		line = line[n:]
+		for len(line) > 0 && line[0].r == ' ' {
+			line = line[1:]
+		}
+	}
+	return res
+}
+
+// placedGlyph is a glyph positioned by layoutText.
+type placedGlyph struct {
+	glyph *Image
+	x, y  int
+	col   hsla.HSLA
+	glow  bool
+}
+
+// layoutText positions the glyphs of text, interpreting newlines and the inline color markup.
+// With wrap, lines are wrapped to width. Lines are aligned within width, or within the widest line if width is 0.
+// It returns the glyphs and the bounds of the text, relative to the top left of the first line.
+func layoutText(f Font, text string, col hsla.HSLA, glow bool, width float64, wrap bool, align TextAlign) ([]placedGlyph, image.Rectangle) {
+	type line struct {
+		runes []styledRune
+		last  bool // last line of a paragraph
+	}
+	lines := []line{}
+	runes := parseMarkup(text, col, glow)
+	start := 0
+	for j := 0; j <= len(runes); j++ {
+		if j < len(runes) && runes[j].r != '\n' {
+			continue
+		}
+		paragraph := runes[start:j]
+		start = j + 1
+		if !wrap || len(paragraph) == 0 {
+			lines = append(lines, line{runes: paragraph, last: true})
+			continue
+		}
+		wrapped := wrapLine(f, paragraph, width)
+		for k, l := range wrapped {
+			lines = append(lines, line{runes: l, last: k == len(wrapped)-1})
+		}
+	}
+
+	widths := make([]float64, len(lines))
+	maxWidth := 0.0
+	for j, l := range lines {
+		for _, a := range advances(f, l.runes) {
+			widths[j] += a
+		}
+		maxWidth = math.Max(maxWidth, widths[j])
+	}
+	if width <= 0 {
+		width = maxWidth
+	}
+
+	lineHeight := f.LineHeight()
+	res := []placedGlyph{}
+	bounds := image.Rectangle{}
+	for j, l := range lines {
+		penX, penY, gap := 0.0, float64(j)*lineHeight, 0.0
+		switch align {
+		case ALIGN_CENTER:
+			penX = (width - widths[j]) / 2
+		case ALIGN_RIGHT:
+			penX = width - widths[j]
+		case ALIGN_JUSTIFY:
+			spaces := 0
+			for _, sr := range l.runes {
+				if sr.r == ' ' {
+					spaces++
+				}
+			}
+			if !l.last && spaces > 0 {
+				gap = (width - widths[j]) / float64(spaces)
+			}
+		}
+		lineX := penX
+		for k, sr := range l.runes {
+			if k > 0 {
+				penX += f.Kern(l.runes[k-1].r, sr.r)
+			}
+			glyph, dx, dy, advance := f.Glyph(sr.r)
+			if glyph != nil && glyph.W() > 0 && glyph.H() > 0 {
+				g := placedGlyph{glyph: glyph, x: int(math.Round(penX)) + dx, y: int(math.Round(penY)) + dy, col: sr.col, glow: sr.glow}
+				res = append(res, g)
+				bounds = bounds.Union(image.Rect(g.x, g.y, g.x+glyph.W(), g.y+glyph.H()))
+			}
+			penX += advance
+			if sr.r == ' ' {
+				penX += gap
+			}
+		}
+		bounds = bounds.Union(image.Rect(int(math.Round(lineX)), int(math.Round(penY)), int(math.Ceil(penX)), int(math.Round(penY+lineHeight))))
+	}
+	return res, bounds
+}
@@ -15,7 +15,7 @@ func (i *Image) ToPolar(angleStart, angleEnd, rotation, fisheye float64) *Image
 
 	w := i.raw.Bounds().Dx()
 	h := i.raw.Bounds().Dy()
-	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	dst := NewBuffer(image.Rect(0, 0, w, h))
 	cx := float64(w) / 2.0
 	cy := float64(h) / 2.0
 	maxR := math.Min(cx, cy)
@@ -60,14 +60,12 @@ func (i *Image) ToPolar(angleStart, angleEnd, rotation, fisheye float64) *Image
 			if angleEnd >= angleStart {
 				// If outside the allowed angle range, set pixel transparent.
 				if theta < angleStart || theta > angleEnd {
-					dst.Set(x, y, image.Transparent)
 					continue
 				}
 				proportion = (theta - angleStart) / (angleEnd - angleStart)
 			} else {
 				// Handle wrapped range, e.g. (90, -90) or (270, 90)
 				if theta < angleStart && theta > angleEnd {
-					dst.Set(x, y, image.Transparent)
 					continue
 				}
 				totalRange := (360 - angleStart) + angleEnd
@@ -92,7 +90,7 @@ func (i *Image) ToPolar(angleStart, angleEnd, rotation, fisheye float64) *Image
 				srcY = h - 1
 			}
 
-			dst.Set(x, y, i.raw.At(srcX, srcY))
+			dst.SetPixel(x, y, i.raw.PixelAt(srcX, srcY))
 		}
 	}
 
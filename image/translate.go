@@ -2,8 +2,7 @@
 package image
 
 import (
-	"image"
-	"sync"
+	"github.com/toxyl/gfx/image/resample"
 )
 
 // Translate translates the image by the specified absolute pixel offsets (x, y).
@@ -11,24 +10,14 @@ import (
 // If wrap is true, pixels that exit one side reappear on the opposite side (wrap-around).
 // Otherwise, areas outside the original bounds are filled with transparent pixels.
 func (i *Image) Translate(x, y int, wrap bool) *Image {
-	i.Lock()
-	defer i.Unlock()
-	w := i.raw.Bounds().Dx()
-	h := i.raw.Bounds().Dy()
-	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	return i.TranslateWith(float64(x), float64(y), wrap, resample.NEAREST)
+}
 
-	for j := range h {
-		for k := range w {
-			srcX := k - x
-			srcY := j - y
-			if wrap {
-				srcX = (srcX%w + w) % w
-				srcY = (srcY%h + h) % h
-				dst.Set(k, j, i.raw.At(srcX, srcY))
-			} else if srcX >= 0 && srcX < w && srcY >= 0 && srcY < h {
-				dst.Set(k, j, i.raw.At(srcX, srcY))
-			}
-		}
+// TranslateWith is like Translate but accepts sub-pixel offsets which are sampled with the given resampling kernel.
+func (i *Image) TranslateWith(x, y float64, wrap bool, kernel resample.Kernel) *Image {
+	edge := resample.TRANSPARENT
+	if wrap {
+		edge = resample.WRAP
 	}
-	return &Image{raw: dst, path: i.path, mu: &sync.Mutex{}}
+	return i.Warp(Translation(x, y), kernel, edge)
 }
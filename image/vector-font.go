@@ -0,0 +1,124 @@
+package image
+
+import (
+	"math"
+	"os"
+	"sync"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// vectorGlyph is a rendered glyph of a VectorFont.
+type vectorGlyph struct {
+	image   *Image
+	dx, dy  int
+	advance float64
+}
+
+// VectorFont renders TrueType and OpenType fonts at a fixed size with anti-aliasing and kerning.
+// It is safe for concurrent use, rendered glyphs are cached.
+type VectorFont struct {
+	mu         *sync.Mutex
+	face       xfont.Face
+	ascent     float64
+	lineHeight float64
+	glyphs     map[rune]vectorGlyph
+}
+
+// NewFont parses TrueType or OpenType font data, e.g. embedded with go:embed, and returns it at the given size
+// (the height of the em square in pixels).
+func NewFont(data []byte, size float64) (*VectorFont, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: xfont.HintingNone})
+	if err != nil {
+		return nil, err
+	}
+	m := face.Metrics()
+	return &VectorFont{
+		mu:         &sync.Mutex{},
+		face:       face,
+		ascent:     fixedToFloat(m.Ascent),
+		lineHeight: fixedToFloat(m.Height),
+		glyphs:     map[rune]vectorGlyph{},
+	}, nil
+}
+
+// LoadFont loads a TrueType (.ttf) or OpenType (.otf) font from path and returns it at the given size.
+func LoadFont(path string, size float64) (*VectorFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &IOError{Op: "read", Path: path, Err: err}
+	}
+	return NewFont(data, size)
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// SetLineHeight sets the distance between two lines in pixels, 0 restores the line height defined by the font.
+func (vf *VectorFont) SetLineHeight(h float64) *VectorFont {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+	if h <= 0 {
+		h = fixedToFloat(vf.face.Metrics().Height)
+	}
+	vf.lineHeight = h
+	return vf
+}
+
+// LineHeight returns the distance between the tops of two lines in pixels.
+func (vf *VectorFont) LineHeight() float64 {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+	return vf.lineHeight
+}
+
+// Kern returns the kerning between a and b in pixels, as defined by the font.
+func (vf *VectorFont) Kern(a, b rune) float64 {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+	return fixedToFloat(vf.face.Kern(a, b))
+}
+
+// Glyph returns the glyph for r with a transparent border of one pixel, so outlines aren't cut off.
+// Runes the font has no glyph for are drawn with the glyph of the replacement character or a question mark.
+func (vf *VectorFont) Glyph(r rune) (*Image, int, int, float64) {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+	if g, ok := vf.glyphs[r]; ok {
+		return g.image, g.dx, g.dy, g.advance
+	}
+	g := vectorGlyph{}
+	for _, c := range []rune{r, '�', '?'} {
+		dot := fixed.Point26_6{X: 0, Y: fixed.Int26_6(math.Round(vf.ascent * 64))}
+		dr, mask, maskp, advance, ok := vf.face.Glyph(dot, c)
+		if !ok && c != '?' {
+			continue
+		}
+		g.advance = fixedToFloat(advance)
+		g.dx, g.dy = dr.Min.X-1, dr.Min.Y-1
+		g.image = New(dr.Dx()+2, dr.Dy()+2)
+		for y := range dr.Dy() {
+			for x := range dr.Dx() {
+				_, _, _, a := mask.At(maskp.X+x, maskp.Y+y).RGBA()
+				if a > 0 {
+					g.image.raw.SetPixel(x+1, y+1, [4]float32{1, 1, 1, float32(a) / 0xFFFF})
+				}
+			}
+		}
+		break
+	}
+	if g.image == nil {
+		g.image = New(0, 0)
+	}
+	vf.glyphs[r] = g
+	return g.image, g.dx, g.dy, g.advance
+}
+
+var _ Font = (*VectorFont)(nil)
@@ -0,0 +1,226 @@
+package image
+
+import (
+	"math"
+	"slices"
+
+	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/image/executor"
+)
+
+// subScanlines is the number of samples per pixel row used for vertical anti-aliasing,
+// horizontal coverage is computed exactly.
+const subScanlines = 16
+
+// polyEdge is a non-horizontal edge of a polygon, dir is +1 for edges pointing down and -1 for edges pointing up.
+type polyEdge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+// crossing is the position where an edge crosses a scanline.
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// rasterize composites col onto the image wherever the polygons cover pixels according to rule.
+// Partially covered pixels are blended with a proportionally reduced alpha.
+func (i *Image) rasterize(polys []polygon, rule FillRule, col *hsla.HSLA, mode blend.BlendMode) {
+	if col == nil || col.A() <= 0 || len(polys) == 0 {
+		return
+	}
+	ctx := i.Context()
+	buf := i.Get()
+	w, h := buf.Bounds().Dx(), buf.Bounds().Dy()
+
+	edges := []polyEdge{}
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, pg := range polys {
+		for k := range pg {
+			p0, p1 := pg[k], pg[(k+1)%len(pg)]
+			if p0.y == p1.y || math.IsNaN(p0.x+p0.y+p1.x+p1.y) {
+				continue
+			}
+			e := polyEdge{p0.x, p0.y, p1.x, p1.y, 1}
+			if p0.y > p1.y {
+				e = polyEdge{p1.x, p1.y, p0.x, p0.y, -1}
+			}
+			edges = append(edges, e)
+			minY, maxY = math.Min(minY, e.y0), math.Max(maxY, e.y1)
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+	slices.SortFunc(edges, func(a, b polyEdge) int {
+		switch {
+		case a.y0 < b.y0:
+			return -1
+		case a.y0 > b.y0:
+			return 1
+		}
+		return 0
+	})
+	yStart := max(0, int(math.Floor(minY)))
+	yEnd := min(h, int(math.Ceil(maxY)))
+	if yStart >= yEnd {
+		return
+	}
+
+	_ = executor.Rows(ctx, yStart, yEnd, func(y0, y1 int) {
+		cov := make([]float32, w)
+		active := []polyEdge{}
+		crossings := []crossing{}
+		dst := &hsla.HSLA{}
+		for y := y0; y < y1; y++ {
+			top, bottom := float64(y), float64(y+1)
+			active = active[:0]
+			for _, e := range edges {
+				if e.y0 >= bottom {
+					break
+				}
+				if e.y1 > top {
+					active = append(active, e)
+				}
+			}
+			if len(active) == 0 {
+				continue
+			}
+			clear(cov)
+			xMin, xMax := w, 0
+			for s := range subScanlines {
+				sy := top + (float64(s)+0.5)/subScanlines
+				crossings = crossings[:0]
+				for _, e := range active {
+					if sy >= e.y0 && sy < e.y1 {
+						crossings = append(crossings, crossing{e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0), e.dir})
+					}
+				}
+				slices.SortFunc(crossings, func(a, b crossing) int {
+					switch {
+					case a.x < b.x:
+						return -1
+					case a.x > b.x:
+						return 1
+					}
+					return 0
+				})
+				winding := 0
+				for k := 0; k < len(crossings)-1; k++ {
+					winding += crossings[k].dir
+					inside := winding != 0
+					if rule == FILL_EVENODD {
+						inside = (k+1)%2 == 1
+					}
+					if !inside {
+						continue
+					}
+					a, b := addSpan(cov, crossings[k].x, crossings[k+1].x, 1.0/subScanlines)
+					xMin, xMax = min(xMin, a), max(xMax, b)
+				}
+			}
+			for x := xMin; x < xMax; x++ {
+				c := min(cov[x], 1)
+				if c <= 0 {
+					continue
+				}
+				src := *col
+				src.Alpha *= float64(c)
+				pixelToHSLA(buf.PixelAt(x, y), dst)
+				buf.SetPixel(x, y, hslaToPixel(blend.HSLA(dst, &src, mode, 1.0)))
+			}
+		}
+	})
+}
+
+// addSpan adds weight times the covered fraction of each pixel between x0 and x1 to cov
+// and returns the range of pixels that have been touched.
+func addSpan(cov []float32, x0, x1 float64, weight float32) (int, int) {
+	w := float64(len(cov))
+	x0, x1 = math.Max(0, x0), math.Min(w, x1)
+	if x0 >= x1 {
+		return len(cov), 0
+	}
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		cov[i0] += float32(x1-x0) * weight
+		return i0, i0 + 1
+	}
+	cov[i0] += float32(float64(i0+1)-x0) * weight
+	for x := i0 + 1; x < i1; x++ {
+		cov[x] += weight
+	}
+	if i1 < len(cov) {
+		cov[i1] += float32(x1-float64(i1)) * weight
+		return i0, i1 + 1
+	}
+	return i0, i1
+}
+
+// FillPath fills the inside of the path, as defined by rule, with col. Open subpaths are closed implicitly.
+// Edges are anti-aliased and blended onto the image with mode. Rows are processed on the shared executor.
+func (i *Image) FillPath(p *Path, rule FillRule, col *hsla.HSLA, mode blend.BlendMode) *Image {
+	i.rasterize(p.fill(), rule, col, mode)
+	return i
+}
+
+// StrokePath draws the outline of the path with the given thickness (in pixels) and col.
+// Open subpaths end with lineCap, corners are joined round. Edges are anti-aliased and blended onto the image with mode.
+func (i *Image) StrokePath(p *Path, thickness float64, lineCap LineCap, col *hsla.HSLA, mode blend.BlendMode) *Image {
+	if thickness > 0 {
+		i.rasterize(p.stroke(thickness, lineCap), FILL_NONZERO, col, mode)
+	}
+	return i
+}
+
+// DrawPath fills the path with colFill according to rule and then strokes its outline with colBorder.
+// A nil or fully transparent color skips the fill or the outline.
+func (i *Image) DrawPath(p *Path, thickness float64, lineCap LineCap, rule FillRule, colBorder, colFill *hsla.HSLA, mode blend.BlendMode) *Image {
+	i.FillPath(p, rule, colFill, mode)
+	return i.StrokePath(p, thickness, lineCap, colBorder, mode)
+}
+
+// DrawLine draws an anti-aliased line from (x0, y0) to (x1, y1) with the given thickness and lineCap.
+// Like all vector primitives it uses continuous coordinates, the center of pixel (x, y) is at (x+0.5, y+0.5).
+func (i *Image) DrawLine(x0, y0, x1, y1, thickness float64, lineCap LineCap, col *hsla.HSLA, mode blend.BlendMode) *Image {
+	return i.StrokePath(NewPath().MoveTo(x0, y0).LineTo(x1, y1), thickness, lineCap, col, mode)
+}
+
+// DrawPolyline draws anti-aliased lines through the points without closing the shape.
+func (i *Image) DrawPolyline(points [][2]float64, thickness float64, lineCap LineCap, col *hsla.HSLA, mode blend.BlendMode) *Image {
+	p := NewPath()
+	for k, pt := range points {
+		if k == 0 {
+			p.MoveTo(pt[0], pt[1])
+			continue
+		}
+		p.LineTo(pt[0], pt[1])
+	}
+	return i.StrokePath(p, thickness, lineCap, col, mode)
+}
+
+// DrawPolygon draws the closed polygon through the points, filled with colFill according to rule
+// and outlined with colBorder. A nil or fully transparent color skips the fill or the outline.
+func (i *Image) DrawPolygon(points [][2]float64, thickness float64, rule FillRule, colBorder, colFill *hsla.HSLA, mode blend.BlendMode) *Image {
+	return i.DrawPath(NewPath().Polygon(points...), thickness, CAP_BUTT, rule, colBorder, colFill, mode)
+}
+
+// DrawEllipse draws the ellipse around (cx, cy) with the radii rx and ry, filled with colFill and outlined with colBorder.
+// A nil or fully transparent color skips the fill or the outline.
+func (i *Image) DrawEllipse(cx, cy, rx, ry, thickness float64, colBorder, colFill *hsla.HSLA, mode blend.BlendMode) *Image {
+	return i.DrawPath(NewPath().Ellipse(cx, cy, rx, ry), thickness, CAP_BUTT, FILL_NONZERO, colBorder, colFill, mode)
+}
+
+// DrawCircle draws the circle around (cx, cy) with radius r, filled with colFill and outlined with colBorder.
+// A nil or fully transparent color skips the fill or the outline.
+func (i *Image) DrawCircle(cx, cy, r, thickness float64, colBorder, colFill *hsla.HSLA, mode blend.BlendMode) *Image {
+	return i.DrawEllipse(cx, cy, r, r, thickness, colBorder, colFill, mode)
+}
+
+// DrawArc draws the elliptical arc around (cx, cy) with the radii rx and ry from angleStart to angleEnd
+// (in degrees, clockwise from the positive x-axis) with the given thickness and lineCap.
+func (i *Image) DrawArc(cx, cy, rx, ry, angleStart, angleEnd, thickness float64, lineCap LineCap, col *hsla.HSLA, mode blend.BlendMode) *Image {
+	return i.StrokePath(NewPath().Arc(cx, cy, rx, ry, angleStart, angleEnd), thickness, lineCap, col, mode)
+}
@@ -7,17 +7,16 @@ import (
 	"os"
 )
 
-func Save(img image.Image, path string) {
+func Save(img image.Image, path string) error {
 	outFile, err := os.Create(path) // #nosec G304
 	if err != nil {
-		panic(err)
+		return err
 	}
-	defer outFile.Close()
-
-	err = jpeg.Encode(outFile, img, nil)
-	if err != nil {
-		panic(err)
+	if err := jpeg.Encode(outFile, img, nil); err != nil {
+		_ = outFile.Close()
+		return err
 	}
+	return outFile.Close()
 }
 
 func FromFile(filename string) (image.Image, error) {
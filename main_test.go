@@ -1,15 +1,25 @@
 package main
 
 import (
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	goimage "image"
+	"image/color"
+	"runtime"
+	"slices"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/color/convert"
 	"github.com/toxyl/gfx/color/filter"
 	"github.com/toxyl/gfx/color/hsla"
 	"github.com/toxyl/gfx/color/rgba"
 	"github.com/toxyl/gfx/coordinates"
+	"github.com/toxyl/gfx/filters/affine"
 	"github.com/toxyl/gfx/filters/alphamap"
 	"github.com/toxyl/gfx/filters/blur"
 	"github.com/toxyl/gfx/filters/brightness"
@@ -19,24 +29,40 @@ import (
 	"github.com/toxyl/gfx/filters/edgedetect"
 	"github.com/toxyl/gfx/filters/emboss"
 	"github.com/toxyl/gfx/filters/enhance"
+	"github.com/toxyl/gfx/filters/expression"
 	"github.com/toxyl/gfx/filters/extract"
+	"github.com/toxyl/gfx/filters/fliph"
+	"github.com/toxyl/gfx/filters/frompolar"
 	"github.com/toxyl/gfx/filters/gamma"
 	"github.com/toxyl/gfx/filters/gray"
 	"github.com/toxyl/gfx/filters/hue"
 	"github.com/toxyl/gfx/filters/huecontrast"
 	"github.com/toxyl/gfx/filters/invert"
+	"github.com/toxyl/gfx/filters/lens"
 	"github.com/toxyl/gfx/filters/lum"
 	"github.com/toxyl/gfx/filters/lumcontrast"
 	"github.com/toxyl/gfx/filters/pastelize"
+	"github.com/toxyl/gfx/filters/perspective"
+	"github.com/toxyl/gfx/filters/pinch"
+	"github.com/toxyl/gfx/filters/ripple"
 	"github.com/toxyl/gfx/filters/sat"
 	"github.com/toxyl/gfx/filters/satcontrast"
 	"github.com/toxyl/gfx/filters/sepia"
 	"github.com/toxyl/gfx/filters/sharpen"
+	"github.com/toxyl/gfx/filters/swirl"
 	"github.com/toxyl/gfx/filters/threshold"
+	"github.com/toxyl/gfx/filters/topolar"
 	"github.com/toxyl/gfx/filters/vibrance"
+	"github.com/toxyl/gfx/filters/wave"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/executor"
+	"github.com/toxyl/gfx/image/resample"
 	"github.com/toxyl/gfx/math"
+	"github.com/toxyl/gfx/math/expr"
+	"github.com/toxyl/gfx/net"
 	"github.com/toxyl/gfx/parser"
+	"github.com/toxyl/gfx/ui/dialogbox"
+	"golang.org/x/image/font/gofont/goregular"
 )
 
 var (
@@ -205,7 +231,7 @@ func makeTestImage(size int, hue, hueTolerance, hueFeather, sat, satTolerance, s
 }
 
 func extractTest(img *image.Image, w, h int, hue, hueTolerance, hueFeather, sat, satTolerance, satFeather, lum, lumTolerance, lumFeather float64) *image.Image {
-	return extract.Apply(img, filter.ToColorFilter(hue, hueTolerance, hueFeather, sat, satTolerance, satFeather, lum, lumTolerance, lumFeather)).Resize(w, h)
+	return extract.Apply(img, filter.ToColorFilter(hue, hueTolerance, hueFeather, sat, satTolerance, satFeather, lum, lumTolerance, lumFeather), image.SPACE_HSL).Resize(w, h)
 }
 
 func renderTestImage(name string, size int, h, hTolerance, hFeather, s, sTolerance, sFeather, l, lTolerance, lFeather float64) {
@@ -219,7 +245,42 @@ func renderTestImage(name string, size int, h, hTolerance, hFeather, s, sToleran
 	extractTest(fAIAImage.Clone(), size, size, h, hTolerance, hFeather, s, sTolerance, sFeather, l, lTolerance, lFeather).SaveAsPNG(fAIA)
 }
 
+func TestHighBitDepth(t *testing.T) {
+	// 16-bit values that aren't multiples of 257 can't be represented with 8 bits
+	colors := []color.NRGBA64{
+		{R: 0x1234, G: 0x8001, B: 0xFFFE, A: 0xFFFF},
+		{R: 0x0001, G: 0x7FFF, B: 0xABCD, A: 0x8001},
+		{R: 0xFEFF, G: 0x0101, B: 0x0102, A: 0x0003},
+	}
+	src := goimage.NewNRGBA64(goimage.Rect(5, 7, 5+len(colors), 8))
+	for x, c := range colors {
+		src.SetNRGBA64(5+x, 7, c)
+	}
+	img := image.NewFromImage(src)
+	for x, want := range colors {
+		if got := img.Get().At(x, 0); got != want {
+			t.Errorf("pixel %d: expected %v, got %v", x, want, got)
+		}
+	}
+	// processing that doesn't change the values must keep the precision
+	res := img.Clone().ProcessPixels(func(x, y int, px []float32) {}).Crop(0, 0, len(colors), 1, false)
+	for x, want := range colors {
+		if got := res.Get().At(x, 0); got != want {
+			t.Errorf("pixel %d after processing: expected %v, got %v", x, want, got)
+		}
+	}
+
+	gray := goimage.NewGray16(goimage.Rect(0, 0, 1, 1))
+	gray.SetGray16(0, 0, color.Gray16{Y: 0x1234})
+	if got, want := image.NewFromImage(gray).Get().At(0, 0), (color.NRGBA64{R: 0x1234, G: 0x1234, B: 0x1234, A: 0xFFFF}); got != want {
+		t.Errorf("gray: expected %v, got %v", want, got)
+	}
+}
+
 func TestImageRendering(t *testing.T) {
+	if fAIAImage == nil {
+		t.Skip("AIA image could not be loaded")
+	}
 	var (
 		SIZE          = 512
 		SAT           = 0.65
@@ -334,6 +395,9 @@ func TestImageBlending(t *testing.T) {
 		blend.SOFT_LIGHT,
 		blend.HARD_LIGHT,
 		blend.PIN_LIGHT,
+		blend.VIVID_LIGHT,
+		blend.LINEAR_LIGHT,
+		blend.HARD_MIX,
 		//
 		blend.DIFFERENCE,
 		blend.EXCLUSION,
@@ -343,6 +407,15 @@ func TestImageBlending(t *testing.T) {
 		blend.AVERAGE,
 		blend.NEGATION,
 		//
+		blend.HUE,
+		blend.SATURATION,
+		blend.COLOR,
+		blend.LUMINOSITY,
+		//
+		blend.LIGHTER_COLOR,
+		blend.DARKER_COLOR,
+		//
+		blend.DISSOLVE,
 		blend.ERASE,
 	}
 
@@ -352,10 +425,43 @@ func TestImageBlending(t *testing.T) {
 			{Source: "test_data/test2.png", BlendMode: string(tt), Alpha: 1.0},
 			{Source: "test_data/test1.png", BlendMode: string(blend.NORMAL), Alpha: 1.0},
 		}
-		c.Render().SaveAsPNG("test_data/blendmode/" + string(tt) + ".png")
-		c.SaveYAML("test_data/compositions/blend-" + string(tt) + ".yaml")
-		c.SaveGFXS("test_data/compositions/blend-" + string(tt) + ".gfxs")
+		img, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := img.Save("test_data/blendmode/" + string(tt) + ".png"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SaveYAML("test_data/compositions/blend-" + string(tt) + ".yaml"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SaveGFXS("test_data/compositions/blend-" + string(tt) + ".gfxs"); err != nil {
+			t.Fatal(err)
+		}
+
+	}
 
+	// the destination fades out, so the operators show where they keep source and destination
+	for _, op := range blend.CompositeOpNames() {
+		c := parser.NewComposition("composite", 512, 512)
+		c.Layers = []*parser.Layer{
+			{Source: "test_data/test2.png", BlendMode: string(blend.MULTIPLY), Alpha: 1.0, Composite: op},
+			{Source: "gradient:radial(r=0.4 hsla(60 1 0.5 1) 0.5 hsla(60 1 0.5 0))", BlendMode: string(blend.NORMAL), Alpha: 1.0},
+		}
+		img, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := img.Save("test_data/blendmode/composite-" + op + ".png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := parser.ParseComposition("[LAYERS]\nmultiply 1.0 * composite src-atop test_data/test2.png")
+	if err != nil || c.Layers[0].Composite != string(blend.SRC_ATOP) {
+		t.Errorf("expected a src-atop layer, got %v", err)
+	}
+	if _, err := parser.ParseComposition("[LAYERS]\nmultiply 1.0 * composite src-on-top test_data/test2.png"); err == nil {
+		t.Errorf("expected an error for an unknown operator")
 	}
 }
 
@@ -367,14 +473,990 @@ func TestComposition(t *testing.T) {
 		{"sun", "test_data/compositions/sun.gfxs"},
 		{"sun_spots", "test_data/compositions/sun_spots.gfxs"},
 		{"lasco_c3", "test_data/compositions/lasco_c3.gfxs"},
+		{"heat_haze", "test_data/compositions/heat_haze.gfxs"},
+		{"gradients", "test_data/compositions/gradients.gfxs"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := parser.NewComposition(tt.name, 0, 0).LoadGFXS(tt.path)
-			c.Render().SaveAsPNG("test_data/compositions/render/" + tt.name + ".png")
-			c.SaveGFXS("test_data/compositions/render/" + tt.name + ".gfxs")
+			c, err := parser.NewComposition(tt.name, 0, 0).LoadGFXS(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := c.Render(context.Background())
+			var ioErr *parser.IOError
+			if errors.As(err, &ioErr) && net.IsURL(ioErr.Path) {
+				t.Skip(err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := img.Save("test_data/compositions/render/" + tt.name + ".png"); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.SaveGFXS("test_data/compositions/render/" + tt.name + ".gfxs"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestExecutorResize(t *testing.T) {
+	executor.SetConcurrency(4)
+	// new workers start asynchronously, retry until all of them are idle and get a band
+	var release, done chan struct{}
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		var entered atomic.Int32
+		started := make(chan struct{})
+		release, done = make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = executor.Rows(context.Background(), 0, 256, func(y0, y1 int) {
+				if entered.Add(1) == 4 {
+					close(started)
+				}
+				<-release
+				_ = executor.Concurrency() // busy workers need the lock of the pool while it shrinks
+			})
+		}()
+		select {
+		case <-started:
+		case <-time.After(100 * time.Millisecond):
+			close(release)
+			<-done
+			if time.Now().After(deadline) {
+				t.Fatal("expected all workers to get a band")
+			}
+			continue
+		}
+		break
+	}
+	resized, shrunk := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(resized)
+		executor.SetConcurrency(1)
+	}()
+	go func() {
+		defer close(shrunk)
+		for executor.Concurrency() != 1 { // blocks while SetConcurrency holds the lock
+			runtime.Gosched()
+		}
+	}()
+	wait := func(c chan struct{}) {
+		select {
+		case <-c:
+		case <-time.After(10 * time.Second):
+			t.Fatal("expected shrinking the pool not to block busy workers")
+		}
+	}
+	wait(shrunk)
+	close(release) // SetConcurrency waits for the busy surplus workers
+	wait(resized)
+	wait(done)
+	executor.SetConcurrency(0) // not deferred, it would block on a deadlocked pool
+}
+
+func TestRenderProgress(t *testing.T) {
+	c, err := parser.NewComposition("sun", 0, 0).LoadGFXS("test_data/compositions/sun.gfxs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last parser.Progress
+	if _, err := c.RenderWithProgress(context.Background(), func(p parser.Progress) {
+		if p.Percent < last.Percent {
+			t.Errorf("progress went back from %.2f%% to %.2f%% at %s", last.Percent, p.Percent, p.Stage)
+		}
+		last = p
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if last.Stage != parser.STAGE_DONE || last.Percent != 100 {
+		t.Errorf("render ended at %s with %.2f%%", last.Stage, last.Percent)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := c.RenderWithProgress(ctx, func(p parser.Progress) {
+		if p.Stage == parser.STAGE_FILTER {
+			cancel()
+		}
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	// filters run with ctx but the image keeps its own context
+	img := image.New(2, 1)
+	img.SetRGBA(0, 0, rgba.New(255, 0, 0, 255))
+	ctx, cancel = context.WithCancel(context.Background())
+	res, err := parser.NewImageFilter(fliph.Meta.Name, nil).ApplyContext(ctx, img)
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != img || img.GetRGBA(1, 0).R() != 255 {
+		t.Errorf("expected the filter to flip the image in place")
+	}
+	if img.Context().Err() != nil || img.Clone().Context().Err() != nil {
+		t.Errorf("expected the image and its clones to keep the background context")
+	}
+}
+
+func TestResampling(t *testing.T) {
+	src := makeTestImage(128, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	tests := []resample.Kernel{
+		resample.NEAREST,
+		resample.BOX,
+		resample.BILINEAR,
+		resample.BICUBIC,
+		resample.LANCZOS,
+	}
+	for _, tt := range tests {
+		t.Run(string(tt), func(t *testing.T) {
+			src.ResizeWith(512, 512, tt).SaveAsPNG("test_data/resample/up-" + string(tt) + ".png")
+			src.ResizeWith(48, 48, tt).SaveAsPNG("test_data/resample/down-" + string(tt) + ".png")
+			src.RotateWith(30, 64, 64, tt).SaveAsPNG("test_data/resample/rotate-" + string(tt) + ".png")
+		})
+	}
+
+	// kernels must keep a uniform color when resizing or rotating
+	flat := image.New(16, 16)
+	want := [4]float32{0.2, 0.4, 0.6, 1}
+	for y := range 16 {
+		for x := range 16 {
+			flat.Get().SetPixel(x, y, want)
+		}
+	}
+	near := func(a, b [4]float32) bool {
+		for c := range a {
+			if math.Abs(float64(a[c]-b[c])) > 1e-4 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, k := range tests {
+		for name, img := range map[string]*image.Image{
+			"up":     flat.ResizeWith(40, 40, k),
+			"down":   flat.ResizeWith(7, 7, k),
+			"rotate": flat.RotateWith(30, 8, 8, k),
+		} {
+			if px := img.Get().PixelAt(img.CW(), img.CH()); !near(px, want) {
+				t.Errorf("%s %s: expected the center to be %v, got %v", k, name, want, px)
+			}
+		}
+	}
+
+	// a row of 4 pixels with red values 0, 1/3, 2/3 and 1
+	row := image.New(4, 1)
+	for x := range 4 {
+		row.Get().SetPixel(x, 0, [4]float32{float32(x) / 3, 0, 0, 1})
+	}
+	red := func(img *image.Image) []float64 {
+		res := []float64{}
+		for x := range img.W() {
+			res = append(res, math.Round(float64(img.Get().PixelAt(x, 0)[0])*1000)/1000)
+		}
+		return res
+	}
+	if got, want := red(row.ResizeWith(8, 1, resample.NEAREST)), []float64{0, 0, 0.333, 0.333, 0.667, 0.667, 1, 1}; !slices.Equal(got, want) {
+		t.Errorf("nearest: expected %v, got %v", want, got)
+	}
+	if got, want := red(row.ResizeWith(2, 1, resample.BOX)), []float64{0.167, 0.833}; !slices.Equal(got, want) {
+		t.Errorf("box: expected %v, got %v", want, got)
+	}
+	// pixel centers are mapped onto each other, so pixel 1 samples the row at 1.5*4/7-0.5
+	if got, want := red(row.ResizeWith(7, 1, resample.BILINEAR)), []float64{0, 0.119, 0.31, 0.5, 0.69, 0.881, 1}; !slices.Equal(got, want) {
+		t.Errorf("bilinear: expected %v, got %v", want, got)
+	}
+
+	// unknown kernels are rejected by the parser
+	for name, want := range map[string]resample.Kernel{"Bicubic": resample.BICUBIC, "lanczos3": resample.LANCZOS, "linear": resample.BILINEAR, "bilinaer": ""} {
+		if got := resample.Parse(name); got != want {
+			t.Errorf("expected %q to parse as %q, got %q", name, want, got)
+		}
+	}
+	for _, bad := range []string{
+		"[COMPOSITION]\nresample = bilinaer\n",
+		"[LAYERS]\nnormal 1.0 * resample bilinaer test_data/test2.png\n",
+		"[FILTERS]\nfx { rotate(angle=30 resample=bilinaer) }\n",
+	} {
+		if _, err := parser.ParseComposition(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+	if _, err := parser.ParseComposition("[FILTERS]\nfx { rotate(angle=30 resample=linear) }\n"); err != nil {
+		t.Errorf("expected aliases to be accepted, got %v", err)
+	}
+}
+
+func TestWarp(t *testing.T) {
+	m := image.Scaling(2, 0.5).Shear(0.3, 0).Rotate(30).Translate(3.5, -2).Around(10, 20)
+	inv, ok := m.Invert()
+	if !ok {
+		t.Fatal("expected matrix to be invertible")
+	}
+	if x, y := m.Then(inv).Apply(7, 9); math.Abs(x-7) > 1e-9 || math.Abs(y-9) > 1e-9 {
+		t.Errorf("expected m followed by its inverse to map (7, 9) to itself, got (%f, %f)", x, y)
+	}
+	if _, ok := image.Scaling(0, 1).Invert(); ok {
+		t.Error("expected degenerate matrix not to be invertible")
+	}
+
+	// a row of 4 pixels with red values 0, 1/3, 2/3 and 1, moved left by 2.4 pixels
+	row := image.New(4, 1)
+	for x := range 4 {
+		row.SetRGBA(x, 0, rgba.New(x*0x55, 0, 0, 0xFF))
+	}
+	tests := []struct {
+		edge resample.Edge
+		want []int // red values, -1 means transparent
+	}{
+		{resample.TRANSPARENT, []int{0xAA, 0xFF, -1, -1}},
+		{resample.CLAMP, []int{0xAA, 0xFF, 0xFF, 0xFF}},
+		{resample.WRAP, []int{0xAA, 0xFF, 0x00, 0x55}},
+		{resample.MIRROR, []int{0xAA, 0xFF, 0xFF, 0xAA}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.edge), func(t *testing.T) {
+			res := row.Warp(image.Translation(-2.4, 0), resample.NEAREST, tt.edge)
+			for x, want := range tt.want {
+				c := res.GetRGBA(x, 0)
+				if (want < 0 && c.A() != 0) || (want >= 0 && (c.A() != 0xFF || int(math.Round(c.R())) != want)) {
+					t.Errorf("pixel %d: expected red %d, got %v", x, want, c)
+				}
+			}
 		})
 	}
+
+	src := makeTestImage(128, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	for _, e := range resample.EdgeNames() {
+		opts := map[string]any{"rotate": 20.0, "scale-x": -0.4, "shear-x": 0.3, "translate-x": 0.1, "resample": "bilinear", "edge": e}
+		parser.NewImageFilter(affine.Meta.Name, opts).Apply(src.Clone()).SaveAsPNG("test_data/resample/affine-" + e + ".png")
+	}
+}
+
+func TestPerspective(t *testing.T) {
+	from := [4][2]float64{{10, 5}, {90, 20}, {100, 80}, {0, 95}}
+	to := [4][2]float64{{0, 0}, {127, 0}, {127, 127}, {0, 127}}
+	h, ok := image.QuadToQuad(from, to)
+	if !ok {
+		t.Fatal("expected homography to exist")
+	}
+	for k := range 4 {
+		x, y, ok := h.Apply(from[k][0], from[k][1])
+		if !ok || math.Abs(x-to[k][0]) > 1e-9 || math.Abs(y-to[k][1]) > 1e-9 {
+			t.Errorf("corner %d: expected %v, got (%f, %f)", k, to[k], x, y)
+		}
+	}
+	if _, ok := image.QuadToQuad([4][2]float64{{0, 0}, {1, 1}, {2, 2}, {0, 1}}, to); ok {
+		t.Error("expected degenerate quadrilateral to fail")
+	}
+
+	src := makeTestImage(128, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	m := image.Rotation(20).Scale(1.2, 0.8).Around(64, 64)
+	if !slices.Equal(src.Warp(m, resample.BILINEAR, resample.CLAMP).Get().Pix, src.WarpPerspective(m.Homography(), resample.BILINEAR, resample.CLAMP).Get().Pix) {
+		t.Error("expected affine homography to warp like the affine matrix")
+	}
+	if !slices.Equal(src.Get().Pix, parser.NewImageFilter(perspective.Meta.Name, nil).Apply(src.Clone()).Get().Pix) {
+		t.Error("expected perspective filter with default arguments not to change the image")
+	}
+	opts := map[string]any{"x0": 0.2, "y0": 0.1, "x1": 0.8, "y1": 0.1, "resample": "bilinear"}
+	parser.NewImageFilter(perspective.Meta.Name, opts).Apply(src.Clone()).SaveAsPNG("test_data/resample/perspective.png")
+}
+
+func TestFromPolar(t *testing.T) {
+	// concentric rings around the center, unwrapped every row must have a single color
+	rings := image.New(128, 128)
+	for y := range 128 {
+		for x := range 128 {
+			r := math.Sqrt(math.Pow(float64(x)-64, 2) + math.Pow(float64(y)-64, 2))
+			rings.SetRGBA(x, y, rgba.New(int(r)%16*16, 0, 0, 0xFF))
+		}
+	}
+	res := rings.FromPolar(64, 64, 0, 60, 0, 360, 90, 60, resample.NEAREST)
+	if res.W() != 90 || res.H() != 60 {
+		t.Fatalf("expected 90x60 image, got %dx%d", res.W(), res.H())
+	}
+	for y := 0; y < 60; y += 5 {
+		want := res.GetRGBA(0, y).R()
+		for x := range 90 {
+			if c := res.GetRGBA(x, y); math.Abs(c.R()-want) > 16 {
+				t.Fatalf("row %d: expected red %f, got %f at column %d", y, want, c.R(), x)
+			}
+		}
+	}
+
+	src := makeTestImage(128, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	unwrapped := parser.NewImageFilter(frompolar.Meta.Name, map[string]any{"resample": "bilinear"}).Apply(src.Clone())
+	unwrapped.SaveAsPNG("test_data/resample/from-polar.png")
+	parser.NewImageFilter(topolar.Meta.Name, nil).Apply(unwrapped).SaveAsPNG("test_data/resample/from-polar-to-polar.png")
+}
+
+func TestDisplace(t *testing.T) {
+	src := makeTestImage(128, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	tests := []struct {
+		name   string
+		color  *rgba.RGBA
+		dx, dy int
+	}{
+		{"neutral", rgba.New(0x80, 0x80, 0, 0xFF), 0, 0},
+		{"right", rgba.New(0xFF, 0x80, 0, 0xFF), 2, 0},
+		{"up", rgba.New(0x80, 0x00, 0, 0xFF), 0, -2},
+		{"transparent", rgba.New(0xFF, 0xFF, 0, 0x00), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dmap := image.NewWithColor(16, 16, *tt.color)
+			res := src.Displace(dmap, 2.0/128, image.CHANNEL_R, image.CHANNEL_G, resample.NEAREST, resample.CLAMP)
+			for _, p := range [][2]int{{10, 10}, {64, 64}, {100, 30}} {
+				if got, want := res.GetRGBA(p[0], p[1]), src.GetRGBA(p[0]+tt.dx, p[1]+tt.dy); *got != *want {
+					t.Errorf("pixel %v: expected %v, got %v", p, want, got)
+				}
+			}
+		})
+	}
+
+	// a map that can't be loaded fails the render
+	c, err := parser.ParseComposition("[FILTERS]\nfx { displace(map=`test_data/missing.png` strength=0.1) }\n[COMPOSITION]\nwidth = 8\nheight = 8\n[LAYERS]\nnormal 1.0 fx pattern:checkerboard()\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Render(context.Background()); err == nil {
+		t.Error("expected an error for a displacement map that doesn't exist")
+	}
+}
+
+func TestDistortions(t *testing.T) {
+	// red and green encode the position of each pixel, so the colors of the result tell where it was sampled
+	src := image.New(128, 128)
+	for y := range 128 {
+		for x := range 128 {
+			src.Get().SetPixel(x, y, [4]float32{float32(x) / 255, float32(y) / 255, 0, 1})
+		}
+	}
+	tests := []struct {
+		filter       string
+		options      map[string]any
+		x, y         int     // the pixel to check
+		wantX, wantY float64 // where it is sampled from (the center of the image is at 64, 64)
+	}{
+		{swirl.Meta.Name, map[string]any{"angle": 180.0, "radius": 0.4}, 74, 64, 59.53, 55.05},
+		{pinch.Meta.Name, map[string]any{"amount": 0.5}, 80, 64, 89.86, 64},
+		{pinch.Meta.Name, map[string]any{"amount": -0.5, "offset-x": 0.3}, 93, 64, 87.98, 64},
+		{wave.Meta.Name, map[string]any{"amplitude": 0.02, "wavelength": 0.25, "angle": 30.0}, 72, 64, 70.75, 66.17},
+		{ripple.Meta.Name, map[string]any{"amplitude": 0.02, "wavelength": 0.1, "edge": "mirror"}, 80, 64, 82.56, 64},
+		{lens.Meta.Name, map[string]any{"k1": 0.3, "k2": 0.1}, 100, 64, 101.80, 64},
+		{lens.Meta.Name, map[string]any{"k1": -0.2, "edge": "clamp"}, 100, 64, 98.86, 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			if !slices.Equal(src.Get().Pix, parser.NewImageFilter(tt.filter, nil).Apply(src.Clone()).Get().Pix) {
+				t.Errorf("expected %s with default arguments not to change the image", tt.filter)
+			}
+			tt.options["resample"] = "bilinear"
+			px := parser.NewImageFilter(tt.filter, tt.options).Apply(src.Clone()).Get().PixelAt(tt.x, tt.y)
+			if x, y := float64(px[0])*255, float64(px[1])*255; math.Abs(x-tt.wantX) > 0.05 || math.Abs(y-tt.wantY) > 0.05 {
+				t.Errorf("expected (%d, %d) to be sampled at (%.2f, %.2f), got (%.2f, %.2f)", tt.x, tt.y, tt.wantX, tt.wantY, x, y)
+			}
+		})
+	}
+}
+
+func TestVectorDrawing(t *testing.T) {
+	white := hsla.New(0, 0.0, 1.0, 1.0)
+	canvas := func() *image.Image { return image.NewWithColor(64, 64, *rgba.New(0, 0, 0, 0xFF)) }
+	// drawing white onto black, the red value of a pixel is its coverage
+	coverage := func(img *image.Image, x, y int) float64 { return img.GetRGBA(x, y).R() / 0xFF }
+	near := func(v, want float64) bool { return math.Abs(v-want) < 0.02 }
+
+	img := canvas().DrawPolygon([][2]float64{{9.5, 10.5}, {20, 10.5}, {20, 20}, {9.5, 20}}, 0, image.FILL_NONZERO, nil, white, blend.NORMAL)
+	for _, tt := range []struct {
+		x, y int
+		want float64
+	}{{15, 15, 1}, {5, 5, 0}, {9, 15, 0.5}, {15, 10, 0.5}, {20, 15, 0}} {
+		if c := coverage(img, tt.x, tt.y); !near(c, tt.want) {
+			t.Errorf("polygon: expected coverage %.2f at (%d, %d), got %.2f", tt.want, tt.x, tt.y, c)
+		}
+	}
+
+	// two overlapping squares with the same orientation: the overlap is a hole with even-odd only
+	squares := image.NewPath().Polygon([2]float64{10, 10}, [2]float64{30, 10}, [2]float64{30, 30}, [2]float64{10, 30}).
+		Polygon([2]float64{20, 20}, [2]float64{40, 20}, [2]float64{40, 40}, [2]float64{20, 40})
+	if c := coverage(canvas().FillPath(squares, image.FILL_NONZERO, white, blend.NORMAL), 25, 25); !near(c, 1) {
+		t.Errorf("nonzero: expected the overlap to be filled, got coverage %.2f", c)
+	}
+	if c := coverage(canvas().FillPath(squares, image.FILL_EVENODD, white, blend.NORMAL), 25, 25); !near(c, 0) {
+		t.Errorf("evenodd: expected the overlap to be empty, got coverage %.2f", c)
+	}
+
+	caps := []struct {
+		cap  image.LineCap
+		want float64 // coverage of the pixel left of the start point
+	}{{image.CAP_BUTT, 0}, {image.CAP_SQUARE, 0.5}, {image.CAP_ROUND, 0.39}}
+	for _, tt := range caps {
+		img := canvas().DrawLine(5, 30.5, 25, 30.5, 1, tt.cap, white, blend.NORMAL)
+		if c := coverage(img, 10, 30); !near(c, 1) {
+			t.Errorf("%s: expected line to cover (10, 30), got %.2f", tt.cap, c)
+		}
+		if c := coverage(img, 4, 30); math.Abs(c-tt.want) > 0.05 {
+			t.Errorf("%s: expected coverage %.2f at (4, 30), got %.2f", tt.cap, tt.want, c)
+		}
+	}
+
+	img = canvas().DrawCircle(32, 32, 20, 0, nil, white, blend.NORMAL)
+	sum := 0.0
+	for y := range 64 {
+		for x := range 64 {
+			sum += coverage(img, x, y)
+		}
+	}
+	if want := math.Pi * 20 * 20; math.Abs(sum-want) > want*0.01 {
+		t.Errorf("circle: expected a total coverage of %.1f, got %.1f", want, sum)
+	}
+
+	red, blue := hsla.New(0, 1.0, 0.5, 1.0), hsla.New(220, 1.0, 0.5, 0.5)
+	img = canvas().
+		DrawEllipse(32, 32, 26, 14, 2, white, blue, blend.NORMAL).
+		DrawArc(32, 32, 20, 20, 200, 340, 3, image.CAP_ROUND, red, blend.NORMAL).
+		DrawLine(6, 58, 40, 40, 4, image.CAP_SQUARE, red, blend.SCREEN).
+		StrokePath(image.NewPath().MoveTo(4, 4).QuadTo(32, 40, 60, 4).MoveTo(4, 60).CubicTo(20, 20, 44, 100, 60, 60), 1.5, image.CAP_BUTT, white, blend.NORMAL)
+	img.SaveAsPNG("test_data/resample/vector.png")
+}
+
+func TestColorSpaces(t *testing.T) {
+	near := func(a, b []float64, eps float64) bool {
+		for i := range a {
+			if math.Abs(a[i]-b[i]) > eps {
+				return false
+			}
+		}
+		return true
+	}
+	known := []struct {
+		name      string
+		got, want []float64
+	}{
+		{"hsv", func() []float64 {
+			c := convert.RGBAToHSVA(rgba.New(255, 127.5, 0, 255))
+			return []float64{c.H(), c.S(), c.V()}
+		}(), []float64{30, 1, 1}},
+		{"xyz", func() []float64 {
+			c := convert.RGBAToXYZ(rgba.New(255, 255, 255, 255))
+			return []float64{c.X(), c.Y(), c.Z()}
+		}(), []float64{0.9505, 1, 1.089}},
+		{"lab", func() []float64 {
+			c := convert.RGBAToLab(rgba.New(255, 0, 0, 127.5))
+			return []float64{c.L(), c.GreenRed(), c.BlueYellow(), c.A()}
+		}(), []float64{53.24, 80.09, 67.20, 0.5}},
+		{"oklab", func() []float64 {
+			c := convert.RGBAToOKLab(rgba.New(255, 0, 0, 127.5))
+			return []float64{c.L(), c.GreenRed(), c.BlueYellow(), c.A()}
+		}(), []float64{0.628, 0.225, 0.126, 0.5}},
+		{"lch", func() []float64 {
+			c := convert.RGBAToLCh(rgba.New(0, 0, 255, 255))
+			return []float64{c.L(), c.C(), c.H()}
+		}(), []float64{32.30, 133.81, 306.29}},
+		{"oklch", func() []float64 {
+			c := convert.RGBAToOKLCh(rgba.New(255, 0, 0, 255))
+			return []float64{c.L(), c.C(), c.H()}
+		}(), []float64{0.628, 0.2577, 29.23}},
+		{"ycbcr", func() []float64 {
+			c := convert.RGBAToYCbCr(rgba.New(255, 255, 255, 255))
+			return []float64{c.Y(), c.Cb(), c.Cr()}
+		}(), []float64{1, 0.5, 0.5}},
+		{"cmyk", func() []float64 {
+			c := convert.RGBAToCMYK(rgba.New(127.5, 0, 0, 255))
+			return []float64{c.C(), c.M(), c.Y(), c.K()}
+		}(), []float64{0, 1, 1, 0.5}},
+	}
+	for _, tt := range known {
+		if !near(tt.got, tt.want, 0.01) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+
+	for _, c := range []*rgba.RGBA{rgba.New(0, 0, 0, 255), rgba.New(255, 255, 255, 128), rgba.New(12, 200, 99, 255), rgba.New(250, 3, 180, 0)} {
+		want := []float64{c.R(), c.G(), c.B(), c.A()}
+		for name, got := range map[string]*rgba.RGBA{
+			"hsv":   convert.HSVAToRGBA(convert.RGBAToHSVA(c)),
+			"xyz":   convert.XYZToRGBA(convert.RGBAToXYZ(c)),
+			"lab":   convert.LabToRGBA(convert.RGBAToLab(c)),
+			"lch":   convert.LChToRGBA(convert.RGBAToLCh(c)),
+			"oklab": convert.OKLabToRGBA(convert.RGBAToOKLab(c)),
+			"oklch": convert.OKLChToRGBA(convert.RGBAToOKLCh(c)),
+			"ycbcr": convert.YCbCrToRGBA(convert.RGBAToYCbCr(c)),
+			"cmyk":  convert.CMYKToRGBA(convert.RGBAToCMYK(c)),
+		} {
+			if !near([]float64{got.R(), got.G(), got.B(), got.A()}, want, 0.01) {
+				t.Errorf("%s: expected %s to survive the round trip, got %s", name, c, got)
+			}
+		}
+	}
+
+	// shifting the hue in OKLCh keeps the perceived lightness, in HSL yellow turns into a much darker blue
+	lightness := func(i *image.Image) float64 {
+		c := i.GetRGBA(0, 0)
+		l, _, _ := convert.RGBToOKLab(c.R()/0xFF, c.G()/0xFF, c.B()/0xFF)
+		return l
+	}
+	yellow := *rgba.New(255, 255, 0, 255)
+	want := lightness(image.NewWithColor(1, 1, yellow))
+	if l := lightness(hue.Apply(image.NewWithColor(1, 1, yellow), 180, image.SPACE_OKLCH)); math.Abs(l-want) > 0.01 {
+		t.Errorf("oklch: expected lightness %.3f, got %.3f", want, l)
+	}
+	if l := lightness(hue.Apply(image.NewWithColor(1, 1, yellow), 180, image.SPACE_HSL)); math.Abs(l-want) < 0.3 {
+		t.Errorf("hsl: expected the lightness to change, got %.3f", l)
+	}
+	for _, space := range image.ColorSpaceNames() {
+		s := image.ParseColorSpace(space)
+		r, g, b := s.ToRGB(s.FromRGB(0.2, 0.6, 0.9))
+		if !near([]float64{r, g, b}, []float64{0.2, 0.6, 0.9}, 1e-6) {
+			t.Errorf("%s: expected the color to survive the round trip, got %.4f %.4f %.4f", space, r, g, b)
+		}
+	}
+
+	// positional args that leave out the space keep its default when the script is saved and loaded again
+	c, err := parser.ParseComposition("[FILTERS]\nfx { hue(30) color-shift(5 -0.05 0.01) lum(0.1) }\n[COMPOSITION]\nfilter = fx\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := parser.ParseComposition(c.String())
+	if err != nil || c2.String() != c.String() {
+		t.Fatalf("expected the filters to survive the round trip, got %v:\n%s", err, c.String())
+	}
+	if f := c2.Filter.Filters[0].String(true); f != "hue(shift=30 space=`hsl`)" {
+		t.Errorf("expected the default space, got %s", f)
+	}
+	if image.ParseColorSpace("RGB") != image.SPACE_RGB || image.ParseColorSpace("nope") != image.SPACE_HSL {
+		t.Errorf("expected rgb to parse and unknown spaces to fall back to hsl")
+	}
+	for _, f := range []string{"sat(0.5 `nope`)", "lum(0.1 `nope`)", "hue(30 `rgb`)"} {
+		if _, err := parser.ParseComposition("[FILTERS]\nfx { " + f + " }\n[COMPOSITION]\nfilter = fx\n"); err == nil {
+			t.Errorf("%s: expected an error", f)
+		}
+	}
+}
+
+func TestBlendSpace(t *testing.T) {
+	// half-transparent white over black is about 128 in sRGB but 188 (50% of the light) in linear space
+	for _, tt := range []struct {
+		space blend.BlendSpace
+		want  float64
+	}{{blend.SPACE_SRGB, 127.5}, {blend.SPACE_LINEAR, 187.5}} {
+		img := image.NewWithColor(1, 1, *rgba.New(0, 0, 0, 255))
+		img.DrawIn(image.NewWithColor(1, 1, *rgba.New(255, 255, 255, 255)), 0, 0, 1, 1, 0, 0, 1, 1, blend.NORMAL, 0.5, tt.space)
+		if c := img.GetRGBA(0, 0); math.Abs(c.R()-tt.want) > 1 {
+			t.Errorf("%s: expected %.1f, got %.1f", tt.space, tt.want, c.R())
+		}
+	}
+
+	c, err := parser.ParseComposition(`
+[FILTERS]
+inv { invert() }
+[COMPOSITION]
+width = 1
+height = 1
+blend-space = linear
+[LAYERS]
+normal 1.0 inv blend-space srgb pattern:checkerboard()
+normal 1.0 * pattern:checkerboard()
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BlendSpace != string(blend.SPACE_LINEAR) || c.Layers[0].BlendSpace != string(blend.SPACE_SRGB) || c.Layers[1].BlendSpace != "" {
+		t.Errorf("expected linear composition with an srgb layer, got %q, %q and %q", c.BlendSpace, c.Layers[0].BlendSpace, c.Layers[1].BlendSpace)
+	}
+	if c2, err := parser.ParseComposition(c.String()); err != nil || c2.String() != c.String() {
+		t.Errorf("expected the composition to survive the round trip, got %v:\n%s", err, c.String())
+	}
+
+	for _, bad := range []string{
+		"[COMPOSITION]\nblend-space = lineer\n",
+		"[LAYERS]\nnormal 1.0 * blend-space lineer pattern:checkerboard()\n",
+	} {
+		if _, err := parser.ParseComposition(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	// Draw blends on the raw buffer, the results must match blending every pixel as HSLA
+	const size = 24
+	pattern := func(shift int) *image.Image {
+		img := image.New(size, size)
+		for y := range size {
+			for x := range size {
+				v := float64((x*7+y*13+shift)%size) / size
+				img.SetRGBA(x, y, rgba.New(255*v, 255*float64(x)/size, 255*float64(y)/size, 255*float64((x+y+shift)%4)/3))
+			}
+		}
+		return img
+	}
+	dst, src := pattern(0), pattern(5)
+	modes := []blend.BlendMode{}
+	for mode := range blend.BlendModes {
+		if mode != blend.DISSOLVE {
+			modes = append(modes, mode)
+		}
+	}
+	for _, mode := range modes {
+		for _, op := range []blend.CompositeOp{"", blend.SRC_OVER, blend.SRC_ATOP, blend.XOR} {
+			for _, space := range []blend.BlendSpace{blend.SPACE_SRGB, blend.SPACE_LINEAR} {
+				// the source is drawn partially outside of the image
+				got := dst.Clone().DrawComposite(src, 0, 0, size, size, -4, 6, size, size, mode, op, 0.75, space)
+				for y := range size {
+					for x := range size {
+						want := dst.GetRGBA(x, y)
+						if x+4 < size && y >= 6 {
+							want = convert.HSLAToRGBA(blend.CompositeHSLAIn(dst.GetHSLA(x, y), src.GetHSLA(x+4, y-6), mode, op, 0.75, space))
+						}
+						g := got.GetRGBA(x, y)
+						if math.Abs(g.A()-want.A()) > 1 || (want.A() > 0 && (math.Abs(g.R()-want.R()) > 1 || math.Abs(g.G()-want.G()) > 1 || math.Abs(g.B()-want.B()) > 1)) {
+							t.Fatalf("%s %s %s at %d,%d: expected %s, got %s", mode, op, space, x, y, want, g)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCustomBlendModes(t *testing.T) {
+	for src, want := range map[string]float64{
+		"min(1, a + b*b)":            1,
+		"-2^2 + 2^3^2":               508,
+		"a < b ? mix(a, b, 0.5) : 0": 0.65,
+		"clamp(a*3, 0, 1) - abs(-b)": 0.2,
+		"max(a, b, 0.9) % 0.5":       0.4,
+	} {
+		e, err := expr.Compile(src, "a", "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := e.Eval([]float64{0.5, 0.8}); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: expected %f, got %f", src, want, got)
+		}
+	}
+
+	script := `
+[FILTERS]
+inv { invert() }
+[BLENDMODES]
+mult = a * b
+glow = min(1, a + b*b*alpha)
+[COMPOSITION]
+width = 64
+height = 64
+[LAYERS]
+%s 0.75 inv pattern:stripes(width=8)
+normal 1.0 * gradient:linear(hsla(0 1 0.5 1) hsla(240 1 0.5 1))
+`
+	render := func(mode string) (*parser.Composition, *image.Image) {
+		c, err := parser.ParseComposition(fmt.Sprintf(script, mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c, img
+	}
+	_, want := render("multiply")
+	c, got := render("mult")
+	for i, v := range got.Get().Pix {
+		if math.Abs(float64(v-want.Get().Pix[i])) > 1e-4 {
+			t.Fatalf("expected mult to match multiply, got %f instead of %f at %d", v, want.Get().Pix[i], i)
+		}
+	}
+	c2, err := parser.ParseComposition(c.String())
+	if err != nil || c2.String() != c.String() || c2.BlendModes["glow"] != "min(1, a + b*b*alpha)" {
+		t.Errorf("expected the blend modes to survive the round trip, got %v:\n%s", err, c.String())
+	}
+	_, img := render("glow")
+	img.SaveAsPNG("test_data/blendmode/custom-glow.png")
+
+	for _, bad := range []string{
+		"[BLENDMODES]\nmultiply = a * b",
+		"[BLENDMODES]\nglow = min(1, a + c)",
+		"[BLENDMODES]\nglow = min(1, a +",
+		"[LAYERS]\nglow 1.0 * pattern:stripes()",
+	} {
+		if _, err := parser.ParseComposition(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestExprFilter(t *testing.T) {
+	img := image.New(4, 4)
+	img.Get().SetPixel(1, 2, [4]float32{0.5, 0.25, 1, 1})
+	f, err := expression.Compile("r*0.8 + b*0.2", "", "", "x == 1 && y == 2 ? smoothstep(0.1, 0.3, l) : a", "", "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Apply(img).Get().PixelAt(1, 2); math.Abs(float64(got[0])-0.6) > 1e-6 || got[1] != 0.25 || got[2] != 1 || got[3] != 1 {
+		t.Errorf("expected (0.6, 0.25, 1, 1), got %v", got)
+	}
+	// h, s and l are applied after r, g and b
+	f, _ = expression.Compile("", "", "", "", "h + 120", "1", "0.5", 0)
+	if got := f.Apply(image.New(1, 1).FillHSLA(0, 0, 1, 1, hsla.New(0, 1, 0.5, 1))).Get().PixelAt(0, 0); got[0] > 1e-6 || got[1] < 1-1e-6 {
+		t.Errorf("expected the hue to turn green, got %v", got)
+	}
+	if _, err := expression.Apply(image.New(1, 1), "r +", "", "", "", "", "", "", 0); err == nil {
+		t.Errorf("expected Apply to return the compile error")
+	}
+
+	script := `
+[VARS]
+k = 0.8
+[FILTERS]
+fx { expr(r=` + "`r*k + b*(1-k)`" + ` a=` + "`smoothstep(0.1, 0.3, l) * noise(x/8, y/8)`" + `) }
+[COMPOSITION]
+width = 64
+height = 64
+[LAYERS]
+normal 1.0 fx gradient:linear(hsla(0 1 0.5 1) hsla(240 1 0.5 1))
+`
+	c, err := parser.ParseComposition(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Layers[0].Filter.Filters[0].Options["r"]; got != "r*(0.8) + b*(1-(0.8))" {
+		t.Errorf("expected the vars to be replaced, got %v", got)
+	}
+	c2, err := parser.ParseComposition(c.String())
+	if err != nil || c2.String() != c.String() {
+		t.Errorf("expected the filter to survive the round trip, got %v:\n%s", err, c.String())
+	}
+	res, err := c.Render(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.SaveAsPNG("test_data/main/expr.png")
+
+	for _, bad := range []string{
+		"[FILTERS]\nfx { expr(r=`r + q`) }",
+		"[FILTERS]\nfx { expr(r=`noise(x)`) }",
+		"[FILTERS]\nfx { expr(`r +`) }",
+		"[FILTERS]\nfx { expr(r=\"r*0.8\") }",
+	} {
+		if _, err := parser.ParseComposition(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+	// filters that weren't parsed are checked when they are applied
+	if _, err := parser.NewImageFilter(expression.Meta.Name, map[string]any{"r": "r +"}).ApplyContext(context.Background(), image.New(4, 4)); err == nil {
+		t.Error("expected an error for an invalid expression")
+	}
+}
+
+func TestBandMath(t *testing.T) {
+	dir := t.TempDir()
+	image.NewWithColor(16, 16, *rgba.New(153, 153, 153, 255)).SaveAsPNG(dir + "/171.png")
+	image.NewWithColor(8, 8, *rgba.New(51, 51, 51, 255)).SaveAsPNG(dir + "/195.png")
+	ramp := "gradient:linear(hsla(0 0 0 1) hsla(0 0 1 1))"
+	render := func(src string) (*parser.Composition, *image.Image) {
+		c, err := parser.ParseComposition("[COMPOSITION]\nwidth = 32\nheight = 32\n[LAYERS]\nnormal 1.0 * " + src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c, img
+	}
+
+	// (0.6 - 0.2) / (0.6 + 0.2) = 0.5, stretched from [-1, 1] to [0, 1]
+	c, img := render("bands:range(expr=`(a-b)/(a+b)` a=" + dir + "/171.png b=" + dir + "/195.png min=-1 max=1)")
+	if px := img.Get().PixelAt(20, 20); math.Abs(float64(px[0])-0.75) > 1.0/255 || px[3] != 1 {
+		t.Errorf("range: expected 0.75, got %v", px)
+	}
+	if c2, err := parser.ParseComposition(c.String()); err != nil || c2.String() != c.String() {
+		t.Errorf("expected the layer to survive the round trip, got %v:\n%s", err, c.String())
+	}
+	// auto stretches the results back to [0, 1], without normalization they are clipped
+	_, img = render("bands:auto(expr=a*2+5 a=" + ramp + ")")
+	if l, r := img.Get().PixelAt(0, 0), img.Get().PixelAt(31, 0); l[0] > 1e-6 || r[0] < 1-1e-6 {
+		t.Errorf("auto: expected a ramp from 0 to 1, got %v and %v", l, r)
+	}
+	_, img = render("bands:none(expr=a*2 a=" + ramp + ")")
+	if px := img.Get().PixelAt(20, 0); px[0] != 1 {
+		t.Errorf("none: expected the result to be clipped, got %v", px)
+	}
+	// a division by zero makes the pixel transparent
+	_, img = render("bands:none(expr=`1/floor(b*2)` b=" + ramp + ")")
+	if l, r := img.Get().PixelAt(0, 0), img.Get().PixelAt(31, 0); l[3] != 0 || r[3] != 1 {
+		t.Errorf("expected only the left half to be transparent, got %v and %v", l, r)
+	}
+
+	for _, bad := range []string{
+		"bands:median(expr=a a=" + ramp + ")",
+		"bands:auto(a=" + ramp + ")",
+		"bands:auto(expr=a+c a=" + ramp + ")",
+		"bands:auto(expr=a " + ramp + ")",
+		"bands:auto(expr=a a=" + ramp + " clip=none)",
+		"bands:auto(expr=a a=gradient:diagonal(hsla(0 0 0 1)))",
+	} {
+		if _, err := parser.ParseComposition("[LAYERS]\nnormal 1.0 * " + bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestComponentBlendModes(t *testing.T) {
+	gray, red := rgba.New(128, 128, 128, 255), rgba.New(255, 0, 0, 255)
+	lum := func(c *rgba.RGBA) float64 { return 0.3*c.R() + 0.59*c.G() + 0.11*c.B() }
+
+	// color keeps the luminosity of the gray base and takes the hue of the red blend color
+	c := blend.RGBA(gray, red, blend.COLOR, 1.0)
+	if math.Abs(lum(c)-128) > 1 || c.R() <= c.G() || c.G() != c.B() {
+		t.Errorf("color: expected a red with the luminosity of the base, got %s", c)
+	}
+	// luminosity is the reverse, a gray base stays gray
+	if c := blend.RGBA(gray, red, blend.LUMINOSITY, 1.0); c.R() != c.G() || math.Abs(lum(c)-lum(red)) > 1 {
+		t.Errorf("luminosity: expected a gray with the luminosity of the blend color, got %s", c)
+	}
+	if c := blend.RGBA(gray, red, blend.SATURATION, 1.0); c.R() != c.G() || c.G() != c.B() {
+		t.Errorf("saturation: expected a gray base to stay gray, got %s", c)
+	}
+	if c := blend.RGBA(gray, red, blend.LIGHTER_COLOR, 1.0); *c != *gray {
+		t.Errorf("lighter-color: expected %s, got %s", gray, c)
+	}
+	if c := blend.RGBA(gray, red, blend.HARD_MIX, 1.0); *c != *red {
+		t.Errorf("hard-mix: expected %s, got %s", red, c)
+	}
+
+	// dissolve passes about alpha of the pixels and the same seed dissolves the same pixels
+	dissolve := func(seed uint64) *image.Image {
+		return image.NewWithColor(100, 100, *gray).DrawDissolve(image.NewWithColor(100, 100, *red), 0, 0, 100, 100, 0, 0, 100, 100, "", 0.25, blend.SPACE_SRGB, seed)
+	}
+	img := dissolve(42)
+	n := 0
+	for y := range 100 {
+		for x := range 100 {
+			switch c := img.GetRGBA(x, y); *c {
+			case *red:
+				n++
+			case *gray:
+			default:
+				t.Fatalf("dissolve: expected only base and blend colors, got %s", c)
+			}
+		}
+	}
+	if n < 2200 || n > 2800 {
+		t.Errorf("dissolve: expected about 2500 pixels of the blend color, got %d", n)
+	}
+	if !slices.Equal(img.Get().Pix, dissolve(42).Get().Pix) {
+		t.Errorf("dissolve: expected the same seed to dissolve the same pixels")
+	}
+	if slices.Equal(img.Get().Pix, dissolve(43).Get().Pix) {
+		t.Errorf("dissolve: expected another seed to dissolve other pixels")
+	}
+
+	// layers set the seed in the script
+	script := "[COMPOSITION]\nwidth = 32\nheight = 32\n[LAYERS]\ndissolve 0.5 * seed %d pattern:stripes(width=4 angle=45)\nnormal 1.0 * pattern:checkerboard()\n"
+	render := func(seed int) *image.Image {
+		c, err := parser.ParseComposition(fmt.Sprintf(script, seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c2, err := parser.ParseComposition(c.String()); err != nil || c2.String() != c.String() || c2.Layers[0].Seed != uint64(seed) {
+			t.Errorf("dissolve: expected the seed to survive the round trip, got %v:\n%s", err, c.String())
+		}
+		res, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	if slices.Equal(render(1).Get().Pix, render(2).Get().Pix) {
+		t.Errorf("dissolve: expected layers with other seeds to dissolve other pixels")
+	}
+	if _, err := parser.ParseComposition(fmt.Sprintf(script, -1)); err == nil {
+		t.Errorf("dissolve: expected an error for a negative seed")
+	}
+}
+
+func TestGradient(t *testing.T) {
+	black, white := hsla.New(0, 0.0, 0.0, 1.0), hsla.New(0, 0.0, 1.0, 1.0)
+	red, blue := hsla.New(0, 1.0, 0.5, 1.0), hsla.New(240, 1.0, 0.5, 1.0)
+
+	img := image.NewGradient(image.INTERPOLATE_RGB, image.GradientStop{Position: 0, Color: black}, image.GradientStop{Position: 1, Color: white}).Linear(4, 1, 0, 0, 4, 0)
+	for x := range 4 {
+		if r, want := img.GetRGBA(x, 0).R(), (float64(x)+0.5)/4*0xFF; math.Abs(r-want) > 1 {
+			t.Errorf("linear: expected red %.1f at x=%d, got %.1f", want, x, r)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		interpolation image.Interpolation
+		c1, c2        *hsla.HSLA
+		want          *hsla.HSLA // color at 0.5
+	}{
+		{"hsl takes the shorter way around", image.INTERPOLATE_HSL, red, blue, hsla.New(300, 1.0, 0.5, 1.0)},
+		{"oklab is perceptually even", image.INTERPOLATE_OKLAB, black, white, hsla.New(0, 0.0, 0.389, 1.0)},
+		{"rgb fades to transparent without darkening", image.INTERPOLATE_RGB, red, hsla.New(240, 1.0, 0.5, 0.0), hsla.New(0, 1.0, 0.5, 0.5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := image.NewGradient(tt.interpolation, image.GradientStop{Position: 0, Color: tt.c1}, image.GradientStop{Position: 1, Color: tt.c2})
+			c := g.At(0.5)
+			if (tt.want.S() > 0 && math.Abs(c.H()-tt.want.H()) > 1) || math.Abs(c.S()-tt.want.S()) > 0.01 || math.Abs(c.L()-tt.want.L()) > 0.01 || math.Abs(c.A()-tt.want.A()) > 0.01 {
+				t.Errorf("expected %s, got %s", tt.want, c)
+			}
+		})
+	}
+
+	radial := image.NewGradient(image.INTERPOLATE_RGB, image.GradientStop{Position: 0, Color: white}, image.GradientStop{Position: 1, Color: black}).Radial(64, 64, 32, 32, 16)
+	if r := radial.GetRGBA(32, 32).R(); r < 0xF0 {
+		t.Errorf("radial: expected the center to be white, got red %.1f", r)
+	}
+	if r := radial.GetRGBA(0, 0).R(); r != 0 {
+		t.Errorf("radial: expected the corners to be black, got red %.1f", r)
+	}
+}
+
+func TestNoise(t *testing.T) {
+	for _, typ := range image.NoiseTypes() {
+		t.Run(typ, func(t *testing.T) {
+			scale := 0.05
+			if typ == string(image.NOISE_WHITE) {
+				scale = 1
+			}
+			a := image.NewNoise(64, 64, image.NoiseType(typ), scale, 3, 0.5, 7)
+			if !slices.Equal(a.Get().Pix, image.NewNoise(64, 64, image.NoiseType(typ), scale, 3, 0.5, 7).Get().Pix) {
+				t.Error("expected the same seed to give the same noise")
+			}
+			if slices.Equal(a.Get().Pix, image.NewNoise(64, 64, image.NoiseType(typ), scale, 3, 0.5, 8).Get().Pix) {
+				t.Error("expected different seeds to give different noise")
+			}
+			if s := a.Stats(image.CHANNEL_R, nil); s.Max-s.Min < 0.5 || math.Abs(s.Mean-0.5) > 0.15 {
+				t.Errorf("expected noise around 0.5, got values from %.2f to %.2f with mean %.2f", s.Min, s.Max, s.Mean)
+			}
+			a.SaveAsPNG("test_data/resample/noise-" + typ + ".png")
+		})
+	}
+
+	white, black := hsla.New(0, 0.0, 1.0, 1.0), hsla.New(0, 0.0, 0.0, 1.0)
+	patterns := []struct {
+		name string
+		img  *image.Image
+		want map[[2]int]float64 // red values at (x, y)
+	}{
+		{"checkerboard", image.NewCheckerboard(32, 32, 8, white, black), map[[2]int]float64{{0, 0}: 0xFF, {8, 0}: 0, {8, 8}: 0xFF, {0, 15}: 0}},
+		{"stripes", image.NewStripes(32, 32, 4, 90, white, black), map[[2]int]float64{{31, 0}: 0xFF, {0, 4}: 0, {0, 8}: 0xFF}},
+		{"grid", image.NewGrid(32, 32, 16, 2, white, black), map[[2]int]float64{{0, 5}: 0xFF, {1, 5}: 0xFF, {2, 5}: 0, {5, 17}: 0xFF, {5, 18}: 0}},
+	}
+	for _, tt := range patterns {
+		for p, want := range tt.want {
+			if r := tt.img.GetRGBA(p[0], p[1]).R(); math.Abs(r-want) > 1 {
+				t.Errorf("%s: expected red %.0f at %v, got %.0f", tt.name, want, p, r)
+			}
+		}
+	}
 }
 
 func TestTextDrawing(t *testing.T) {
@@ -437,6 +1519,267 @@ func TestTextDrawing(t *testing.T) {
 	}
 }
 
+func TestTextLayout(t *testing.T) {
+	measure := []struct {
+		box      image.TextBox
+		text     string
+		maxWidth int
+		w, h     int
+	}{
+		{image.TextBox{}, "Hello", 0, 45, 10},
+		{image.TextBox{}, "ab\nabcd", 0, 36, 20},
+		{image.TextBox{}, "[:r:]ab[::]c", 0, 27, 10},
+		{image.TextBox{Padding: 2}, "ab", 0, 22, 14},
+		{image.TextBox{Wrap: true}, "aaa bbb ccc", 72, 63, 20},
+		{image.TextBox{Wrap: true}, "aaaaaa", 36, 36, 20},
+		{image.TextBox{Wrap: true, Padding: 2}, "aaa bbb", 40, 31, 24},
+	}
+	for _, tt := range measure {
+		if w, h := tt.box.Measure(tt.text, tt.maxWidth); w != tt.w || h != tt.h {
+			t.Errorf("%q: expected %dx%d, got %dx%d", tt.text, tt.w, tt.h, w, h)
+		}
+	}
+
+	bg := *rgba.New(0x00, 0x33, 0x33, 0xFF)
+	col := *hsla.New(30, 1.0, 0.5, 1.0)
+	columns := func(i *image.Image) (first, last int) {
+		first, last = -1, -1
+		for x := range i.W() {
+			for y := range i.H() {
+				if *i.GetRGBA(x, y) != bg {
+					if first < 0 {
+						first = x
+					}
+					last = x
+					break
+				}
+			}
+		}
+		return first, last
+	}
+	align := []struct {
+		align       image.TextAlign
+		first, last int
+	}{
+		{image.ALIGN_LEFT, 1, 79},
+		{image.ALIGN_CENTER, 10, 88},
+		{image.ALIGN_RIGHT, 19, 97},
+		{image.ALIGN_JUSTIFY, 1, 97},
+	}
+	for _, tt := range align {
+		i := image.NewWithColor(99, 20, bg)
+		i.DrawTextBox(image.TextBox{Wrap: true, Align: tt.align}, "a a a a b bbbbbbbbb", 0, 0, 99, 20, col, false, blend.NORMAL)
+		if first, last := columns(i); first != tt.first || last != tt.last {
+			t.Errorf("%s: expected text from x=%d to %d, got %d to %d", tt.align, tt.first, tt.last, first, last)
+		}
+	}
+
+	i := image.NewWithColor(200, 120, bg)
+	i.DrawTextBox(image.TextBox{
+		Wrap: true, Align: image.ALIGN_JUSTIFY, VAlign: image.VALIGN_MIDDLE, Padding: 4, Background: hsla.New(0, 0, 0.15, 1),
+	}, "The quick brown fox [:r:]jumps[::] over the lazy dog.\nPack my box with five dozen liquor jugs.", 4, 4, 120, 112, col, false, blend.NORMAL)
+	w, h := dialogbox.Size("Dialog", image.New(40, 20))
+	if w != 62 || h != 42 {
+		t.Errorf("expected a dialog box of 62x42, got %dx%d", w, h)
+	}
+	dialogbox.Draw(i, 128, 4, 0, 0, "Dialog", image.NewWithColor(40, 20, *rgba.New(0xFF, 0x80, 0x00, 0xFF)), blend.NORMAL)
+	i.SaveAsPNG("test_data/text/Layout.png")
+}
+
+func TestVectorText(t *testing.T) {
+	if _, err := image.NewFont([]byte("no font"), 16); err == nil {
+		t.Error("expected an error for invalid font data")
+	}
+	small, err := image.NewFont(goregular.TTF, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, _ := image.NewFont(goregular.TTF, 24)
+	if _, _, _, a1 := small.Glyph('W'); a1 <= 0 {
+		t.Errorf("expected a positive advance, got %.2f", a1)
+	} else if _, _, _, a2 := large.Glyph('W'); math.Abs(a2-2*a1) > 0.1 {
+		t.Errorf("expected the advance to scale with the size, got %.2f and %.2f", a1, a2)
+	}
+	if small.SetLineHeight(20).LineHeight() != 20 || small.SetLineHeight(0).LineHeight() <= 12 {
+		t.Error("expected the line height to be set and restored")
+	}
+
+	glyph, _, _, _ := large.Glyph('Ä')
+	partial := false
+	for y := range glyph.H() {
+		for x := range glyph.W() {
+			if a := glyph.GetRGBA(x, y).A(); a > 0 && a < 0xFF {
+				partial = true
+			}
+		}
+	}
+	if !partial {
+		t.Error("expected anti-aliased glyph edges")
+	}
+
+	i := image.NewWithColor(200, 64, *rgba.New(0x00, 0x33, 0x33, 0xFF))
+	i.DrawTextWith(large, "Grüße [:r:]AV[::] ☃\n[:gon:]Glow", 4, 2, *hsla.New(30, 1.0, 0.5, 1.0), false, blend.NORMAL).SaveAsPNG("test_data/text/Vector.png")
+	drawn := false
+	for x := range 40 {
+		if *i.GetRGBA(4+x, 45) != *i.GetRGBA(199, 63) {
+			drawn = true
+		}
+	}
+	if !drawn {
+		t.Error("expected the second line to be drawn")
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	img := image.NewWithColor(10, 10, *rgba.New(0, 0, 0, 0xFF))
+	img.FillRGBA(0, 0, 5, 10, rgba.New(0xFF, 0xFF, 0xFF, 0xFF)) // left half white, right half black
+	mask := image.New(10, 10)
+	mask.FillRGBA(0, 0, 10, 2, rgba.New(0, 0, 0, 0xFF)) // top two rows
+
+	tests := []struct {
+		name     string
+		ch       image.Channel
+		sel      *image.Selection
+		count    int
+		mean     float64
+		stddev   float64
+		median   float64
+		p90      float64
+		histLast int
+	}{
+		{"all", image.CHANNEL_L, nil, 100, 0.5, 0.5, 0.5, 1, 50},
+		{"region", image.CHANNEL_R, &image.Selection{X: 3, Y: 0, W: 4, H: 10}, 40, 0.5, 0.5, 0.5, 1, 20},
+		{"region-left", image.CHANNEL_G, &image.Selection{X: 0, Y: 0, W: 5, H: 10}, 50, 1, 0, 1, 1, 50},
+		{"mask", image.CHANNEL_B, &image.Selection{Mask: mask}, 20, 0.5, 0.5, 0.5, 1, 10},
+		{"alpha", image.CHANNEL_A, nil, 100, 1, 0, 1, 1, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := img.Stats(tt.ch, tt.sel)
+			h := img.Histogram(tt.ch, 4, tt.sel)
+			if s.Count != tt.count || h.Count != tt.count {
+				t.Errorf("expected %d pixels, got %d (stats) and %d (histogram)", tt.count, s.Count, h.Count)
+			}
+			if s.Mean != tt.mean || s.StdDev != tt.stddev || s.Median != tt.median || s.Percentile(90) != tt.p90 {
+				t.Errorf("expected mean %f, stddev %f, median %f, p90 %f, got %f, %f, %f, %f",
+					tt.mean, tt.stddev, tt.median, tt.p90, s.Mean, s.StdDev, s.Median, s.Percentile(90))
+			}
+			if h.Bins[3] != tt.histLast {
+				t.Errorf("expected %d pixels in the last bin, got %d", tt.histLast, h.Bins[3])
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	img := image.New(4, 4)
+	var unknown *parser.UnknownFilterError
+	if _, err := parser.NewImageFilter("no-such-filter", nil).ApplyContext(context.Background(), img); !errors.As(err, &unknown) {
+		t.Errorf("expected an UnknownFilterError, got %v", err)
+	}
+	var arg *parser.ArgumentError
+	if _, err := parser.NewImageFilter(convolution.Meta.Name, map[string]any{"matrix": []float64{1, 2, 3}}).ApplyContext(context.Background(), img); !errors.As(err, &arg) {
+		t.Errorf("expected an ArgumentError, got %v", err)
+	}
+}
+
+func TestPointFilters(t *testing.T) {
+	// hue changes from left to right, saturation and lightness from top to bottom
+	src := image.New(36, 24)
+	for y := range 24 {
+		for x := range 36 {
+			r, g, b := convert.HSLToRGB(float64(x)*10, 0.1+float64(y%4)*0.3, 0.05+float64(y)*0.038)
+			src.Get().SetPixel(x, y, [4]float32{float32(r), float32(g), float32(b), 1})
+		}
+	}
+	rgb := func(fn func(r, g, b float64) (float64, float64, float64)) func(i *image.Image) *image.Image {
+		return func(i *image.Image) *image.Image {
+			return i.ProcessRGBA(0, 0, i.W(), i.H(), func(x, y int, col *rgba.RGBA) (int, int, *rgba.RGBA) {
+				r, g, b := fn(col.R(), col.G(), col.B())
+				return x, y, rgba.New(r, g, b, col.A())
+			})
+		}
+	}
+	hsl := func(fn func(col *hsla.HSLA) *hsla.HSLA) func(i *image.Image) *image.Image {
+		return func(i *image.Image) *image.Image {
+			return i.ProcessHSLA(0, 0, i.W(), i.H(), func(x, y int, col *hsla.HSLA) (int, int, *hsla.HSLA) { return x, y, fn(col) })
+		}
+	}
+	luma := func(r, g, b float64) float64 { return 0.299*r + 0.587*g + 0.114*b }
+	// the filters as they were implemented before they were ported to ProcessPixels (values in the range [0, 255])
+	tests := []struct {
+		filter  string
+		options map[string]any
+		want    func(i *image.Image) *image.Image
+	}{
+		{brightness.Meta.Name, map[string]any{"adjustment": 0.2}, rgb(func(r, g, b float64) (float64, float64, float64) {
+			return math.Clamp(r+0.2*255, 0, 255), math.Clamp(g+0.2*255, 0, 255), math.Clamp(b+0.2*255, 0, 255)
+		})},
+		{colorshift.Meta.Name, map[string]any{"hue": 40.0, "sat": -0.2, "lum": 0.1}, hsl(func(col *hsla.HSLA) *hsla.HSLA { return col.Shift(40, -0.2, 0.1, 0) })},
+		{contrast.Meta.Name, map[string]any{"adjustment": 0.3}, rgb(func(r, g, b float64) (float64, float64, float64) {
+			f := (259 * 1.3) / (255 * 0.7)
+			adjust := func(v float64) float64 { return math.Clamp(f*(v-128)+128, 0, 255) }
+			return adjust(r), adjust(g), adjust(b)
+		})},
+		{gamma.Meta.Name, map[string]any{"adjustment": 0.5}, rgb(func(r, g, b float64) (float64, float64, float64) {
+			adjust := func(v float64) float64 { return math.Min(255, math.Pow(v/255, 1/1.5)*255) }
+			return adjust(r), adjust(g), adjust(b)
+		})},
+		{gray.Meta.Name, nil, rgb(func(r, g, b float64) (float64, float64, float64) {
+			return luma(r, g, b), luma(r, g, b), luma(r, g, b)
+		})},
+		{hue.Meta.Name, map[string]any{"shift": 75.0}, hsl(func(col *hsla.HSLA) *hsla.HSLA { return col.ShiftH(75) })},
+		{huecontrast.Meta.Name, map[string]any{"adjustment": 0.4}, hsl(func(col *hsla.HSLA) *hsla.HSLA {
+			return col.SetH(math.Clamp(0.5+(col.H()/360-0.5)*0.6, 0, 1) * 360)
+		})},
+		{invert.Meta.Name, nil, rgb(func(r, g, b float64) (float64, float64, float64) { return 255 - r, 255 - g, 255 - b })},
+		{lum.Meta.Name, map[string]any{"shift": 0.1}, rgb(func(r, g, b float64) (float64, float64, float64) {
+			f := math.Clamp(luma(r, g, b)+0.1*255, 0, 255) / luma(r, g, b)
+			return math.Clamp(r*f, 0, 255), math.Clamp(g*f, 0, 255), math.Clamp(b*f, 0, 255)
+		})},
+		{lumcontrast.Meta.Name, map[string]any{"adjustment": 0.5}, hsl(func(col *hsla.HSLA) *hsla.HSLA {
+			return col.SetL(math.Clamp(0.5+(col.L()-0.5)*1.5, 0, 1))
+		})},
+		{pastelize.Meta.Name, nil, hsl(func(col *hsla.HSLA) *hsla.HSLA { return col.SetS(col.S() * 0.5).SetL(col.L() + (1-col.L())*0.2) })},
+		{sat.Meta.Name, map[string]any{"shift": -0.4}, rgb(func(r, g, b float64) (float64, float64, float64) {
+			adjust := func(v float64) float64 { return math.Clamp(luma(r, g, b)+0.6*(v-luma(r, g, b)), 0, 255) }
+			return adjust(r), adjust(g), adjust(b)
+		})},
+		{satcontrast.Meta.Name, map[string]any{"adjustment": -0.5}, hsl(func(col *hsla.HSLA) *hsla.HSLA {
+			return col.SetS(math.Clamp(0.5+(col.S()-0.5)*1.5, 0, 1))
+		})},
+		{sepia.Meta.Name, nil, rgb(func(r, g, b float64) (float64, float64, float64) {
+			return math.Min(255, 0.393*r+0.769*g+0.189*b), math.Min(255, 0.349*r+0.686*g+0.168*b), math.Min(255, 0.272*r+0.534*g+0.131*b)
+		})},
+		{threshold.Meta.Name, map[string]any{"amount": 0.5}, func(i *image.Image) *image.Image {
+			return i.ProcessRGBA(0, 0, i.W(), i.H(), func(x, y int, col *rgba.RGBA) (int, int, *rgba.RGBA) {
+				if luma(col.R(), col.G(), col.B()) > 0.5*255 {
+					return x, y, col
+				}
+				return x, y, col.SetA(0)
+			})
+		}},
+		{vibrance.Meta.Name, map[string]any{"adjustment": 0.6}, hsl(func(col *hsla.HSLA) *hsla.HSLA {
+			return col.SetS(math.Min(1, col.S()*(1+0.6*(1-col.S()))))
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, want := parser.NewImageFilter(tt.filter, tt.options).Apply(src.Clone()).Get(), tt.want(src.Clone()).Get()
+			for y := range 24 {
+				for x := range 36 {
+					g, w := got.PixelAt(x, y), want.PixelAt(x, y)
+					for c := range 4 {
+						if math.Abs(g[c]-w[c]) > 1.0/255 && (c == 3 || w[3] != 0) {
+							t.Fatalf("pixel %d,%d: expected %v, got %v", x, y, w, g)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestFilters(t *testing.T) {
 	var (
 		testImage    = image.NewFromURL("https://sdo.gsfc.nasa.gov/assets/img/latest/f_211_193_171pfss_512.jpg")
@@ -776,6 +2119,9 @@ func TestFilters(t *testing.T) {
 			{"1.00", convolution.Meta.Name, map[string]any{"matrix": customFilter, "bias": 0.00, "factor": 1.00}},
 		},
 	}
+	if testImage == nil {
+		t.Skip("test image could not be loaded")
+	}
 	for k, tests := range testGroups {
 		for i, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -808,4 +2154,66 @@ func TestColorBlending(t *testing.T) {
 			fmt.Printf("blend.RGBA({%s}, {%s}, normal, 1) = %s\n", tt.c1, tt.c2, blend.RGBA(tt.c1, tt.c2, blend.NORMAL, 1.0))
 		})
 	}
+	// half-transparent red onto opaque blue, and multiply onto transparent and opaque yellow
+	blue, red, yellow := rgba.New(0, 0, 0xFF, 0xFF), rgba.New(0xFF, 0, 0, 0x7F), rgba.New(0xFF, 0xFF, 0, 0xFF)
+	clear := rgba.New(0, 0, 0, 0)
+	ops := []struct {
+		c1, c2 *rgba.RGBA
+		mode   blend.BlendMode
+		op     blend.CompositeOp
+		want   *rgba.RGBA
+	}{
+		{blue, red, blend.NORMAL, blend.SRC_OVER, rgba.New(0x7F, 0, 0x80, 0xFF)},
+		{blue, red, blend.NORMAL, blend.DST_OVER, blue},
+		{blue, red, blend.NORMAL, blend.SRC_IN, red},
+		{blue, red, blend.NORMAL, blend.DST_IN, rgba.New(0, 0, 0xFF, 0x7F)},
+		{blue, red, blend.NORMAL, blend.SRC_OUT, clear},
+		{blue, red, blend.NORMAL, blend.DST_OUT, rgba.New(0, 0, 0xFF, 0x80)},
+		{blue, red, blend.NORMAL, blend.SRC_ATOP, rgba.New(0x7F, 0, 0x80, 0xFF)},
+		{blue, red, blend.NORMAL, blend.DST_ATOP, rgba.New(0, 0, 0xFF, 0x7F)},
+		{blue, red, blend.NORMAL, blend.XOR, rgba.New(0, 0, 0xFF, 0x80)},
+		{blue, red, blend.NORMAL, blend.CLEAR, clear},
+		{blue, red, blend.ERASE, blend.SRC_OVER, rgba.New(0, 0, 0xFF, 0x80)},
+		{yellow, red, blend.MULTIPLY, blend.SRC_ATOP, rgba.New(0xFF, 0x80, 0, 0xFF)},
+		{clear, red, blend.MULTIPLY, blend.SRC_ATOP, clear},
+		{clear, red, blend.MULTIPLY, blend.SRC_OVER, red},
+	}
+	for _, tt := range ops {
+		got := blend.Composite(rgba.New(tt.c1.R(), tt.c1.G(), tt.c1.B(), tt.c1.A()), rgba.New(tt.c2.R(), tt.c2.G(), tt.c2.B(), tt.c2.A()), tt.mode, tt.op, 1.0)
+		for i, v := range []float64{got.R() - tt.want.R(), got.G() - tt.want.G(), got.B() - tt.want.B(), got.A() - tt.want.A()} {
+			if math.Abs(v) > 1 {
+				t.Errorf("%s %s: expected %s, got %s (channel %d)", tt.mode, tt.op, tt.want, got, i)
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkFilters(b *testing.B) {
+	src := makeTestImage(512, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	names := []string{}
+	for name := range *parser.Filters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			f := parser.NewImageFilter(name, map[string]any{})
+			for range b.N {
+				f.Apply(src.Clone())
+			}
+		})
+	}
+}
+
+func BenchmarkDraw(b *testing.B) {
+	dst := makeTestImage(512, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 0)
+	src := makeTestImage(512, 0, 90, 0, 0.5, 0.5, 0.0, 0.5, 0.5, 0.0, 180)
+	for _, mode := range []blend.BlendMode{blend.NORMAL, blend.MULTIPLY, blend.COLOR} {
+		b.Run(string(mode), func(b *testing.B) {
+			for range b.N {
+				dst.Draw(src, 0, 0, 512, 512, 0, 0, 512, 512, mode, 0.5)
+			}
+		})
+	}
 }
@@ -0,0 +1,513 @@
+// Package expr compiles arithmetic expressions like `min(1, a + b*b)` to functions of named variables.
+//
+// Expressions support numbers, variables, the constants pi and e, parentheses, the operators
+// + - * / % ^ (power), comparisons (< <= > >= == !=), logic (&& || !) and the ternary operator c ? x : y.
+// Comparisons and logic return 1 for true and 0 for false, every value other than 0 is true.
+// Available functions are listed by Functions.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is returned when an expression can't be compiled.
+type SyntaxError struct {
+	Expr string // The offending expression.
+	Pos  int    // Position (starting at 0) in the expression where the error was found.
+	Msg  string // What went wrong.
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d in `%s`: %s", e.Pos, e.Expr, e.Msg)
+}
+
+// Expr is a compiled expression.
+type Expr struct {
+	src  string
+	vars []string
+	eval func(v []float64) float64
+}
+
+// Funcs are additional functions for CompileFuncs, a value must be a func(float64) float64,
+// func(float64, float64) float64 or func(float64, float64, float64) float64.
+// They can't replace the built-in functions.
+type Funcs map[string]any
+
+// Compile compiles src, the expression can use the given variables.
+func Compile(src string, vars ...string) (*Expr, error) {
+	return CompileFuncs(src, nil, vars...)
+}
+
+// CompileFuncs is like Compile but the expression can also call funcs.
+func CompileFuncs(src string, funcs Funcs, vars ...string) (*Expr, error) {
+	p := &parser{src: src, vars: vars, funcs: funcs}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	eval, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return &Expr{src: src, vars: vars, eval: eval}, nil
+}
+
+// Eval evaluates the expression, v holds the values of the variables in the order they were passed to Compile.
+func (e *Expr) Eval(v []float64) float64 {
+	return e.eval(v)
+}
+
+// Vars returns the variables the expression can use.
+func (e *Expr) Vars() []string {
+	return slices.Clone(e.vars)
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Functions returns the names of all functions expressions can call.
+func Functions() []string {
+	names := []string{"min", "max"}
+	for name := range funcs1 {
+		names = append(names, name)
+	}
+	for name := range funcs2 {
+		names = append(names, name)
+	}
+	for name := range funcs3 {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+var funcs1 = map[string]func(x float64) float64{
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+	"exp":   math.Exp,
+	"log":   math.Log,
+	"log2":  math.Log2,
+	"log10": math.Log10,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+	"trunc": math.Trunc,
+	"sign": func(x float64) float64 {
+		switch {
+		case x > 0:
+			return 1
+		case x < 0:
+			return -1
+		}
+		return 0
+	},
+}
+
+var funcs2 = map[string]func(x, y float64) float64{
+	"pow":   math.Pow,
+	"atan2": math.Atan2,
+	"hypot": math.Hypot,
+	"mod":   math.Mod,
+	"step": func(edge, x float64) float64 {
+		if x < edge {
+			return 0
+		}
+		return 1
+	},
+}
+
+var funcs3 = map[string]func(x, y, z float64) float64{
+	"clamp": func(x, lo, hi float64) float64 { return math.Max(lo, math.Min(hi, x)) },
+	"mix":   func(x, y, t float64) float64 { return x*(1-t) + y*t },
+	"smoothstep": func(e0, e1, x float64) float64 {
+		t := math.Max(0, math.Min(1, (x-e0)/(e1-e0)))
+		return t * t * (3 - 2*t)
+	},
+}
+
+// truth converts a bool to 1 or 0.
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+type parser struct {
+	src   string
+	vars  []string
+	funcs Funcs
+	pos   int
+	tok   token
+}
+
+type evalFunc = func(v []float64) float64
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Expr: p.src, Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next reads the next token.
+func (p *parser) next() error {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.src) && (p.src[end] == '+' || p.src[end] == '-') {
+				end++
+			}
+			if end < len(p.src) && isDigit(p.src[end]) {
+				for p.pos = end; p.pos < len(p.src) && isDigit(p.src[p.pos]); p.pos++ {
+				}
+			}
+		}
+		text := p.src[start:p.pos]
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.tok = token{pos: start}
+			return p.errorf("invalid number %q", text)
+		}
+		p.tok = token{kind: tokNumber, text: text, num: num, pos: start}
+	case isLetter(c):
+		for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	default:
+		if p.pos+1 < len(p.src) && slices.Contains([]string{"<=", ">=", "==", "!=", "&&", "||"}, p.src[p.pos:p.pos+2]) {
+			p.pos += 2
+		} else if strings.IndexByte("+-*/%^()<>,?:!", c) >= 0 {
+			p.pos++
+		} else {
+			p.tok = token{pos: start}
+			return p.errorf("unexpected character %q", c)
+		}
+		p.tok = token{kind: tokOp, text: p.src[start:p.pos], pos: start}
+	}
+	return nil
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' }
+
+// accept reads the next token if the current one is the operator op.
+func (p *parser) accept(op string) (bool, error) {
+	if p.tok.kind != tokOp || p.tok.text != op {
+		return false, nil
+	}
+	return true, p.next()
+}
+
+func (p *parser) expect(op string) error {
+	if ok, err := p.accept(op); ok || err != nil {
+		return err
+	}
+	if p.tok.kind == tokEOF {
+		return p.errorf("expected %q but the expression ended", op)
+	}
+	return p.errorf("expected %q but got %q", op, p.tok.text)
+}
+
+func (p *parser) ternary() (evalFunc, error) {
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.accept("?"); !ok || err != nil {
+		return cond, err
+	}
+	x, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	y, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return func(v []float64) float64 {
+		if cond(v) != 0 {
+			return x(v)
+		}
+		return y(v)
+	}, nil
+}
+
+// binary parses a chain of left-associative operators, operand parses the operands.
+func (p *parser) binary(operand func() (evalFunc, error), ops map[string]func(x, y evalFunc) evalFunc) (evalFunc, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp {
+		op, found := ops[p.tok.text]
+		if !found {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = op(x, y)
+	}
+	return x, nil
+}
+
+func (p *parser) or() (evalFunc, error) {
+	return p.binary(p.and, map[string]func(x, y evalFunc) evalFunc{
+		"||": func(x, y evalFunc) evalFunc {
+			return func(v []float64) float64 { return truth(x(v) != 0 || y(v) != 0) }
+		},
+	})
+}
+
+func (p *parser) and() (evalFunc, error) {
+	return p.binary(p.comparison, map[string]func(x, y evalFunc) evalFunc{
+		"&&": func(x, y evalFunc) evalFunc {
+			return func(v []float64) float64 { return truth(x(v) != 0 && y(v) != 0) }
+		},
+	})
+}
+
+func (p *parser) comparison() (evalFunc, error) {
+	return p.binary(p.sum, map[string]func(x, y evalFunc) evalFunc{
+		"<":  func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return truth(x(v) < y(v)) } },
+		"<=": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return truth(x(v) <= y(v)) } },
+		">":  func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return truth(x(v) > y(v)) } },
+		">=": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return truth(x(v) >= y(v)) } },
+		"==": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return truth(x(v) == y(v)) } },
+		"!=": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return truth(x(v) != y(v)) } },
+	})
+}
+
+func (p *parser) sum() (evalFunc, error) {
+	return p.binary(p.product, map[string]func(x, y evalFunc) evalFunc{
+		"+": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return x(v) + y(v) } },
+		"-": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return x(v) - y(v) } },
+	})
+}
+
+func (p *parser) product() (evalFunc, error) {
+	return p.binary(p.unary, map[string]func(x, y evalFunc) evalFunc{
+		"*": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return x(v) * y(v) } },
+		"/": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return x(v) / y(v) } },
+		"%": func(x, y evalFunc) evalFunc { return func(v []float64) float64 { return math.Mod(x(v), y(v)) } },
+	})
+}
+
+func (p *parser) unary() (evalFunc, error) {
+	if p.tok.kind == tokOp && (p.tok.text == "-" || p.tok.text == "+" || p.tok.text == "!") {
+		op := p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "-":
+			return func(v []float64) float64 { return -x(v) }, nil
+		case "!":
+			return func(v []float64) float64 { return truth(x(v) == 0) }, nil
+		}
+		return x, nil
+	}
+	return p.power()
+}
+
+// power parses the right-associative ^ operator, it binds tighter than unary operators on its left: -2^2 is -4.
+func (p *parser) power() (evalFunc, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.accept("^"); !ok || err != nil {
+		return x, err
+	}
+	y, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(v []float64) float64 { return math.Pow(x(v), y(v)) }, nil
+}
+
+func (p *parser) primary() (evalFunc, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return func([]float64) float64 { return tok.num }, nil
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokOp && p.tok.text == "(" {
+			return p.call(tok)
+		}
+		if i := slices.Index(p.vars, tok.text); i >= 0 {
+			return func(v []float64) float64 { return v[i] }, nil
+		}
+		if c, found := constants[tok.text]; found {
+			return func([]float64) float64 { return c }, nil
+		}
+		p.tok = tok
+		return nil, p.errorf("unknown variable %q, available are: %s", tok.text, strings.Join(p.vars, ", "))
+	case tokOp:
+		if tok.text == "(" {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			x, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+		return nil, p.errorf("unexpected %q", tok.text)
+	}
+	return nil, p.errorf("unexpected end of the expression")
+}
+
+// call parses the arguments of a call to the function fn, the current token is the opening parenthesis.
+func (p *parser) call(fn token) (evalFunc, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	args := []evalFunc{}
+	if ok, err := p.accept(")"); err != nil {
+		return nil, err
+	} else if !ok {
+		for {
+			arg, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if ok, err := p.accept(","); err != nil {
+				return nil, err
+			} else if !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	arity := func(n int) error {
+		if len(args) != n {
+			return &SyntaxError{Expr: p.src, Pos: fn.pos, Msg: fmt.Sprintf("%s expects %d arguments but got %d", fn.text, n, len(args))}
+		}
+		return nil
+	}
+	if f, found := funcs1[fn.text]; found {
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		x := args[0]
+		return func(v []float64) float64 { return f(x(v)) }, nil
+	}
+	if f, found := funcs2[fn.text]; found {
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		x, y := args[0], args[1]
+		return func(v []float64) float64 { return f(x(v), y(v)) }, nil
+	}
+	if f, found := funcs3[fn.text]; found {
+		if err := arity(3); err != nil {
+			return nil, err
+		}
+		x, y, z := args[0], args[1], args[2]
+		return func(v []float64) float64 { return f(x(v), y(v), z(v)) }, nil
+	}
+	if fn.text == "min" || fn.text == "max" {
+		if len(args) == 0 {
+			return nil, &SyntaxError{Expr: p.src, Pos: fn.pos, Msg: fn.text + " expects at least 1 argument"}
+		}
+		pick := math.Min
+		if fn.text == "max" {
+			pick = math.Max
+		}
+		res := args[0]
+		for _, arg := range args[1:] {
+			x, y := res, arg
+			res = func(v []float64) float64 { return pick(x(v), y(v)) }
+		}
+		return res, nil
+	}
+	switch f := p.funcs[fn.text].(type) {
+	case func(float64) float64:
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		x := args[0]
+		return func(v []float64) float64 { return f(x(v)) }, nil
+	case func(float64, float64) float64:
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		x, y := args[0], args[1]
+		return func(v []float64) float64 { return f(x(v), y(v)) }, nil
+	case func(float64, float64, float64) float64:
+		if err := arity(3); err != nil {
+			return nil, err
+		}
+		x, y, z := args[0], args[1], args[2]
+		return func(v []float64) float64 { return f(x(v), y(v), z(v)) }, nil
+	}
+	return nil, &SyntaxError{Expr: p.src, Pos: fn.pos, Msg: fmt.Sprintf("unknown function %q", fn.text)}
+}
@@ -0,0 +1,20 @@
+package math
+
+// Hash returns a pseudo-random number for the grid point (x, y), the same seed always returns the same numbers.
+func Hash(seed uint64, x, y int64) uint64 {
+	return mix(mix(mix(seed+0x9E3779B97F4A7C15)^uint64(x)) ^ uint64(y))
+}
+
+// HashFloat returns a pseudo-random number in [0, 1) for the grid point (x, y), see Hash.
+func HashFloat(seed uint64, x, y int64) float64 {
+	return float64(Hash(seed, x, y)>>11) / (1 << 53)
+}
+
+// mix scrambles the bits of h (the finalizer of SplitMix64).
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 27
+	h *= 0x94D049BB133111EB
+	return h ^ h>>31
+}
@@ -1,14 +1,28 @@
 package net
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+// Timeout is the maximum time a download may take, including reading the response body.
+var Timeout = 30 * time.Second
+
 func Download(url string) ([]byte, error) {
-	resp, err := http.Get(url) // #nosec G304
+	return DownloadContext(context.Background(), url)
+}
+
+// DownloadContext is like Download but aborts the request when ctx is cancelled.
+func DownloadContext(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := (&http.Client{Timeout: Timeout}).Do(req) // #nosec G107
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
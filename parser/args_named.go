@@ -41,6 +41,11 @@ func parseNamedArgs(args string, filter *ImageFilter, vars map[string]string) {
 			}
 			idx++
 		}
+		if idx == len(args) && strings.HasPrefix(args, STR_QUOTE) {
+			// this is the final argument, it's a string that contains a STR_ASSIGN
+			filter.Options[lhs] = parseArgsValue(args, vars)
+			args = ""
+		}
 	}
 	if len(args) > 0 {
 		// there should be one final argument left
@@ -5,7 +5,10 @@ import (
 )
 
 func parseUnnamedArgs(args string, filter *ImageFilter, vars map[string]string) error {
-	m, _ := Filters.Get(filter.Type)
+	m, _ := Filters.Get(strings.ToLower(filter.Type))
+	if m == nil {
+		return &UnknownFilterError{Name: filter.Type}
+	}
 	keys := m.ArgNames()
 	values := make([]any, len(keys))
 	inQuote := false
@@ -19,8 +22,13 @@ func parseUnnamedArgs(args string, filter *ImageFilter, vars map[string]string)
 				continue // this is an escaped quote
 			}
 			inQuote = !inQuote
-			if !inQuote && inArg {
+			if inQuote {
+				inArg = true // the string might be empty
+			} else if inArg {
 				// we just finished a string
+				if argIdx >= len(keys) {
+					return newParseError(args, "too many arguments for filter %s", filter.Type)
+				}
 				val := strings.TrimSpace(args[idx : i+1])
 				values[argIdx] = parseArgsValue(val, vars)
 				argIdx++
@@ -29,6 +37,9 @@ func parseUnnamedArgs(args string, filter *ImageFilter, vars map[string]string)
 			}
 		case CHAR_SPACE, CHAR_TAB:
 			if !inQuote && inArg {
+				if argIdx >= len(keys) {
+					return newParseError(args, "too many arguments for filter %s", filter.Type)
+				}
 				val := strings.TrimSpace(args[idx:i])
 				values[argIdx] = parseArgsValue(val, vars)
 				argIdx++
@@ -41,12 +52,16 @@ func parseUnnamedArgs(args string, filter *ImageFilter, vars map[string]string)
 			}
 		}
 	}
-	if argIdx < len(keys) {
-		val := strings.TrimSpace(args[idx:])
+	if val := strings.TrimSpace(args[idx:]); val != "" {
+		if argIdx >= len(keys) {
+			return newParseError(args, "too many arguments for filter %s", filter.Type)
+		}
 		values[argIdx] = parseArgsValue(val, vars)
+		argIdx++
 	}
 
-	for i := 0; i < len(keys); i++ {
+	// missing args keep their defaults
+	for i := 0; i < argIdx; i++ {
 		filter.Options[keys[i]] = values[i]
 	}
 	return nil
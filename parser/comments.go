@@ -2,16 +2,16 @@ package parser
 
 import "strings"
 
-func trimCommentsAndWhitespace(line string) string {
+func trimCommentsAndWhitespace(line string) (string, error) {
 	line = strings.TrimSpace(line)
 	if !strings.Contains(line, STR_COMMENT) {
-		return line // nothing to strip, so we return the line as is
+		return line, nil // nothing to strip, so we return the line as is
 	}
 	if line[0] == CHAR_COMMENT {
-		return "" // entire line is commented, so we strip everything
+		return "", nil // entire line is commented, so we strip everything
 	}
 	if !strings.Contains(line, STR_QUOTE) {
-		return strings.TrimSpace(line[:strings.Index(line, STR_COMMENT)]) // there is no string in this line so we can simply strip the comment
+		return strings.TrimSpace(line[:strings.Index(line, STR_COMMENT)]), nil // there is no string in this line so we can simply strip the comment
 	}
 
 	// if we get here there is a string in the line and a # which could be part of the string, or the string is part of the comment
@@ -26,11 +26,13 @@ func trimCommentsAndWhitespace(line string) string {
 			inQuote = !inQuote // toggle inQuote status
 		case CHAR_COMMENT:
 			if !inQuote {
-				return strings.TrimSpace(line[:i]) // we're not in a string, so this is the comment position
+				return strings.TrimSpace(line[:i]), nil // we're not in a string, so this is the comment position
 			}
 		}
 	}
 
-	// we shouldn't get here, let's error
-	panic("stripping comments failed on: \n" + line + "\n")
+	if inQuote {
+		return "", newParseError(line, "unterminated string")
+	}
+	return line, nil // all # are part of strings
 }
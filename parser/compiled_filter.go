@@ -22,6 +22,22 @@ func (f *CompiledFilter) Append(filters ...*ImageFilter) *CompiledFilter {
 	return f
 }
 
+// requires returns the filter blocks that image options of the filters refer to (see GetOptionImage),
+// including the blocks those refer to, in the order they have to be defined.
+func (f *CompiledFilter) requires() []*CompiledFilter {
+	res := []*CompiledFilter{}
+	for _, filter := range f.Filters {
+		for _, v := range filter.Options {
+			if name, ok := v.(string); ok {
+				if b, ok := filter.blocks[name]; ok && b != f {
+					res = append(append(res, b.requires()...), b)
+				}
+			}
+		}
+	}
+	return res
+}
+
 func (f *CompiledFilter) Get() []*ImageFilter {
 	return f.Filters
 }
@@ -1,25 +1,33 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/toxyl/flo"
 	"github.com/toxyl/gfx/color/blend"
 	"github.com/toxyl/gfx/color/hsla"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
 	"github.com/toxyl/gfx/math"
 )
 
 type Composition struct {
-	Name   string          `yaml:"name,omitempty"`
-	Width  int             `yaml:"width,omitempty"`
-	Height int             `yaml:"height,omitempty"`
-	Layers []*Layer        `yaml:"layers,omitempty"`
-	Color  *hsla.HSLA      `yaml:"color,omitempty"`
-	Crop   *Crop           `yaml:"crop,omitempty"`
-	Resize *Resize         `yaml:"resize,omitempty"`
-	Filter *CompiledFilter `yaml:"filter,omitempty"`
+	Name       string            `yaml:"name,omitempty"`
+	Width      int               `yaml:"width,omitempty"`
+	Height     int               `yaml:"height,omitempty"`
+	Layers     []*Layer          `yaml:"layers,omitempty"`
+	BlendModes map[string]string `yaml:"blendmodes,omitempty"` // user-defined blend modes, name => expression
+	Color      *hsla.HSLA        `yaml:"color,omitempty"`
+	Background string            `yaml:"background,omitempty"` // generated source (like a gradient) used instead of Color
+	Crop       *Crop             `yaml:"crop,omitempty"`
+	Resize     *Resize           `yaml:"resize,omitempty"`
+	Resample   string            `yaml:"resample,omitempty"`
+	BlendSpace string            `yaml:"blend-space,omitempty"` // space layers are blended in, defaults to srgb
+	Filter     *CompiledFilter   `yaml:"filter,omitempty"`
 }
 
 func (c *Composition) String() string {
@@ -30,12 +38,17 @@ func (c *Composition) String() string {
 	filter := STR_COMMENT + " no " + COMP_FILTER + " defined"
 	resize := STR_COMMENT + " no " + COMP_RESIZE + " defined"
 	crop := STR_COMMENT + " no " + COMP_CROP + " defined"
+	kernel := STR_COMMENT + " no " + COMP_RESAMPLE + " defined"
+	space := STR_COMMENT + " no " + COMP_BLEND_SPACE + " defined"
 	name := STR_COMMENT + " no " + COMP_NAME + " defined"
 	width := STR_COMMENT + " no " + COMP_WIDTH + " defined"
 	height := STR_COMMENT + " no " + COMP_HEIGHT + " defined"
 	if c.Color != nil {
 		color = spf("%s %s hsla%s%f %f %f %f%s", spfPad(maxLenOp, COMP_COLOR), STR_ASSIGN, STR_LPAREN, c.Color.H(), c.Color.S(), c.Color.L(), c.Color.A(), STR_RPAREN)
 	}
+	if c.Background != "" {
+		color = spf("%s %s %s", spfPad(maxLenOp, COMP_COLOR), STR_ASSIGN, c.Background)
+	}
 	if c.Filter != nil {
 		filter = spf("%s %s %s", spfPad(maxLenOp, COMP_FILTER), STR_ASSIGN, c.Filter.Name)
 	}
@@ -45,6 +58,12 @@ func (c *Composition) String() string {
 	if c.Resize != nil {
 		resize = spf("%s %s %d %d", spfPad(maxLenOp, COMP_RESIZE), STR_ASSIGN, c.Resize.W, c.Resize.H)
 	}
+	if c.Resample != "" {
+		kernel = spf("%s %s %s", spfPad(maxLenOp, COMP_RESAMPLE), STR_ASSIGN, c.Resample)
+	}
+	if c.BlendSpace != "" {
+		space = spf("%s %s %s", spfPad(maxLenOp, COMP_BLEND_SPACE), STR_ASSIGN, c.BlendSpace)
+	}
 	if c.Name != "" {
 		name = spf("%s %s %s", spfPad(maxLenOp, COMP_NAME), STR_ASSIGN, STR_QUOTE+c.Name+STR_QUOTE)
 	}
@@ -56,8 +75,18 @@ func (c *Composition) String() string {
 	}
 	filters := []string{}
 	layers := []string{}
+	defined := map[string]bool{}
+	addFilter := func(f *CompiledFilter) {
+		for _, b := range append(f.requires(), f) {
+			if b.Name != "" && defined[b.Name] {
+				continue
+			}
+			defined[b.Name] = true
+			filters = append(filters, b.String())
+		}
+	}
 	if c.Layers != nil {
-		hasCrop, hasResize, hasOffset, hasFilter := false, false, false, false
+		hasCrop, hasResize, hasOffset, hasResample, hasBlendSpace, hasComposite, hasSeed, hasFilter := false, false, false, false, false, false, false, false
 		for _, l := range c.Layers {
 			if l == nil {
 				continue
@@ -71,6 +100,18 @@ func (c *Composition) String() string {
 			if l.Offset != nil {
 				hasOffset = true
 			}
+			if l.Resample != "" {
+				hasResample = true
+			}
+			if l.BlendSpace != "" {
+				hasBlendSpace = true
+			}
+			if l.Composite != "" {
+				hasComposite = true
+			}
+			if l.Seed != 0 {
+				hasSeed = true
+			}
 			if l.Filter != nil {
 				hasFilter = true
 			}
@@ -81,14 +122,23 @@ func (c *Composition) String() string {
 				continue
 			}
 
-			layers = append(layers, l.String(hasCrop, hasResize, hasOffset, hasFilter))
+			layers = append(layers, l.String(hasCrop, hasResize, hasOffset, hasResample, hasBlendSpace, hasComposite, hasSeed, hasFilter))
 			if l.Filter != nil {
-				filters = append(filters, l.Filter.String())
+				addFilter(l.Filter)
 			}
 		}
 	}
 	if c.Filter != nil {
-		filters = append(filters, c.Filter.String())
+		addFilter(c.Filter)
+	}
+	modes := []string{STR_COMMENT + " none defined"}
+	if len(c.BlendModes) > 0 {
+		names := slices.Sorted(maps.Keys(c.BlendModes))
+		maxLenName := math.MaxLenStr(names...)
+		modes = modes[:0]
+		for _, name := range names {
+			modes = append(modes, spf("%s %s %s", spfPad(maxLenName, name), STR_ASSIGN, c.BlendModes[name]))
+		}
 	}
 	return spf(
 		`%s%s%s
@@ -97,6 +147,9 @@ func (c *Composition) String() string {
 %s%s%s
 %s
 
+%s%s%s
+%s
+
 %s%s%s
 %s 
 %s
@@ -105,6 +158,8 @@ func (c *Composition) String() string {
 %s
 %s
 %s
+%s
+%s
 
 %s%s%s
 %s
@@ -112,6 +167,8 @@ func (c *Composition) String() string {
 		STR_LBRACKET, strings.ToUpper(SECTION_VARS), STR_RBRACKET,
 		STR_LBRACKET, strings.ToUpper(SECTION_FILTERS), STR_RBRACKET,
 		strings.Join(filters, "\n"),
+		STR_LBRACKET, strings.ToUpper(SECTION_BLENDMODES), STR_RBRACKET,
+		strings.Join(modes, "\n"),
 		STR_LBRACKET, strings.ToUpper(SECTION_COMPOSITION), STR_RBRACKET,
 		name,
 		width,
@@ -120,93 +177,155 @@ func (c *Composition) String() string {
 		filter,
 		crop,
 		resize,
+		kernel,
+		space,
 		STR_LBRACKET, strings.ToUpper(SECTION_LAYERS), STR_RBRACKET,
 		strings.Join(layers, "\n"),
 	)
 }
 
-func (c *Composition) LoadYAML(path string) *Composition {
-	if err := flo.File(path).LoadYAML(&c); err != nil {
-		panic("failed to load composition from YAML: " + err.Error())
+// LoadYAML replaces the composition with the one stored as YAML at path.
+func (c *Composition) LoadYAML(path string) (*Composition, error) {
+	if err := flo.File(path).LoadYAML(c); err != nil {
+		return nil, &IOError{Op: "load", Path: path, Err: err}
 	}
-	return c
+	return c, nil
 }
 
-func (c *Composition) LoadGFXS(path string) *Composition {
+// LoadGFXS replaces the composition with the one stored as GFXScript at path.
+// A ParseError or UnknownFilterError is returned if the script is invalid.
+func (c *Composition) LoadGFXS(path string) (*Composition, error) {
 	str := ""
 	if err := flo.File(path).LoadString(&str); err != nil {
-		panic("failed to load composition: " + err.Error())
+		return nil, &IOError{Op: "load", Path: path, Err: err}
 	}
 	comp, err := ParseComposition(str)
 	if err != nil {
-		panic("failed to parse composition: " + err.Error())
+		return nil, err
 	}
-	c = comp
-	return c
+	*c = *comp
+	return c, nil
 }
 
-func (c *Composition) SaveYAML(path string) *Composition {
-	if err := flo.File(path).StoreYAML(&c); err != nil {
-		panic("failed to save composition as YAML: " + err.Error())
+func (c *Composition) SaveYAML(path string) error {
+	if err := flo.File(path).StoreYAML(c); err != nil {
+		return &IOError{Op: "save", Path: path, Err: err}
 	}
-	return c
+	return nil
 }
 
-func (c *Composition) SaveGFXS(path string) *Composition {
+func (c *Composition) SaveGFXS(path string) error {
 	if err := flo.File(path).StoreString(c.String()); err != nil {
-		panic("failed to save composition: " + err.Error())
+		return &IOError{Op: "save", Path: path, Err: err}
 	}
-	return c
+	return nil
+}
+
+// Render renders all layers from bottom to top and then applies the composition filter, crop and resize.
+// Rendering stops with the context's error if ctx is cancelled.
+func (c *Composition) Render(ctx context.Context) (*image.Image, error) {
+	return c.RenderWithProgress(ctx, nil)
 }
 
-func (c *Composition) Render() *image.Image {
+// RenderWithProgress is like Render but reports every step of the render to fn.
+func (c *Composition) RenderWithProgress(ctx context.Context, fn ProgressFunc) (*image.Image, error) {
+	steps := countFilters(c.Filter) + 1
+	for _, l := range c.Layers {
+		steps += countFilters(l.Filter) + 2
+	}
+	p := newProgress(fn, steps)
+
 	w, h := c.Width, c.Height
 	res := image.New(w, h)
 	if c.Color != nil {
 		res.FillHSLA(0, 0, w, h, c.Color)
 	}
+	if c.Background != "" {
+		gen, _, err := parseGenerator(c.Background)
+		if err != nil {
+			return nil, err
+		}
+		if gen != nil {
+			res = gen(w, h)
+		}
+	}
+	res = res.WithContext(ctx) // blending uses the context too
+	custom := map[string]blend.CustomFunc{}
+	for name, src := range c.BlendModes {
+		fn, err := compileBlendMode(src)
+		if err != nil {
+			return nil, newParseError(src, "%s", err)
+		}
+		custom[name] = fn
+	}
 	numLayers := len(c.Layers)
 	for i := numLayers - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		l := c.Layers[i]
-		scaled := l.Render(w, h)
-		if scaled == nil {
-			fmt.Printf("WARN: failed to render layer source %s, ignoring layer.\n", l.Source)
-			continue // rendering failed, maybe URL or file wasn't available
+		scaled, err := l.render(ctx, w, h, resample.Kernel(c.Resample), p, i)
+		if err != nil {
+			return nil, err
+		}
+		space := c.BlendSpace
+		if l.BlendSpace != "" {
+			space = l.BlendSpace
 		}
-		res.Draw(
+		p.step(STAGE_BLEND, i, "")
+		if fn, ok := custom[l.BlendMode]; ok {
+			res.DrawCustom(scaled, 0, 0, w, h, 0, 0, w, h, fn, blend.CompositeOp(l.Composite), l.Alpha, blend.BlendSpace(space))
+			continue
+		}
+		if blend.BlendMode(l.BlendMode) == blend.DISSOLVE {
+			res.DrawDissolve(scaled, 0, 0, w, h, 0, 0, w, h, blend.CompositeOp(l.Composite), l.Alpha, blend.BlendSpace(space), l.Seed)
+			continue
+		}
+		res.DrawComposite(
 			scaled,
 			0, 0, w, h,
 			0, 0, w, h,
 			blend.BlendMode(l.BlendMode),
+			blend.CompositeOp(l.Composite),
 			l.Alpha,
+			blend.BlendSpace(space),
 		)
 	}
 	if c.Filter != nil {
-		for _, filter := range c.Filter.Get() {
-			if filter != nil {
-				res = filter.Apply(res)
-			}
+		var err error
+		if res, err = applyFilters(ctx, res, c.Filter.Get(), p, -1); err != nil {
+			return nil, err
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.step(STAGE_FINISH, -1, "")
 	if c.Crop != nil && c.Crop.W > 0 && c.Crop.H > 0 {
 		res = res.Crop(c.Crop.X, c.Crop.Y, c.Crop.W, c.Crop.H, true)
 	}
 	if c.Resize != nil && c.Resize.W > 0 && c.Resize.H > 0 {
-		return res.Resize(c.Resize.W, c.Resize.H)
+		w, h = c.Resize.W, c.Resize.H
 	}
-	return res.Resize(w, h)
+	res = res.ResizeWith(w, h, resample.Kernel(c.Resample)).WithContext(context.Background())
+	p.finish()
+	return res, nil
 }
 
 func NewComposition(name string, w, h int) *Composition {
 	c := Composition{
-		Name:   name,
-		Width:  w,
-		Height: h,
-		Layers: []*Layer{},
-		Color:  nil,
-		Crop:   nil,
-		Resize: nil,
-		Filter: nil,
+		Name:       name,
+		Width:      w,
+		Height:     h,
+		Layers:     []*Layer{},
+		BlendModes: map[string]string{},
+		Color:      nil,
+		Background: "",
+		Crop:       nil,
+		Resize:     nil,
+		Resample:   "",
+		BlendSpace: "",
+		Filter:     nil,
 	}
 	return &c
 }
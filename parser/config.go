@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/image/resample"
 )
 
 const (
@@ -33,44 +34,101 @@ const (
 	CHAR_LBRACKET = '['
 	CHAR_RBRACKET = ']'
 	CHAR_COMMA    = ','
+	CHAR_SOURCE   = ':'
 )
 
 // section consts
 const (
 	SECTION_VARS        = "VARS"
 	SECTION_FILTERS     = "FILTERS"
+	SECTION_BLENDMODES  = "BLENDMODES"
 	SECTION_COMPOSITION = "COMPOSITION"
 	SECTION_LAYERS      = "LAYERS"
 )
 
 var (
-	SECTIONS = []string{SECTION_VARS, SECTION_FILTERS, SECTION_COMPOSITION, SECTION_LAYERS}
+	SECTIONS = []string{SECTION_VARS, SECTION_FILTERS, SECTION_BLENDMODES, SECTION_COMPOSITION, SECTION_LAYERS}
+)
+
+// variables of user-defined blend modes
+const (
+	BLENDMODE_BASE  = "a"     // channel of the base color
+	BLENDMODE_BLEND = "b"     // channel of the blend color
+	BLENDMODE_ALPHA = "alpha" // alpha of the blend color
 )
 
 // composition consts
 const (
-	COMP_NAME   = "name"
-	COMP_WIDTH  = "width"
-	COMP_HEIGHT = "height"
-	COMP_COLOR  = "color"
-	COMP_FILTER = "filter"
-	COMP_CROP   = "crop"
-	COMP_RESIZE = "resize"
+	COMP_NAME        = "name"
+	COMP_WIDTH       = "width"
+	COMP_HEIGHT      = "height"
+	COMP_COLOR       = "color"
+	COMP_FILTER      = "filter"
+	COMP_CROP        = "crop"
+	COMP_RESIZE      = "resize"
+	COMP_RESAMPLE    = "resample"
+	COMP_BLEND_SPACE = "blend-space"
 )
 
 var (
-	COMPOSITION = []string{COMP_NAME, COMP_WIDTH, COMP_HEIGHT, COMP_COLOR, COMP_CROP, COMP_FILTER, COMP_RESIZE}
+	COMPOSITION = []string{COMP_NAME, COMP_WIDTH, COMP_HEIGHT, COMP_COLOR, COMP_CROP, COMP_FILTER, COMP_RESIZE, COMP_RESAMPLE, COMP_BLEND_SPACE}
 )
 
 // layer consts
 const (
-	LAYER_CROP   = "crop"
-	LAYER_RESIZE = "resize"
-	LAYER_OFFSET = "offset"
+	LAYER_CROP        = "crop"
+	LAYER_RESIZE      = "resize"
+	LAYER_OFFSET      = "offset"
+	LAYER_RESAMPLE    = "resample"
+	LAYER_BLEND_SPACE = "blend-space"
+	LAYER_COMPOSITE   = "composite"
+	LAYER_SEED        = "seed"
+)
+
+var (
+	LAYER = []string{LAYER_CROP, LAYER_RESIZE, LAYER_OFFSET, LAYER_RESAMPLE, LAYER_BLEND_SPACE, LAYER_COMPOSITE, LAYER_SEED}
+)
+
+// generated source consts
+const (
+	SOURCE_GRADIENT          = "gradient"
+	SOURCE_NOISE             = "noise"
+	SOURCE_PATTERN_GENERATOR = "pattern" // SOURCE_PATTERN is the regex that matches all sources
 )
 
 var (
-	LAYER = []string{LAYER_CROP, LAYER_RESIZE, LAYER_OFFSET}
+	SOURCES = []string{SOURCE_GRADIENT, SOURCE_NOISE, SOURCE_PATTERN_GENERATOR}
+)
+
+// band maths source consts
+const (
+	SOURCE_BANDS = "bands"
+	BANDS_EXPR   = "expr"
+	BANDS_MIN    = "min"
+	BANDS_MAX    = "max"
+	BANDS_CLIP   = "clip"
+)
+
+// gradient consts
+const (
+	GRADIENT_LINEAR = "linear"
+	GRADIENT_RADIAL = "radial"
+	GRADIENT_CONIC  = "conic"
+)
+
+var (
+	GRADIENTS = []string{GRADIENT_LINEAR, GRADIENT_RADIAL, GRADIENT_CONIC}
+)
+
+// pattern consts
+const (
+	PATTERN_CHECKERBOARD = "checkerboard"
+	PATTERN_STRIPES      = "stripes"
+	PATTERN_GRID         = "grid"
+)
+
+var (
+	PATTERNS = []string{PATTERN_CHECKERBOARD, PATTERN_STRIPES, PATTERN_GRID}
 )
 
 // keyword consts
@@ -87,26 +145,51 @@ var (
 	BLENDMODES = []string{
 		string(blend.ADD),
 		string(blend.AVERAGE),
+		string(blend.COLOR),
 		string(blend.COLOR_BURN),
 		string(blend.DARKEN),
+		string(blend.DARKER_COLOR),
 		string(blend.DIFFERENCE),
+		string(blend.DISSOLVE),
 		string(blend.DIVIDE),
 		string(blend.ERASE),
 		string(blend.EXCLUSION),
 		string(blend.HARD_LIGHT),
+		string(blend.HARD_MIX),
+		string(blend.HUE),
 		string(blend.LIGHTEN),
+		string(blend.LIGHTER_COLOR),
 		string(blend.LINEAR_BURN),
+		string(blend.LINEAR_LIGHT),
+		string(blend.LUMINOSITY),
 		string(blend.MULTIPLY),
 		string(blend.NEGATION),
 		string(blend.NORMAL),
 		string(blend.OVERLAY),
 		string(blend.PIN_LIGHT),
+		string(blend.SATURATION),
 		string(blend.SCREEN),
 		string(blend.SOFT_LIGHT),
 		string(blend.SUBTRACT),
+		string(blend.VIVID_LIGHT),
 	}
 )
 
+// resampling kernel constants
+var (
+	RESAMPLERS = resample.Names()
+)
+
+// blend space constants
+var (
+	BLEND_SPACES = blend.BlendSpaces()
+)
+
+// Porter-Duff operator constants
+var (
+	COMPOSITE_OPS = blend.CompositeOpNames()
+)
+
 // calculated consts
 const (
 	STR_SPACE    = string(CHAR_SPACE)
@@ -122,21 +205,27 @@ const (
 	STR_LBRACKET = string(CHAR_LBRACKET)
 	STR_RBRACKET = string(CHAR_RBRACKET)
 	STR_COMMA    = string(CHAR_COMMA)
+	STR_SOURCE   = string(CHAR_SOURCE)
 )
 
 // patterns
 var (
-	WORD_PATTERN        = `\b[a-zA-Z][a-zA-Z0-9-_]+?\b`
-	NUMBER_PATTERN      = `\b-?\d+(\.\d+)?\b`
-	ALPHA_PATTERN       = `\b(0|1)\.\d+?\b`
-	FILE_PATTERN        = `\.\./.*|\./.*|/.*`
-	URL_PATTERN         = `\b(http|ftp)s{0,1}://\S*\b`
-	CLI_ARG_PATTERN     = `\$\d+`
-	SECTIONS_PATTERN    = `\b(` + strings.Join(SECTIONS, "|") + `)\b`
-	COMPOSITION_PATTERN = `\b(` + strings.Join(COMPOSITION, "|") + `)\b`
-	LAYER_PATTERN       = `\b(` + strings.Join(LAYER, "|") + `)\b`
-	KEYWORDS_PATTERN    = `\b(` + strings.Join(KEYWORDS, "|") + `)\b`
-	BLENDMODES_PATTERN  = `\b(` + strings.Join(BLENDMODES, "|") + `)\b`
-	SECTION_PATTERN     = `\` + STR_LBRACKET + SECTIONS_PATTERN + `\` + STR_RBRACKET
-	SOURCE_PATTERN      = `(` + FILE_PATTERN + `|` + URL_PATTERN + `|` + CLI_ARG_PATTERN + `)`
+	WORD_PATTERN          = `\b[a-zA-Z][a-zA-Z0-9-_]+?\b`
+	NUMBER_PATTERN        = `\b-?\d+(\.\d+)?\b`
+	ALPHA_PATTERN         = `\b(0|1)\.\d+?\b`
+	FILE_PATTERN          = `\.\./.*|\./.*|/.*`
+	URL_PATTERN           = `\b(http|ftp)s{0,1}://\S*\b`
+	CLI_ARG_PATTERN       = `\$\d+`
+	GENERATOR_PATTERN     = `\b(` + strings.Join(SOURCES, "|") + `)` + STR_SOURCE + `\w+`
+	BANDS_PATTERN         = `\b` + SOURCE_BANDS + STR_SOURCE + `\w+`
+	SECTIONS_PATTERN      = `\b(` + strings.Join(SECTIONS, "|") + `)\b`
+	COMPOSITION_PATTERN   = `\b(` + strings.Join(COMPOSITION, "|") + `)\b`
+	LAYER_PATTERN         = `\b(` + strings.Join(LAYER, "|") + `)\b`
+	KEYWORDS_PATTERN      = `\b(` + strings.Join(KEYWORDS, "|") + `)\b`
+	BLENDMODES_PATTERN    = `\b(` + strings.Join(BLENDMODES, "|") + `)\b`
+	RESAMPLERS_PATTERN    = `\b(` + strings.Join(RESAMPLERS, "|") + `)\b`
+	BLEND_SPACES_PATTERN  = `\b(` + strings.Join(BLEND_SPACES, "|") + `)\b`
+	COMPOSITE_OPS_PATTERN = `\b(` + strings.Join(COMPOSITE_OPS, "|") + `)\b`
+	SECTION_PATTERN       = `\` + STR_LBRACKET + SECTIONS_PATTERN + `\` + STR_RBRACKET
+	SOURCE_PATTERN        = `(` + FILE_PATTERN + `|` + URL_PATTERN + `|` + CLI_ARG_PATTERN + `|` + GENERATOR_PATTERN + `|` + BANDS_PATTERN + `)`
 )
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/toxyl/gfx/image"
+)
+
+// IOError is returned when a composition, filter chain or layer source can't be read or written.
+type IOError = image.IOError
+
+// ParseError is returned when a GFXScript can't be parsed.
+type ParseError struct {
+	Line int    // Line number (starting at 1) or 0 if unknown.
+	Text string // The offending line.
+	Msg  string // What went wrong.
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("parse error on line %d (%s): %s", e.Line, e.Text, e.Msg)
+	}
+	if e.Text != "" {
+		return fmt.Sprintf("parse error (%s): %s", e.Text, e.Msg)
+	}
+	return "parse error: " + e.Msg
+}
+
+// UnknownFilterError is returned when a script references a filter that is neither
+// one of the available filters nor defined in the [FILTERS] section.
+type UnknownFilterError struct {
+	Line int    // Line number (starting at 1) or 0 if unknown.
+	Name string // Name of the filter.
+}
+
+func (e *UnknownFilterError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("unknown filter on line %d: %s", e.Line, e.Name)
+	}
+	return "unknown filter: " + e.Name
+}
+
+// ArgumentError is returned when a filter or layer argument has an invalid value,
+// e.g. when a script uses `$1` but the CLI argument is missing.
+type ArgumentError struct {
+	Name  string // Name of the argument.
+	Value any    // Value of the argument.
+	Msg   string // What went wrong.
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("invalid argument %s=%v: %s", e.Name, e.Value, e.Msg)
+}
+
+func newParseError(text, format string, args ...any) *ParseError {
+	return &ParseError{Text: text, Msg: fmt.Sprintf(format, args...)}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"strconv"
 	"strings"
 
@@ -77,12 +78,25 @@ func (fc *FilterChain) Append(filter ...string) {
 	}
 }
 
-func (fc *FilterChain) Apply(img *image.Image) *image.Image {
-	out := img.Clone()
-	for _, f := range *fc {
-		out = f.Apply(out)
+// Apply applies all filters of the chain to a copy of img.
+// It stops with the context's error if ctx is cancelled.
+func (fc *FilterChain) Apply(ctx context.Context, img *image.Image) (*image.Image, error) {
+	return applyFilters(ctx, img.Clone(), *fc, nil, -1)
+}
+
+// applyFilters applies filters to img in order, each filter advances p by one step.
+func applyFilters(ctx context.Context, img *image.Image, filters []*ImageFilter, p *progress, layer int) (*image.Image, error) {
+	var err error
+	for _, f := range filters {
+		if f == nil {
+			continue
+		}
+		p.step(STAGE_FILTER, layer, f.Type)
+		if img, err = f.ApplyContext(ctx, img); err != nil {
+			return nil, err
+		}
 	}
-	return out
+	return img, nil
 }
 
 func NewFilterChain(filter ...string) *FilterChain {
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/toxyl/gfx/filters/expression"
+)
+
+// compileExprFilter compiles the expressions of an expr filter.
+func compileExprFilter(s *Filter, m *MetaData) (*expression.Filter, error) {
+	exprs := make([]string, 7)
+	for k := range exprs {
+		exprs[k] = s.GetOptionString(m.NameOf(k), m.DefaultOf(k))
+	}
+	return expression.Compile(exprs[0], exprs[1], exprs[2], exprs[3], exprs[4], exprs[5], exprs[6], int64(s.GetOptionFloat64(m.NameOf(7), m.DefaultOf(7))))
+}
+
+// prepareExprFilter replaces the [VARS] used by the expressions of an expr filter with their values
+// and checks that the expressions compile, so that errors show up when the script is parsed.
+// Pixel variables (see expression.Vars) and function names take precedence over [VARS] of the same name.
+func prepareExprFilter(f *ImageFilter, vars map[string]string) error {
+	m, _ := Filters.Get(expression.Meta.Name)
+	for k := range 7 {
+		if src, ok := f.Options[m.NameOf(k)].(string); ok {
+			if strings.HasPrefix(src, `"`) {
+				return fmt.Errorf("the expression for %s must be enclosed in backticks, not double quotes", m.NameOf(k))
+			}
+			f.Options[m.NameOf(k)] = expandExprVars(src, vars)
+		}
+	}
+	_, err := compileExprFilter(f, m)
+	return err
+}
+
+// expandExprVars replaces the identifiers in src that name [VARS] with their values in parentheses.
+func expandExprVars(src string, vars map[string]string) string {
+	isLetter := func(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' }
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	res := strings.Builder{}
+	for i := 0; i < len(src); {
+		start := i
+		switch c := src[i]; {
+		case isDigit(c) || c == '.':
+			// numbers can contain letters (1e-3), they must not be mistaken for identifiers
+			for i++; i < len(src) && (isDigit(src[i]) || isLetter(src[i]) || src[i] == '.'); i++ {
+			}
+		case isLetter(c):
+			for i++; i < len(src) && (isLetter(src[i]) || isDigit(src[i])); i++ {
+			}
+			name := src[start:i]
+			if _, ok := vars[name]; ok && !strings.HasPrefix(strings.TrimLeft(src[i:], " \t"), STR_LPAREN) && !slices.Contains(expression.Vars, name) {
+				res.WriteString(STR_LPAREN + formatExprValue(parseArgsValue(name, vars)) + STR_RPAREN)
+				continue
+			}
+		default:
+			i++
+		}
+		res.WriteString(src[start:i])
+	}
+	return res.String()
+}
+
+func formatExprValue(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
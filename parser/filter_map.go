@@ -1,10 +1,13 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/toxyl/gfx/color/filter"
+	"github.com/toxyl/gfx/filters/affine"
 	"github.com/toxyl/gfx/filters/alphamap"
 	"github.com/toxyl/gfx/filters/blur"
 	"github.com/toxyl/gfx/filters/brightness"
@@ -13,41 +16,52 @@ import (
 	"github.com/toxyl/gfx/filters/convolution"
 	"github.com/toxyl/gfx/filters/crop"
 	"github.com/toxyl/gfx/filters/cropcircle"
+	"github.com/toxyl/gfx/filters/displace"
 	"github.com/toxyl/gfx/filters/edgedetect"
 	"github.com/toxyl/gfx/filters/emboss"
 	"github.com/toxyl/gfx/filters/enhance"
+	"github.com/toxyl/gfx/filters/expression"
 	"github.com/toxyl/gfx/filters/extract"
 	"github.com/toxyl/gfx/filters/fliph"
 	"github.com/toxyl/gfx/filters/flipv"
+	"github.com/toxyl/gfx/filters/frompolar"
 	"github.com/toxyl/gfx/filters/gamma"
 	"github.com/toxyl/gfx/filters/gray"
 	"github.com/toxyl/gfx/filters/hue"
 	"github.com/toxyl/gfx/filters/huecontrast"
 	"github.com/toxyl/gfx/filters/invert"
+	"github.com/toxyl/gfx/filters/lens"
 	"github.com/toxyl/gfx/filters/lum"
 	"github.com/toxyl/gfx/filters/lumcontrast"
+	"github.com/toxyl/gfx/filters/matrix"
 	"github.com/toxyl/gfx/filters/meta"
 	"github.com/toxyl/gfx/filters/pastelize"
+	"github.com/toxyl/gfx/filters/perspective"
+	"github.com/toxyl/gfx/filters/pinch"
+	"github.com/toxyl/gfx/filters/ripple"
 	"github.com/toxyl/gfx/filters/rotate"
 	"github.com/toxyl/gfx/filters/sat"
 	"github.com/toxyl/gfx/filters/satcontrast"
 	"github.com/toxyl/gfx/filters/scale"
 	"github.com/toxyl/gfx/filters/sepia"
 	"github.com/toxyl/gfx/filters/sharpen"
+	"github.com/toxyl/gfx/filters/swirl"
 	"github.com/toxyl/gfx/filters/threshold"
 	"github.com/toxyl/gfx/filters/topolar"
 	"github.com/toxyl/gfx/filters/transform"
 	"github.com/toxyl/gfx/filters/translate"
 	"github.com/toxyl/gfx/filters/translatewrap"
 	"github.com/toxyl/gfx/filters/vibrance"
+	"github.com/toxyl/gfx/filters/wave"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
 	"github.com/toxyl/gfx/math"
 )
 
 type Image = image.Image
 type MetaData = meta.FilterMetaData
 type Filter = ImageFilter
-type FilterFn func(s *Filter, i *Image, m *MetaData)
+type FilterFn func(s *Filter, i *Image, m *MetaData) error
 
 type FilterMapEntry struct {
 	Fn   FilterFn
@@ -81,54 +95,66 @@ func NewFilterMap(entries ...*FilterMapEntry) *FilterMap {
 
 var (
 	Filters = NewFilterMap(
-		NewFilterMapEntry(flipv.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(flipv.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			flipv.Apply(i)
+			return nil
 		}),
-		NewFilterMapEntry(fliph.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(fliph.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			fliph.Apply(i)
+			return nil
 		}),
-		NewFilterMapEntry(rotate.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(rotate.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			rotate.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				resample.Kernel(s.GetOptionString(m.NameOf(3), m.DefaultOf(3))),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(crop.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(crop.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			crop.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
 				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(cropcircle.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(cropcircle.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			cropcircle.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(translate.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(translate.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			translate.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				resample.Kernel(s.GetOptionString(m.NameOf(2), m.DefaultOf(2))),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(translatewrap.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(translatewrap.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			translatewrap.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				resample.Kernel(s.GetOptionString(m.NameOf(2), m.DefaultOf(2))),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(scale.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(scale.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			scale.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				resample.Kernel(s.GetOptionString(m.NameOf(3), m.DefaultOf(3))),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(transform.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(transform.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			transform.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
@@ -136,113 +162,302 @@ var (
 				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
 				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
 				s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
+				resample.Kernel(s.GetOptionString(m.NameOf(6), m.DefaultOf(6))),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(gray.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(affine.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			affine.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
+				s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
+				s.GetOptionFloat64(m.NameOf(6), m.DefaultOf(6)),
+				s.GetOptionFloat64(m.NameOf(7), m.DefaultOf(7)),
+				s.GetOptionFloat64(m.NameOf(8), m.DefaultOf(8)),
+				resample.Kernel(s.GetOptionString(m.NameOf(9), m.DefaultOf(9))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(10), m.DefaultOf(10))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(matrix.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			matrix.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
+				s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
+				resample.Kernel(s.GetOptionString(m.NameOf(6), m.DefaultOf(6))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(7), m.DefaultOf(7))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(perspective.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			perspective.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
+				s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
+				s.GetOptionFloat64(m.NameOf(6), m.DefaultOf(6)),
+				s.GetOptionFloat64(m.NameOf(7), m.DefaultOf(7)),
+				resample.Kernel(s.GetOptionString(m.NameOf(8), m.DefaultOf(8))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(9), m.DefaultOf(9))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(gray.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			gray.Apply(i)
+			return nil
 		}),
-		NewFilterMapEntry(invert.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(invert.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			invert.Apply(i)
+			return nil
 		}),
-		NewFilterMapEntry(pastelize.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(pastelize.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			pastelize.Apply(i)
+			return nil
 		}),
-		NewFilterMapEntry(sepia.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(sepia.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			sepia.Apply(i)
+			return nil
 		}),
-		NewFilterMapEntry(hue.Meta, func(s *Filter, i *Image, m *MetaData) {
-			hue.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+		NewFilterMapEntry(hue.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			hue.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				image.ParseColorSpace(s.GetOptionString(m.NameOf(1), m.DefaultOf(1))),
+			)
+			return nil
 		}),
-		NewFilterMapEntry(sat.Meta, func(s *Filter, i *Image, m *MetaData) {
-			sat.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+		NewFilterMapEntry(sat.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			sat.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				image.ParseColorSpace(s.GetOptionString(m.NameOf(1), m.DefaultOf(1))),
+			)
+			return nil
 		}),
-		NewFilterMapEntry(lum.Meta, func(s *Filter, i *Image, m *MetaData) {
-			lum.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+		NewFilterMapEntry(lum.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			lum.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				image.ParseColorSpace(s.GetOptionString(m.NameOf(1), m.DefaultOf(1))),
+			)
+			return nil
 		}),
-		NewFilterMapEntry(huecontrast.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(huecontrast.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			huecontrast.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(satcontrast.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(satcontrast.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			satcontrast.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(lumcontrast.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(lumcontrast.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			lumcontrast.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(colorshift.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(colorshift.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			colorshift.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				image.ParseColorSpace(s.GetOptionString(m.NameOf(3), m.DefaultOf(3))),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(brightness.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(brightness.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			brightness.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(contrast.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(contrast.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			contrast.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(gamma.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(gamma.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			gamma.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(vibrance.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(vibrance.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			vibrance.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(enhance.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(enhance.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			enhance.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(sharpen.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(sharpen.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			sharpen.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(blur.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(blur.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			blur.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(edgedetect.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(edgedetect.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			edgedetect.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(emboss.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(emboss.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			emboss.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(threshold.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(threshold.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			threshold.Apply(i, s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)))
+			return nil
 		}),
-		NewFilterMapEntry(alphamap.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(alphamap.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			alphamap.Apply(i,
 				s.GetOptionString(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
 			)
+			return nil
 		}),
-		NewFilterMapEntry(extract.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(extract.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			extract.Apply(i, filter.ToColorFilter(
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)), s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)), s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
 				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)), s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)), s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
 				s.GetOptionFloat64(m.NameOf(6), m.DefaultOf(6)), s.GetOptionFloat64(m.NameOf(7), m.DefaultOf(7)), s.GetOptionFloat64(m.NameOf(8), m.DefaultOf(8)),
-			))
+			), image.ParseColorSpace(s.GetOptionString(m.NameOf(9), m.DefaultOf(9))))
+			return nil
 		}),
-		NewFilterMapEntry(convolution.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(convolution.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			matrix, err := s.GetOptionMatrix(m.NameOf(3), m.DefaultOf(3).([][]float64))
+			if err != nil {
+				return err
+			}
 			convolution.NewCustomFilter(
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				1.0+s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
 				func(a float64) [][]float64 {
-					return s.GetOptionMatrix(m.NameOf(3), m.DefaultOf(3).([][]float64))
+					return matrix
 				},
 			).Apply(i)
+			return nil
+		}),
+		NewFilterMapEntry(swirl.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			swirl.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				resample.Kernel(s.GetOptionString(m.NameOf(4), m.DefaultOf(4))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(5), m.DefaultOf(5))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(pinch.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			pinch.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				resample.Kernel(s.GetOptionString(m.NameOf(4), m.DefaultOf(4))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(5), m.DefaultOf(5))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(wave.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			wave.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
+				s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
+				resample.Kernel(s.GetOptionString(m.NameOf(6), m.DefaultOf(6))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(7), m.DefaultOf(7))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(ripple.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			ripple.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
+				resample.Kernel(s.GetOptionString(m.NameOf(5), m.DefaultOf(5))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(6), m.DefaultOf(6))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(lens.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			lens.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				resample.Kernel(s.GetOptionString(m.NameOf(4), m.DefaultOf(4))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(5), m.DefaultOf(5))),
+			)
+			return nil
+		}),
+		NewFilterMapEntry(frompolar.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			frompolar.Apply(i,
+				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
+				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
+				s.GetOptionFloat64(m.NameOf(4), m.DefaultOf(4)),
+				s.GetOptionFloat64(m.NameOf(5), m.DefaultOf(5)),
+				s.GetOptionFloat64(m.NameOf(6), m.DefaultOf(6)),
+				s.GetOptionFloat64(m.NameOf(7), m.DefaultOf(7)),
+				resample.Kernel(s.GetOptionString(m.NameOf(8), m.DefaultOf(8))),
+			)
+			return nil
 		}),
-		NewFilterMapEntry(topolar.Meta, func(s *Filter, i *Image, m *MetaData) {
+		NewFilterMapEntry(topolar.Meta, func(s *Filter, i *Image, m *MetaData) error {
 			topolar.Apply(i,
 				s.GetOptionFloat64(m.NameOf(0), m.DefaultOf(0)),
 				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
 				s.GetOptionFloat64(m.NameOf(2), m.DefaultOf(2)),
 				s.GetOptionFloat64(m.NameOf(3), m.DefaultOf(3)),
 			)
+			return nil
+		}),
+		NewFilterMapEntry(expression.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			f, err := compileExprFilter(s, m)
+			if err != nil {
+				return err
+			}
+			f.Apply(i)
+			return nil
 		}),
 	)
 )
 
+// Filters with image options resolve them with GetOptionImage, which applies filters itself.
+// They are registered here because referring to Filters while it is initialized is an initialization cycle.
+func init() {
+	for _, e := range []*FilterMapEntry{
+		NewFilterMapEntry(displace.Meta, func(s *Filter, i *Image, m *MetaData) error {
+			dmap, err := s.GetOptionImage(m.NameOf(0), i)
+			if err != nil {
+				return err
+			}
+			displace.Apply(i, dmap,
+				s.GetOptionFloat64(m.NameOf(1), m.DefaultOf(1)),
+				image.Channel(strings.ToLower(s.GetOptionString(m.NameOf(2), m.DefaultOf(2)))),
+				image.Channel(strings.ToLower(s.GetOptionString(m.NameOf(3), m.DefaultOf(3)))),
+				resample.Kernel(s.GetOptionString(m.NameOf(4), m.DefaultOf(4))),
+				resample.ParseEdge(s.GetOptionString(m.NameOf(5), m.DefaultOf(5))),
+			)
+			return nil
+		}),
+	} {
+		(*Filters)[e.Meta.Name] = e
+	}
+}
+
 type ImageFilter struct {
-	Type    string         `yaml:"type,omitempty"`
-	Options map[string]any `yaml:"options,omitempty"`
+	Type    string                     `yaml:"type,omitempty"`
+	Options map[string]any             `yaml:"options,omitempty"`
+	blocks  map[string]*CompiledFilter // filter blocks that image options can refer to (see GetOptionImage)
 }
 
 func (f *ImageFilter) String(verbose bool) string {
@@ -251,7 +466,7 @@ func (f *ImageFilter) String(verbose bool) string {
 	for _, opt := range m.Args {
 		k := opt.Name
 		v := opt.Default
-		if vopt, ok := f.Options[k]; ok {
+		if vopt, ok := f.Options[k]; ok && vopt != nil {
 			v = vopt
 		}
 		if verbose {
@@ -307,12 +522,17 @@ func (s *ImageFilter) GetOptionFloat64(option string, def any) float64 {
 func (s *ImageFilter) GetOptionString(option string, def any) string {
 	v, ok := s.Options[option]
 	if ok && v != nil {
-		return v.(string)
+		if str, ok := v.(string); ok {
+			return str
+		}
+		return fmt.Sprint(v)
 	}
 	return def.(string)
 }
 
-func (s *ImageFilter) GetOptionMatrix(option string, def [][]float64) [][]float64 {
+// GetOptionMatrix returns the square matrix an option refers to, or def if the option isn't set.
+// It returns an ArgumentError if the number of values isn't a perfect square.
+func (s *ImageFilter) GetOptionMatrix(option string, def [][]float64) ([][]float64, error) {
 	v, ok := s.Options[option]
 	if ok && v != nil {
 		in := v.([]float64)
@@ -322,8 +542,11 @@ func (s *ImageFilter) GetOptionMatrix(option string, def [][]float64) [][]float6
 
 		// Check if the length of the input slice is a perfect square
 		if rows*rows != len(in) {
-			fmt.Printf("Warning: input matrix is not a perfect square, falling back to default\n")
-			return def
+			return nil, &ArgumentError{
+				Name:  s.Type + STR_LPAREN + option + STR_RPAREN,
+				Value: in,
+				Msg:   "must have a perfect square number of values",
+			}
 		}
 
 		// Initialize the matrix
@@ -339,22 +562,87 @@ func (s *ImageFilter) GetOptionMatrix(option string, def [][]float64) [][]float6
 			}
 		}
 
-		return m
+		return m, nil
+	}
+	return def, nil
+}
+
+// GetOptionImage returns the image an option refers to, or nil if the option isn't set. The value can be
+// the name of a filter block defined before the filter, whose filters are then applied to a copy of i,
+// or a source like the ones layers use (a file, URL, CLI argument or generated source like a gradient).
+// Generated sources are rendered at the size of i.
+func (s *ImageFilter) GetOptionImage(option string, i *image.Image) (*image.Image, error) {
+	src := s.GetOptionString(option, "")
+	if src == "" {
+		return nil, nil
+	}
+	if f, ok := s.blocks[src]; ok {
+		return applyFilters(i.Context(), i.Clone(), f.Get(), nil, -1)
+	}
+	l := NewLayer()
+	l.Source = src
+	if err := l.load(i.Context(), i.W(), i.H(), resample.NEAREST); err != nil {
+		return nil, err
+	}
+	return l.data, nil
+}
+
+// Validate returns an UnknownFilterError if the filter type doesn't exist
+// and an ArgumentError if an option has a value that isn't allowed.
+func (s *ImageFilter) Validate() error {
+	m, _ := Filters.Get(strings.ToLower(s.Type))
+	if m == nil {
+		return &UnknownFilterError{Name: s.Type}
 	}
-	return def
+	for _, a := range m.Args {
+		v, ok := s.Options[a.Name]
+		if !ok || v == nil || len(a.Values) == 0 {
+			continue
+		}
+		if !slices.Contains(a.Values, strings.ToLower(fmt.Sprint(v))) {
+			return &ArgumentError{
+				Name:  s.Type + STR_LPAREN + a.Name + STR_RPAREN,
+				Value: v,
+				Msg:   "must be one of " + strings.Join(a.Values, ", "),
+			}
+		}
+	}
+	return nil
 }
 
+// Apply applies the filter to i, errors of the filter (like a displacement map that can't be loaded)
+// leave i unchanged, use ApplyContext to get them.
 func (s *ImageFilter) Apply(i *image.Image) *image.Image {
+	_ = s.apply(i)
+	return i
+}
+
+func (s *ImageFilter) apply(i *image.Image) error {
 	if s.Type == "" {
-		return i
+		return nil
 	}
 	m, fn := Filters.Get(strings.ToLower(s.Type))
-	if m != nil && fn != nil {
-		fn(s, i, m)
-		return i
+	if m == nil || fn == nil {
+		return &UnknownFilterError{Name: s.Type}
 	}
-	fmt.Printf("Error: unknown filter type: %s\n", s.Type)
-	return i
+	return fn(s, i, m)
+}
+
+// ApplyContext is like Apply but returns the error of the filter, or ctx.Err() if ctx is cancelled
+// before or while the filter runs.
+func (s *ImageFilter) ApplyContext(ctx context.Context, i *image.Image) (*image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := i.WithContext(ctx)
+	if err := s.apply(c); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	i.Set(c.Get())
+	return i, nil
 }
 
 func NewImageFilter(typ string, options map[string]any) *ImageFilter {
@@ -1,27 +1,32 @@
 package parser
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"strconv"
 
 	"github.com/toxyl/gfx/color/blend"
 	"github.com/toxyl/gfx/image"
-	"github.com/toxyl/gfx/net"
+	"github.com/toxyl/gfx/image/resample"
 )
 
 type Layer struct {
-	data      *image.Image    `yaml:"-"`
-	Source    string          `yaml:"src,omitempty"`
-	BlendMode string          `yaml:"blend,omitempty"`
-	Alpha     float64         `yaml:"alpha,omitempty"`
-	Crop      *Crop           `yaml:"crop,omitempty"`
-	Offset    *Offset         `yaml:"offset,omitempty"`
-	Resize    *Resize         `yaml:"resize,omitempty"`
-	Filter    *CompiledFilter `yaml:"filter,omitempty"`
+	data       *image.Image    `yaml:"-"`
+	Source     string          `yaml:"src,omitempty"`
+	BlendMode  string          `yaml:"blend,omitempty"`
+	Alpha      float64         `yaml:"alpha,omitempty"`
+	Crop       *Crop           `yaml:"crop,omitempty"`
+	Offset     *Offset         `yaml:"offset,omitempty"`
+	Resize     *Resize         `yaml:"resize,omitempty"`
+	Resample   string          `yaml:"resample,omitempty"`
+	BlendSpace string          `yaml:"blend-space,omitempty"` // overrides the blend space of the composition
+	Composite  string          `yaml:"composite,omitempty"`   // Porter-Duff operator, the legacy over formula is used if empty
+	Seed       uint64          `yaml:"seed,omitempty"`        // selects the pixels that pass when the layer is dissolved
+	Filter     *CompiledFilter `yaml:"filter,omitempty"`
 }
 
-func (l *Layer) String(compHasCrop, compHasResize, compHasOffset, compHasFilter bool) string {
+func (l *Layer) String(compHasCrop, compHasResize, compHasOffset, compHasResample, compHasBlendSpace, compHasComposite, compHasSeed, compHasFilter bool) string {
 	resize := "                "
 	wresize := len(resize)
 	if !compHasResize {
@@ -49,46 +54,105 @@ func (l *Layer) String(compHasCrop, compHasResize, compHasOffset, compHasFilter
 	if l.Offset != nil {
 		offset = fmt.Sprintf("%*s", woffset, l.Offset.String())
 	}
+	kernel := "                 "
+	wkernel := len(kernel)
+	if !compHasResample {
+		kernel = ""
+		wkernel = 0
+	}
+	if l.Resample != "" {
+		kernel = fmt.Sprintf("%*s", wkernel, fmt.Sprintf("%s %8s", LAYER_RESAMPLE, l.Resample))
+	}
+	space := "                  "
+	wspace := len(space)
+	if !compHasBlendSpace {
+		space = ""
+		wspace = 0
+	}
+	if l.BlendSpace != "" {
+		space = fmt.Sprintf("%*s", wspace, fmt.Sprintf("%s %6s", LAYER_BLEND_SPACE, l.BlendSpace))
+	}
+	op := "                  "
+	wop := len(op)
+	if !compHasComposite {
+		op = ""
+		wop = 0
+	}
+	if l.Composite != "" {
+		op = fmt.Sprintf("%*s", wop, fmt.Sprintf("%s %8s", LAYER_COMPOSITE, l.Composite))
+	}
+	seed := "               "
+	wseed := len(seed)
+	if !compHasSeed {
+		seed = ""
+		wseed = 0
+	}
+	if l.Seed != 0 {
+		seed = fmt.Sprintf("%*s", wseed, fmt.Sprintf("%s %10d", LAYER_SEED, l.Seed))
+	}
 	filter := "               *"
 	wfilter := len(filter)
 	if !compHasFilter {
-		filter = ""
-		wfilter = 0
+		filter = "*" // the column can't be left out, the parser expects a filter before the source
+		wfilter = len(filter)
 	}
 	if l.Filter != nil {
 		filter = fmt.Sprintf("%*s", wfilter, l.Filter.Name)
 	}
 	return fmt.Sprintf(
-		"%16s %6.4f %s %s %s %s %s",
+		"%16s %6.4f %s %s %s %s %s %s %s %s %s",
 		l.BlendMode,
 		l.Alpha,
 		filter,
 		resize,
 		crop,
 		offset,
+		kernel,
+		space,
+		op,
+		seed,
 		l.Source,
 	)
 }
 
-func (l *Layer) load() {
-	if l.Source != "" {
-		if l.Source[0] == CHAR_CLI_ARG {
-			if i, err := strconv.Atoi(l.Source[1:]); err == nil {
-				l.Source = flag.Arg(i)
-				if l.Source == "" {
-					panic("missing argument $" + fmt.Sprint(i) + " (hint: numbering starts at 0)")
-				}
+// load loads the image data of the layer, generated sources (like gradients) are rendered at w x h pixels.
+// The sources of band maths are resized to w x h with kernel.
+func (l *Layer) load(ctx context.Context, w, h int, kernel resample.Kernel) error {
+	if l.Source == "" {
+		if l.data == nil {
+			return &ArgumentError{Name: "source", Value: l.Source, Msg: "layer has neither a source nor image data"}
+		}
+		return nil // image data was set with LoadFromImage
+	}
+	if l.Source[0] == CHAR_CLI_ARG {
+		if i, err := strconv.Atoi(l.Source[1:]); err == nil {
+			src := flag.Arg(i)
+			if src == "" {
+				return &ArgumentError{Name: l.Source, Value: src, Msg: "missing argument (hint: numbering starts at 0)"}
 			}
+			l.Source = src
 		}
-		l.data = image.NewFromURL(l.Source)
-		if l.data == nil && net.IsURL(l.Source) {
-			return // URL failed to load
+	}
+	if b, ok, err := parseBands(l.Source); ok {
+		if err != nil {
+			return err
 		}
-		if l.data == nil {
-			// this wasn't a URL, maybe it's a file
-			l.data = image.NewFromFile(l.Source)
+		l.data, err = b.render(ctx, w, h, kernel)
+		return err
+	}
+	if gen, ok, err := parseGenerator(l.Source); ok {
+		if err != nil {
+			return err
 		}
+		l.data = gen(w, h)
+		return nil
 	}
+	data, err := image.OpenContext(ctx, l.Source)
+	if err != nil {
+		return err
+	}
+	l.data = data
+	return nil
 }
 
 func (l *Layer) LoadFromImage(i *image.Image) *Layer {
@@ -114,22 +178,53 @@ func (l *Layer) SetAlpha(alpha float64) *Layer {
 	return l
 }
 
-func (l *Layer) Render(w, h int) *image.Image {
-	l.load()
-	if l.data == nil {
-		return nil
+func (l *Layer) SetResample(kernel resample.Kernel) *Layer {
+	l.Resample = string(kernel)
+	return l
+}
+
+func (l *Layer) SetBlendSpace(space blend.BlendSpace) *Layer {
+	l.BlendSpace = string(space)
+	return l
+}
+
+func (l *Layer) SetComposite(op blend.CompositeOp) *Layer {
+	l.Composite = string(op)
+	return l
+}
+
+func (l *Layer) SetSeed(seed uint64) *Layer {
+	l.Seed = seed
+	return l
+}
+
+// Render renders the layer at w x h pixels.
+// Resizing uses the layer's resampling kernel or nearest-neighbor if none is set.
+// Rendering stops with the context's error if ctx is cancelled.
+func (l *Layer) Render(ctx context.Context, w, h int) (*image.Image, error) {
+	return l.render(ctx, w, h, resample.NEAREST, nil, -1)
+}
+
+// render renders the layer at w x h pixels, kernel is used if the layer doesn't define its own resampling kernel.
+// Loading and every filter advance p by one step, index is the layer index reported to p.
+func (l *Layer) render(ctx context.Context, w, h int, kernel resample.Kernel, p *progress, index int) (*image.Image, error) {
+	if l.Resample != "" {
+		kernel = resample.Kernel(l.Resample)
 	}
-	res := l.data.Resize(w, h)
+	p.step(STAGE_LOAD, index, "")
+	if err := l.load(ctx, w, h, kernel); err != nil {
+		return nil, err
+	}
+	res := l.data.ResizeWith(w, h, kernel)
 	if l.Filter != nil {
-		for _, filter := range l.Filter.Get() {
-			if filter != nil {
-				res = filter.Apply(res)
-			}
+		var err error
+		if res, err = applyFilters(ctx, res, l.Filter.Get(), p, index); err != nil {
+			return nil, err
 		}
 	}
 	if l.Resize != nil && l.Resize.W > 0 && l.Resize.H > 0 {
 		res2 := image.New(w, h)
-		res2.Draw(res.Resize(l.Resize.W, l.Resize.H), 0, 0, l.Resize.W, l.Resize.H, (w-l.Resize.W)/2, (h-l.Resize.H)/2, l.Resize.W, l.Resize.H, blend.NORMAL, 1)
+		res2.Draw(res.ResizeWith(l.Resize.W, l.Resize.H, kernel), 0, 0, l.Resize.W, l.Resize.H, (w-l.Resize.W)/2, (h-l.Resize.H)/2, l.Resize.W, l.Resize.H, blend.NORMAL, 1)
 		res = res2
 	}
 	if l.Crop != nil && l.Crop.W > 0 && l.Crop.H > 0 {
@@ -138,19 +233,23 @@ func (l *Layer) Render(w, h int) *image.Image {
 	if l.Offset != nil && (l.Offset.X != 0 || l.Offset.Y != 0) {
 		res = res.Offset(l.Offset.X, l.Offset.Y)
 	}
-	return res
+	return res, nil
 }
 
 func NewLayer() *Layer {
 	l := Layer{
-		data:      nil,
-		Source:    "",
-		BlendMode: string(blend.NORMAL),
-		Alpha:     1,
-		Crop:      nil,
-		Offset:    nil,
-		Resize:    nil,
-		Filter:    nil,
+		data:       nil,
+		Source:     "",
+		BlendMode:  string(blend.NORMAL),
+		Alpha:      1,
+		Crop:       nil,
+		Offset:     nil,
+		Resize:     nil,
+		Resample:   "",
+		BlendSpace: "",
+		Composite:  "",
+		Seed:       0,
+		Filter:     nil,
 	}
 	return &l
 }
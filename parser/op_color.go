@@ -7,15 +7,19 @@ import (
 	"github.com/toxyl/gfx/color/hsla"
 )
 
-func parseColor(value string) *hsla.HSLA {
+func parseColor(value string) (*hsla.HSLA, error) {
 	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(strings.ToLower(value)), "hsla"+STR_LPAREN), STR_RPAREN)
-	parts := strings.Split(value, STR_SPACE)
+	parts := strings.Fields(value)
 	if len(parts) != 4 {
-		panic("composition color must be given as `hsla" + STR_LPAREN + "hue" + STR_SPACE + "sat" + STR_SPACE + "lum" + STR_SPACE + "alpha" + STR_RPAREN + "`")
+		return nil, newParseError(value, "composition color must be given as `hsla"+STR_LPAREN+"hue"+STR_SPACE+"sat"+STR_SPACE+"lum"+STR_SPACE+"alpha"+STR_RPAREN+"`")
 	}
-	h, _ := strconv.ParseFloat(parts[0], 64)
-	s, _ := strconv.ParseFloat(parts[1], 64)
-	l, _ := strconv.ParseFloat(parts[2], 64)
-	a, _ := strconv.ParseFloat(parts[3], 64)
-	return hsla.New(h, s, l, a)
+	v := [4]float64{}
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, newParseError(value, "invalid color component: %s", p)
+		}
+		v[i] = f
+	}
+	return hsla.New(v[0], v[1], v[2], v[3]), nil
 }
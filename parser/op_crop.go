@@ -17,11 +17,27 @@ func (c *Crop) String() string {
 	return fmt.Sprintf("%s %4d %4d %4d %4d", LAYER_CROP, c.X, c.Y, c.W, c.H)
 }
 
-func parseCrop(value string) Crop {
-	parts := strings.Split(value, STR_SPACE)
-	x, _ := strconv.Atoi(parts[0])
-	y, _ := strconv.Atoi(parts[1])
-	w, _ := strconv.Atoi(parts[2])
-	h, _ := strconv.Atoi(parts[3])
-	return Crop{X: x, Y: y, W: w, H: h}
+func parseCrop(value string) (Crop, error) {
+	v, err := parseInts(value, 4)
+	if err != nil {
+		return Crop{}, err
+	}
+	return Crop{X: v[0], Y: v[1], W: v[2], H: v[3]}, nil
+}
+
+// parseInts parses exactly n whitespace-separated integers.
+func parseInts(value string, n int) ([]int, error) {
+	parts := strings.Fields(value)
+	if len(parts) != n {
+		return nil, newParseError(value, "expected %d values, got %d", n, len(parts))
+	}
+	res := make([]int, n)
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, newParseError(value, "not an integer: %s", p)
+		}
+		res[i] = v
+	}
+	return res, nil
 }
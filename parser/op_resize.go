@@ -2,8 +2,6 @@ package parser
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 )
 
 type Resize struct {
@@ -15,9 +13,10 @@ func (r *Resize) String() string {
 	return fmt.Sprintf("%s %4d %4d", LAYER_RESIZE, r.W, r.H)
 }
 
-func parseResize(value string) Resize {
-	parts := strings.Split(value, STR_SPACE)
-	w, _ := strconv.Atoi(parts[0])
-	h, _ := strconv.Atoi(parts[1])
-	return Resize{W: w, H: h}
+func parseResize(value string) (Resize, error) {
+	v, err := parseInts(value, 2)
+	if err != nil {
+		return Resize{}, err
+	}
+	return Resize{W: v[0], H: v[1]}, nil
 }
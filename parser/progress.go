@@ -0,0 +1,71 @@
+package parser
+
+import "github.com/toxyl/gfx/math"
+
+// Stage is the kind of work a render step does.
+type Stage string
+
+const (
+	STAGE_LOAD   Stage = "load"   // loading and resizing a layer source
+	STAGE_FILTER Stage = "filter" // applying a filter of a layer or of the composition
+	STAGE_BLEND  Stage = "blend"  // blending a layer onto the layers below it
+	STAGE_FINISH Stage = "finish" // cropping and resizing the composition
+	STAGE_DONE   Stage = "done"   // the render is complete
+)
+
+// Progress describes the render step that is about to run.
+type Progress struct {
+	Stage   Stage
+	Layer   int     // index of the layer in Composition.Layers, -1 for steps of the composition itself
+	Filter  string  // type of the filter, only set for STAGE_FILTER
+	Percent float64 // share of completed steps, from 0 to 100
+}
+
+// ProgressFunc receives progress updates. It is called synchronously by the render, so it should return quickly.
+type ProgressFunc func(p Progress)
+
+// progress counts the steps of a render and reports each one to fn. A nil *progress ignores all calls.
+type progress struct {
+	fn    ProgressFunc
+	steps int
+	done  int
+}
+
+func (p *progress) step(stage Stage, layer int, filter string) {
+	if p == nil {
+		return
+	}
+	if p.fn != nil {
+		pct := 100.0
+		if p.steps > 0 {
+			pct = math.Min(100, 100*float64(p.done)/float64(p.steps))
+		}
+		p.fn(Progress{Stage: stage, Layer: layer, Filter: filter, Percent: pct})
+	}
+	p.done++
+}
+
+func (p *progress) finish() {
+	if p == nil || p.fn == nil {
+		return
+	}
+	p.fn(Progress{Stage: STAGE_DONE, Layer: -1, Percent: 100})
+}
+
+func newProgress(fn ProgressFunc, steps int) *progress {
+	return &progress{fn: fn, steps: steps}
+}
+
+// countFilters returns the number of filters of f that will be applied.
+func countFilters(f *CompiledFilter) int {
+	if f == nil {
+		return 0
+	}
+	n := 0
+	for _, filter := range f.Get() {
+		if filter != nil {
+			n++
+		}
+	}
+	return n
+}
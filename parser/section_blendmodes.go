@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/math/expr"
+)
+
+var reBlendModeName = regexp.MustCompile(`^` + WORD_PATTERN + `$`)
+
+// parseBlendModesSection parses a user-defined blend mode (`name = expression`) and adds it to modes.
+func parseBlendModesSection(line string, modes map[string]string) error {
+	parts := strings.SplitN(line, STR_ASSIGN, 2)
+	if len(parts) != 2 {
+		return newParseError(line, "blend modes must be defined as `name "+STR_ASSIGN+" expression`")
+	}
+	name := strings.TrimSpace(parts[0])
+	value := strings.TrimSpace(parts[1])
+	if !reBlendModeName.MatchString(name) {
+		return newParseError(name, "invalid blend mode name")
+	}
+	if _, ok := blend.BlendModes[blend.BlendMode(name)]; ok {
+		return newParseError(name, "can't redefine a built-in blend mode")
+	}
+	if _, err := compileBlendMode(value); err != nil {
+		return newParseError(line, "%s", err)
+	}
+	modes[name] = value
+	return nil
+}
+
+// compileBlendMode compiles the expression of a user-defined blend mode.
+func compileBlendMode(src string) (blend.CustomFunc, error) {
+	e, err := expr.Compile(src, BLENDMODE_BASE, BLENDMODE_BLEND, BLENDMODE_ALPHA)
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b, alpha float64) float64 {
+		return e.Eval([]float64{a, b, alpha})
+	}, nil
+}
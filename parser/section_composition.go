@@ -2,72 +2,156 @@ package parser
 
 import (
 	"bufio"
+	"errors"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/image/resample"
 )
 
-func parseCompositionSection(line string, comp *Composition, filters map[string]*CompiledFilter) {
+func parseCompositionSection(line string, comp *Composition, filters map[string]*CompiledFilter) error {
 	parts := strings.SplitN(line, STR_ASSIGN, 2)
+	if len(parts) != 2 {
+		return newParseError(line, "composition settings must be defined as `setting "+STR_ASSIGN+" value`")
+	}
 	key := strings.TrimSpace(parts[0])
 	value := strings.TrimSpace(parts[1])
+	var err error
 	switch key {
 	case COMP_FILTER:
-		comp.Filter.Append(filters[value].Filters...)
+		f, ok := filters[value]
+		if !ok {
+			return &UnknownFilterError{Name: value}
+		}
+		comp.Filter.Append(f.Filters...)
 	case COMP_CROP:
-		crop := parseCrop(value)
+		var crop Crop
+		crop, err = parseCrop(value)
 		comp.Crop = &crop
 	case COMP_RESIZE:
-		resize := parseResize(value)
+		var resize Resize
+		resize, err = parseResize(value)
 		comp.Resize = &resize
+	case COMP_RESAMPLE:
+		if comp.Resample = string(resample.Parse(value)); comp.Resample == "" {
+			err = newParseError(value, "unknown resampling kernel, must be one of: %s", strings.Join(resample.AllNames(), ", "))
+		}
+	case COMP_BLEND_SPACE:
+		if comp.BlendSpace = string(blend.ParseBlendSpace(value)); comp.BlendSpace == "" {
+			err = newParseError(value, "unknown blend space, must be one of: %s", strings.Join(blend.BlendSpaces(), ", "))
+		}
 	case COMP_COLOR:
-		comp.Color = parseColor(value)
+		comp.Color, comp.Background = nil, ""
+		if _, ok, gerr := parseGenerator(value); ok {
+			comp.Background, err = value, gerr
+		} else {
+			comp.Color, err = parseColor(value)
+		}
 	case COMP_NAME:
 		comp.Name = strings.Trim(value, STR_QUOTE)
 	case COMP_WIDTH:
-		comp.Width, _ = strconv.Atoi(value)
+		if comp.Width, err = strconv.Atoi(value); err != nil {
+			err = newParseError(value, "width must be an integer")
+		}
 	case COMP_HEIGHT:
-		comp.Height, _ = strconv.Atoi(value)
+		if comp.Height, err = strconv.Atoi(value); err != nil {
+			err = newParseError(value, "height must be an integer")
+		}
+	default:
+		err = newParseError(key, "unknown composition setting")
 	}
+	return err
+}
+
+// withLine adds the line number (and the line if the error doesn't have any text yet) to parse errors.
+func withLine(err error, num int, line string) error {
+	var pe *ParseError
+	if errors.As(err, &pe) && pe.Line == 0 {
+		pe.Line = num
+		if pe.Text == "" {
+			pe.Text = line
+		}
+	}
+	var ufe *UnknownFilterError
+	if errors.As(err, &ufe) && ufe.Line == 0 {
+		ufe.Line = num
+	}
+	return err
 }
 
 func ParseComposition(content string) (*Composition, error) {
 	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			lineNum++ // we keep track of the line number, so we can report it in errors
+		}
+		return advance, token, err
+	})
 	comp := Composition{
-		Name:   "",
-		Width:  0,
-		Height: 0,
-		Layers: []*Layer{},
-		Crop:   &Crop{},
-		Resize: &Resize{},
-		Filter: NewCompiledFilter("compFilter"),
+		Name:       "",
+		Width:      0,
+		Height:     0,
+		Layers:     []*Layer{},
+		BlendModes: map[string]string{},
+		Crop:       &Crop{},
+		Resize:     &Resize{},
+		Filter:     NewCompiledFilter("compFilter"),
 	}
 	var currentSection string
 	vars := make(map[string]string)
 	fltrs := make(map[string]*CompiledFilter)
 
 	for scanner.Scan() {
-		line := trimCommentsAndWhitespace(scanner.Text())
+		raw := scanner.Text()
+		line, err := trimCommentsAndWhitespace(raw)
+		if err != nil {
+			return nil, withLine(err, lineNum, raw)
+		}
 		if line == "" {
 			continue
 		}
 		if s := parseSection(line); s != "" {
+			if !slices.Contains(SECTIONS, strings.ToUpper(s)) {
+				return nil, withLine(newParseError(line, "unknown section"), lineNum, line)
+			}
 			currentSection = s
 			continue
 		}
 		switch strings.ToUpper(currentSection) {
 		case SECTION_VARS:
-			parseVarsSection(line, vars)
+			err = parseVarsSection(line, vars)
 		case SECTION_FILTERS:
-			filterName, filterLines := parseFilterBlock(line, scanner)
-			if filterName != "" {
-				fltrs[filterName] = NewCompiledFilter(filterName).Append(parseFilters(filterLines, vars, fltrs)...)
+			var filterName string
+			var filterLines []string
+			var filters []*ImageFilter
+			filterName, filterLines, err = parseFilterBlock(line, scanner)
+			if err == nil && filterName != "" {
+				if filters, err = parseFilters(filterLines, vars, fltrs); err == nil {
+					fltrs[filterName] = NewCompiledFilter(filterName).Append(filters...)
+				}
 			}
+		case SECTION_BLENDMODES:
+			err = parseBlendModesSection(line, comp.BlendModes)
 		case SECTION_COMPOSITION:
-			parseCompositionSection(line, &comp, fltrs)
+			err = parseCompositionSection(line, &comp, fltrs)
 		case SECTION_LAYERS:
-			layer := parseLayer(line, fltrs)
-			comp.Layers = append(comp.Layers, &layer)
+			var layer Layer
+			if layer, err = parseLayer(line, fltrs, comp.BlendModes); err == nil {
+				comp.Layers = append(comp.Layers, &layer)
+			}
+		default:
+			err = newParseError(line, "statement outside of a known section")
 		}
+		if err != nil {
+			return nil, withLine(err, lineNum, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &IOError{Op: "read", Path: "composition", Err: err}
 	}
 
 	return &comp, nil
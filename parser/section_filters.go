@@ -2,14 +2,15 @@ package parser
 
 import (
 	"bufio"
-	"fmt"
 	"strings"
+
+	"github.com/toxyl/gfx/filters/expression"
 )
 
-func parseFilterBlock(line string, scanner *bufio.Scanner) (string, []string) {
+func parseFilterBlock(line string, scanner *bufio.Scanner) (string, []string, error) {
 	line = strings.TrimSpace(line)
 	if !strings.Contains(line, STR_LBRACE) {
-		return "", nil // this is not a filter block
+		return "", nil, newParseError(line, "filters must be defined as `name "+STR_LBRACE+" filter(...) "+STR_RBRACE+"`")
 	}
 	idxLBrace := strings.Index(line, STR_LBRACE)
 	idxLast := len(line) - 1
@@ -20,14 +21,18 @@ func parseFilterBlock(line string, scanner *bufio.Scanner) (string, []string) {
 		// select everything up to there and replace linebreaks with spaces,
 		// so we don't have to worry about the amount of filters defined per line,
 		// in the next step we will split them into single filters
+		filterData = strings.TrimSpace(line[idxLBrace+1:])
+		isEnd := false
 		for scanner.Scan() {
-			nextLine := trimCommentsAndWhitespace(scanner.Text())
+			nextLine, err := trimCommentsAndWhitespace(scanner.Text())
+			if err != nil {
+				return "", nil, err
+			}
 			if nextLine == "" {
 				continue
 			}
 			// the line might contain an STR_RBRACE in a string, so we have to check char by char
 			inQuote := false
-			isEnd := false
 			for i, c := range nextLine {
 				shouldBreak := false
 				switch c {
@@ -35,21 +40,27 @@ func parseFilterBlock(line string, scanner *bufio.Scanner) (string, []string) {
 					if inQuote && nextLine[i-1] == CHAR_ESCAPE {
 						continue // this is an escaped quote
 					}
+					inQuote = !inQuote // toggle quote status
 				case CHAR_RBRACE:
 					if !inQuote {
-						// this is the end of the filter block
+						// this is the end of the filter block,
+						// but there might be filters in front of it
 						isEnd = true
 						shouldBreak = true
+						nextLine = nextLine[:i]
 					}
 				}
 				if shouldBreak {
 					break
 				}
 			}
+			filterData += STR_SPACE + nextLine
 			if isEnd {
 				break
 			}
-			filterData += STR_SPACE + nextLine
+		}
+		if !isEnd {
+			return "", nil, newParseError(line, "filter block is missing its closing `"+STR_RBRACE+"`")
 		}
 	} else {
 		// this is a single line filter block
@@ -81,25 +92,40 @@ func parseFilterBlock(line string, scanner *bufio.Scanner) (string, []string) {
 			}
 		}
 	}
-	return filterName, filterLines
+	if rest := strings.TrimSpace(filterData[idxCurrent:]); rest != "" {
+		return "", nil, newParseError(rest, "incomplete filter in block %s", filterName)
+	}
+	return filterName, filterLines, nil
 }
 
-func parseFilters(lines []string, vars map[string]string, fltrs map[string]*CompiledFilter) []*ImageFilter {
+func parseFilters(lines []string, vars map[string]string, fltrs map[string]*CompiledFilter) ([]*ImageFilter, error) {
 	parsedFilters := []*ImageFilter{}
 	lkw := len(KEYWORD_USE) + 1
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		filterType := line[:strings.Index(line, STR_LPAREN)]
+		idxLParen := strings.Index(line, STR_LPAREN)
+		if idxLParen < 0 {
+			return nil, newParseError(line, "filters must be called as `filter"+STR_LPAREN+"args"+STR_RPAREN+"`")
+		}
+		filterType := strings.TrimSpace(line[:idxLParen])
 		if strings.EqualFold(filterType, KEYWORD_USE) {
 			filterName := strings.TrimSpace(line[lkw : len(line)-1])
-			parsedFilters = append(parsedFilters, fltrs[filterName].Get()...)
+			f, ok := fltrs[filterName]
+			if !ok {
+				return nil, &UnknownFilterError{Name: filterName}
+			}
+			parsedFilters = append(parsedFilters, f.Get()...)
 			continue
 		}
-		filterArgs := strings.TrimSpace(line[strings.Index(line, STR_LPAREN)+1 : strings.Index(line, STR_RPAREN)])
+		if m, _ := Filters.Get(strings.ToLower(filterType)); m == nil {
+			return nil, &UnknownFilterError{Name: filterType}
+		}
+		filterArgs := strings.TrimSpace(line[idxLParen+1 : len(line)-1])
 
 		filter := &ImageFilter{
 			Type:    filterType,
 			Options: make(map[string]any),
+			blocks:  fltrs,
 		}
 
 		if filterArgs != "" {
@@ -107,12 +133,20 @@ func parseFilters(lines []string, vars map[string]string, fltrs map[string]*Comp
 				parseNamedArgs(filterArgs, filter, vars)
 			} else {
 				if err := parseUnnamedArgs(filterArgs, filter, vars); err != nil {
-					fmt.Printf("Error parsing unnamed arguments for filter %s: %v\n", filterType, err)
+					return nil, err
 				}
 			}
 		}
+		if err := filter.Validate(); err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(filterType, expression.Meta.Name) {
+			if err := prepareExprFilter(filter, vars); err != nil {
+				return nil, newParseError(line, "%s", err)
+			}
+		}
 
 		parsedFilters = append(parsedFilters, filter)
 	}
-	return parsedFilters
+	return parsedFilters, nil
 }
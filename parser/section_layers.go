@@ -3,59 +3,126 @@ package parser
 import (
 	"strconv"
 	"strings"
-)
 
-func parseInt(s string) int {
-	i, _ := strconv.Atoi(s)
-	return i
-}
+	"github.com/toxyl/gfx/color/blend"
+	"github.com/toxyl/gfx/image/resample"
+)
 
-func parseLayer(line string, filters map[string]*CompiledFilter) Layer {
+func parseLayer(line string, filters map[string]*CompiledFilter, modes map[string]string) (Layer, error) {
 	line = strings.TrimSpace(line)
 	parts := strings.Fields(line)
+	if len(parts) < 4 {
+		return Layer{}, newParseError(line, "layers must be defined as `mode alpha filter [ops] source`")
+	}
 	blendMode := parts[0]
-	alpha, _ := strconv.ParseFloat(parts[1], 64)
+	if _, ok := blend.BlendModes[blend.BlendMode(blendMode)]; !ok && modes[blendMode] == "" {
+		return Layer{}, newParseError(blendMode, "unknown blend mode")
+	}
+	alpha, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return Layer{}, newParseError(parts[1], "alpha must be a number")
+	}
 	filterName := parts[2]
+	filter, ok := filters[filterName]
+	if !ok && filterName != "*" {
+		return Layer{}, &UnknownFilterError{Name: filterName}
+	}
 	var crop *Crop
 	var offset *Offset
 	var resize *Resize
+	var kernel string
+	var space string
+	var op string
+	var seed uint64
 	var src string
 
+	// args returns the n values following the layer operation at index i
+	args := func(i, n int) ([]int, error) {
+		if i+n >= len(parts) {
+			return nil, newParseError(line, "%s requires %d values and a source", parts[i], n)
+		}
+		return parseInts(strings.Join(parts[i+1:i+1+n], STR_SPACE), n)
+	}
+
 	for i := 3; i < len(parts); {
 		switch parts[i] {
 		case LAYER_CROP:
-			crop = &Crop{
-				X: parseInt(parts[i+1]),
-				Y: parseInt(parts[i+2]),
-				W: parseInt(parts[i+3]),
-				H: parseInt(parts[i+4]),
+			v, err := args(i, 4)
+			if err != nil {
+				return Layer{}, err
 			}
+			crop = &Crop{X: v[0], Y: v[1], W: v[2], H: v[3]}
 			i += 5
 		case LAYER_OFFSET:
-			offset = &Offset{
-				X: parseInt(parts[i+1]),
-				Y: parseInt(parts[i+2]),
+			v, err := args(i, 2)
+			if err != nil {
+				return Layer{}, err
 			}
+			offset = &Offset{X: v[0], Y: v[1]}
 			i += 3
 		case LAYER_RESIZE:
-			resize = &Resize{
-				W: parseInt(parts[i+1]),
-				H: parseInt(parts[i+2]),
+			v, err := args(i, 2)
+			if err != nil {
+				return Layer{}, err
 			}
+			resize = &Resize{W: v[0], H: v[1]}
 			i += 3
+		case LAYER_RESAMPLE:
+			if i+2 >= len(parts) {
+				return Layer{}, newParseError(line, "%s requires a kernel and a source", LAYER_RESAMPLE)
+			}
+			if kernel = string(resample.Parse(parts[i+1])); kernel == "" {
+				return Layer{}, newParseError(parts[i+1], "unknown resampling kernel, must be one of: %s", strings.Join(resample.AllNames(), ", "))
+			}
+			i += 2
+		case LAYER_BLEND_SPACE:
+			if i+2 >= len(parts) {
+				return Layer{}, newParseError(line, "%s requires a blend space and a source", LAYER_BLEND_SPACE)
+			}
+			if space = string(blend.ParseBlendSpace(parts[i+1])); space == "" {
+				return Layer{}, newParseError(parts[i+1], "unknown blend space, must be one of: %s", strings.Join(blend.BlendSpaces(), ", "))
+			}
+			i += 2
+		case LAYER_COMPOSITE:
+			if i+2 >= len(parts) {
+				return Layer{}, newParseError(line, "%s requires an operator and a source", LAYER_COMPOSITE)
+			}
+			if op = string(blend.ParseCompositeOp(parts[i+1])); op == "" {
+				return Layer{}, newParseError(parts[i+1], "unknown composite operator")
+			}
+			i += 2
+		case LAYER_SEED:
+			if i+2 >= len(parts) {
+				return Layer{}, newParseError(line, "%s requires a number and a source", LAYER_SEED)
+			}
+			if seed, err = strconv.ParseUint(parts[i+1], 10, 64); err != nil {
+				return Layer{}, newParseError(parts[i+1], "seed must be a positive integer")
+			}
+			i += 2
 		default:
 			src = strings.Join(parts[i:], STR_SPACE)
 			i = len(parts)
 		}
 	}
 
-	return Layer{
-		Source:    src,
-		BlendMode: blendMode,
-		Alpha:     alpha,
-		Crop:      crop,
-		Offset:    offset,
-		Resize:    resize,
-		Filter:    filters[filterName],
+	if _, _, err := parseGenerator(src); err != nil {
+		return Layer{}, err
+	}
+	if _, _, err := parseBands(src); err != nil {
+		return Layer{}, err
 	}
+
+	return Layer{
+		Source:     src,
+		BlendMode:  blendMode,
+		Alpha:      alpha,
+		Crop:       crop,
+		Offset:     offset,
+		Resize:     resize,
+		Resample:   kernel,
+		BlendSpace: space,
+		Composite:  op,
+		Seed:       seed,
+		Filter:     filter,
+	}, nil
 }
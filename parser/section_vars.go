@@ -2,9 +2,13 @@ package parser
 
 import "strings"
 
-func parseVarsSection(line string, vars map[string]string) {
+func parseVarsSection(line string, vars map[string]string) error {
 	parts := strings.SplitN(line, STR_ASSIGN, 2)
+	if len(parts) != 2 {
+		return newParseError(line, "variables must be defined as `name "+STR_ASSIGN+" value`")
+	}
 	key := strings.TrimSpace(parts[0])
 	value := strings.TrimSpace(parts[1])
 	vars[key] = value
+	return nil
 }
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/image/resample"
+	"github.com/toxyl/gfx/math/expr"
+)
+
+var reBandName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// bandMath is a source whose pixels are computed from other sources with an expression (see image.BandMath).
+type bandMath struct {
+	norm     image.BandNormalization
+	expr     *expr.Expr
+	names    []string // names of the sources in the order the expression expects them
+	sources  []string
+	min, max float64 // range stretched to [0, 1] by image.NORMALIZE_RANGE
+	clip     float64 // percentage of outliers removed at both ends by image.NORMALIZE_AUTO
+}
+
+// parseBands parses a band maths source like `bands:auto(expr=`(a-b)/(a+b)` a=goes_16_171.png b=goes_16_195.png clip=1)`.
+// The kind is the normalization, every named argument other than expr, min, max and clip is a source that the
+// expression can use by its name. It returns false if src isn't a band maths source and an error if it is one
+// but can't be parsed.
+func parseBands(src string) (*bandMath, bool, error) {
+	name, def, ok := strings.Cut(strings.TrimSpace(src), STR_SOURCE)
+	if !ok || !strings.EqualFold(name, SOURCE_BANDS) {
+		return nil, false, nil
+	}
+	kind, named, unnamed, err := parseSourceArgs(name, def)
+	if err != nil {
+		return nil, true, withText(err, src)
+	}
+	b := &bandMath{norm: image.ParseBandNormalization(kind), min: 0, max: 1, clip: 0}
+	if b.norm == "" {
+		return nil, true, newParseError(src, "unknown normalization %s, must be one of: %s", kind, strings.Join(image.BandNormalizations(), ", "))
+	}
+	if len(unnamed) > 0 {
+		return nil, true, newParseError(src, "%s sources only have named arguments", SOURCE_BANDS)
+	}
+	for _, k := range slices.Sorted(maps.Keys(named)) {
+		v := strings.Trim(named[k], STR_QUOTE)
+		switch k {
+		case BANDS_EXPR:
+			continue
+		case BANDS_MIN, BANDS_MAX, BANDS_CLIP:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, true, newParseError(src, "%s must be a number", k)
+			}
+			switch k {
+			case BANDS_MIN:
+				b.min = f
+			case BANDS_MAX:
+				b.max = f
+			default:
+				b.clip = f
+			}
+			continue
+		}
+		if !reBandName.MatchString(k) {
+			return nil, true, newParseError(src, "invalid source name %s, names may only contain letters, digits and underscores", k)
+		}
+		if _, _, err := parseGenerator(v); err != nil {
+			return nil, true, err
+		}
+		b.names = append(b.names, k)
+		b.sources = append(b.sources, v)
+	}
+	if len(b.names) == 0 {
+		return nil, true, newParseError(src, "%s sources need at least one source", SOURCE_BANDS)
+	}
+	if named[BANDS_EXPR] == "" {
+		return nil, true, newParseError(src, "%s sources need an %s", SOURCE_BANDS, BANDS_EXPR)
+	}
+	if b.expr, err = expr.Compile(strings.Trim(named[BANDS_EXPR], STR_QUOTE), b.names...); err != nil {
+		return nil, true, newParseError(src, "%s", err)
+	}
+	return b, true, nil
+}
+
+// render loads the sources at w x h pixels (resized with kernel if necessary) and computes the band maths.
+func (b *bandMath) render(ctx context.Context, w, h int, kernel resample.Kernel) (*image.Image, error) {
+	bands := make([]*image.Image, len(b.sources))
+	for k, src := range b.sources {
+		l := NewLayer()
+		l.Source = src
+		if err := l.load(ctx, w, h, kernel); err != nil {
+			return nil, err
+		}
+		bands[k] = l.data.ResizeWith(w, h, kernel)
+	}
+	return image.BandMath(ctx, bands, b.expr.Eval, b.norm, b.min, b.max, b.clip)
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/toxyl/gfx/image"
+)
+
+// generator renders a generated source at w x h pixels.
+type generator func(w, h int) *image.Image
+
+// generators maps the names of generated sources to functions that parse their kind and arguments,
+// e.g. `gradient:radial(cx=0.5 hsla(0 1 0.5 1) hsla(240 1 0.5 1))` has the kind `radial` and two arguments
+// that follow the named argument `cx`.
+var generators = map[string]func(kind string, named map[string]string, args []string) (generator, error){
+	SOURCE_GRADIENT:          parseGradient,
+	SOURCE_NOISE:             parseNoise,
+	SOURCE_PATTERN_GENERATOR: parsePattern,
+}
+
+// parseGenerator returns the generator described by src. It returns false if src isn't a generated source
+// and an error if it is one but can't be parsed.
+func parseGenerator(src string) (generator, bool, error) {
+	name, def, ok := strings.Cut(strings.TrimSpace(src), STR_SOURCE)
+	if !ok {
+		return nil, false, nil
+	}
+	parse, ok := generators[strings.ToLower(name)]
+	if !ok {
+		return nil, false, nil
+	}
+	kind, named, unnamed, err := parseSourceArgs(name, def)
+	if err != nil {
+		return nil, true, withText(err, src)
+	}
+	gen, err := parse(kind, named, unnamed)
+	if err != nil {
+		return nil, true, withText(err, src)
+	}
+	return gen, true, nil
+}
+
+// parseSourceArgs splits def, the part of a source like `name:kind(args)` that follows `name:`,
+// into the kind and the named and unnamed arguments.
+func parseSourceArgs(name, def string) (kind string, named map[string]string, unnamed []string, err error) {
+	kind, args, ok := strings.Cut(def, STR_LPAREN)
+	if !ok || !strings.HasSuffix(args, STR_RPAREN) {
+		return "", nil, nil, newParseError("", "%s sources must be defined as `%s%skind%sargs%s`", name, name, STR_SOURCE, STR_LPAREN, STR_RPAREN)
+	}
+	named = map[string]string{}
+	unnamed = []string{}
+	for _, a := range splitGeneratorArgs(strings.TrimSuffix(args, STR_RPAREN)) {
+		if k, v, ok := strings.Cut(a, STR_ASSIGN); ok && !strings.Contains(k, STR_LPAREN) {
+			named[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+			continue
+		}
+		unnamed = append(unnamed, a)
+	}
+	return strings.ToLower(strings.TrimSpace(kind)), named, unnamed, nil
+}
+
+// splitGeneratorArgs splits args at whitespace that isn't enclosed in parentheses or quotes,
+// `x=0 hsla(0 1 0.5 1) 0.5` gives `x=0`, `hsla(0 1 0.5 1)` and `0.5`. Whitespace around `=` is removed first.
+func splitGeneratorArgs(args string) []string {
+	for _, s := range []string{STR_SPACE, STR_TAB} {
+		for strings.Contains(args, s+STR_ASSIGN) || strings.Contains(args, STR_ASSIGN+s) {
+			args = strings.ReplaceAll(strings.ReplaceAll(args, s+STR_ASSIGN, STR_ASSIGN), STR_ASSIGN+s, STR_ASSIGN)
+		}
+	}
+	res := []string{}
+	depth := 0
+	inQuote := false
+	start := 0
+	for i, c := range args {
+		switch c {
+		case CHAR_QUOTE:
+			inQuote = !inQuote
+		case CHAR_LPAREN:
+			depth++
+		case CHAR_RPAREN:
+			depth--
+		case CHAR_SPACE, CHAR_TAB:
+			if depth == 0 && !inQuote {
+				if a := strings.TrimSpace(args[start:i]); a != "" {
+					res = append(res, a)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if a := strings.TrimSpace(args[start:]); a != "" {
+		res = append(res, a)
+	}
+	return res
+}
+
+// generatorFloats returns the named arguments of a generated source that are listed in defs (as name and default value)
+// as numbers, using the defaults for missing ones. Named arguments that are neither in defs nor in other are an error.
+func generatorFloats(source, kind string, named map[string]string, defs [][2]string, other ...string) (map[string]float64, error) {
+	v := map[string]float64{}
+	for _, d := range defs {
+		v[d[0]], _ = strconv.ParseFloat(d[1], 64)
+	}
+	for k, s := range named {
+		if _, ok := v[k]; !ok {
+			if !slices.Contains(other, k) {
+				return nil, newParseError("", "unknown argument %s for %s%s%s", k, source, STR_SOURCE, kind)
+			}
+			continue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, newParseError("", "%s must be a number", k)
+		}
+		v[k] = f
+	}
+	return v, nil
+}
+
+// withText sets the text of parse errors that don't have any yet.
+func withText(err error, text string) error {
+	if pe, ok := err.(*ParseError); ok && pe.Text == "" {
+		pe.Text = text
+	}
+	return err
+}
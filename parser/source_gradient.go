@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/toxyl/gfx/image"
+)
+
+// gradientArgs are the named arguments of each kind of gradient with their defaults.
+// Positions are relative to the width and height of the image, radii relative to the larger of both.
+var gradientArgs = map[string][][2]string{
+	GRADIENT_LINEAR: {{"x0", "0"}, {"y0", "0"}, {"x1", "1"}, {"y1", "0"}},
+	GRADIENT_RADIAL: {{"cx", "0.5"}, {"cy", "0.5"}, {"r", "0.5"}},
+	GRADIENT_CONIC:  {{"cx", "0.5"}, {"cy", "0.5"}, {"angle", "0"}},
+}
+
+// parseGradient parses a gradient source like `gradient:linear(x0=0 y0=0 x1=1 y1=1 space=oklab hsla(0 1 0.5 1) 0 hsla(240 1 0.5 1) 1)`.
+// The named arguments are optional, the unnamed ones are the color stops, each optionally followed by its position.
+// Like in CSS, the first and last stop default to the positions 0 and 1 and stops without position are spread evenly
+// between their neighbors.
+func parseGradient(kind string, named map[string]string, args []string) (generator, error) {
+	defs, ok := gradientArgs[kind]
+	if !ok {
+		return nil, newParseError("", "unknown gradient %s, must be one of: %s", kind, strings.Join(GRADIENTS, ", "))
+	}
+	space := image.INTERPOLATE_RGB
+	if v, ok := named["space"]; ok {
+		if !slices.Contains(image.InterpolationNames(), strings.ToLower(v)) {
+			return nil, newParseError("", "unknown color space %s, must be one of: %s", v, strings.Join(image.InterpolationNames(), ", "))
+		}
+		space = image.ParseInterpolation(v)
+	}
+	v, err := generatorFloats(SOURCE_GRADIENT, kind, named, defs, "space")
+	if err != nil {
+		return nil, err
+	}
+
+	stops := []image.GradientStop{}
+	for i := 0; i < len(args); i++ {
+		col, err := parseColor(args[i])
+		if err != nil {
+			return nil, newParseError("", "gradient stops must be given as `hsla%sh s l a%s [position]`, got %s", STR_LPAREN, STR_RPAREN, args[i])
+		}
+		pos := math.NaN()
+		if i+1 < len(args) {
+			if f, err := strconv.ParseFloat(args[i+1], 64); err == nil {
+				pos = f
+				i++
+			}
+		}
+		stops = append(stops, image.GradientStop{Position: pos, Color: col})
+	}
+	if len(stops) == 0 {
+		return nil, newParseError("", "gradients need at least one color stop")
+	}
+	if math.IsNaN(stops[0].Position) {
+		stops[0].Position = 0
+	}
+	if n := len(stops); math.IsNaN(stops[n-1].Position) {
+		stops[n-1].Position = 1
+	}
+	for i := 1; i < len(stops); i++ {
+		if !math.IsNaN(stops[i].Position) {
+			continue
+		}
+		j := i + 1
+		for math.IsNaN(stops[j].Position) {
+			j++
+		}
+		p0, p1 := stops[i-1].Position, stops[j].Position
+		for k := i; k < j; k++ {
+			stops[k].Position = p0 + (p1-p0)*float64(k-i+1)/float64(j-i+1)
+		}
+	}
+	g := image.NewGradient(space, stops...)
+
+	switch kind {
+	case GRADIENT_LINEAR:
+		return func(w, h int) *image.Image {
+			fw, fh := float64(w), float64(h)
+			return g.Linear(w, h, v["x0"]*fw, v["y0"]*fh, v["x1"]*fw, v["y1"]*fh)
+		}, nil
+	case GRADIENT_RADIAL:
+		return func(w, h int) *image.Image {
+			fw, fh := float64(w), float64(h)
+			return g.Radial(w, h, v["cx"]*fw, v["cy"]*fh, v["r"]*math.Max(fw, fh))
+		}, nil
+	default:
+		return func(w, h int) *image.Image {
+			return g.Conic(w, h, v["cx"]*float64(w), v["cy"]*float64(h), v["angle"])
+		}, nil
+	}
+}
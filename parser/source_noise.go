@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/toxyl/gfx/image"
+)
+
+// parseNoise parses a noise source like `noise:simplex(scale=0.01 octaves=4 persistence=0.5 seed=7)`.
+// All arguments are optional, scale defaults to 0.01 (1 for white noise).
+func parseNoise(kind string, named map[string]string, args []string) (generator, error) {
+	if !slices.Contains(image.NoiseTypes(), kind) {
+		return nil, newParseError("", "unknown noise %s, must be one of: %s", kind, strings.Join(image.NoiseTypes(), ", "))
+	}
+	if len(args) > 0 {
+		return nil, newParseError("", "noise only has named arguments")
+	}
+	scale := "0.01"
+	if kind == string(image.NOISE_WHITE) {
+		scale = "1"
+	}
+	v, err := generatorFloats(SOURCE_NOISE, kind, named, [][2]string{{"scale", scale}, {"octaves", "1"}, {"persistence", "0.5"}, {"seed", "0"}})
+	if err != nil {
+		return nil, err
+	}
+	return func(w, h int) *image.Image {
+		return image.NewNoise(w, h, image.NoiseType(kind), v["scale"], int(v["octaves"]), v["persistence"], int64(v["seed"]))
+	}, nil
+}
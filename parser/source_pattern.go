@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/toxyl/gfx/color/hsla"
+	"github.com/toxyl/gfx/image"
+)
+
+// patternArgs are the named arguments of each pattern with their defaults, sizes are in pixels.
+var patternArgs = map[string][][2]string{
+	PATTERN_CHECKERBOARD: {{"size", "16"}},
+	PATTERN_STRIPES:      {{"width", "8"}, {"angle", "0"}},
+	PATTERN_GRID:         {{"size", "16"}, {"thickness", "1"}},
+}
+
+// parsePattern parses a pattern source like `pattern:stripes(width=4 angle=45 hsla(0 0 1 1) hsla(0 0 0 1))`.
+// The named arguments are optional, the unnamed ones are up to two colors: the colors of the checkerboard or stripes
+// (white and black by default) or the line and background colors of the grid (white and transparent by default).
+func parsePattern(kind string, named map[string]string, args []string) (generator, error) {
+	defs, ok := patternArgs[kind]
+	if !ok {
+		return nil, newParseError("", "unknown pattern %s, must be one of: %s", kind, strings.Join(PATTERNS, ", "))
+	}
+	v, err := generatorFloats(SOURCE_PATTERN_GENERATOR, kind, named, defs)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 2 {
+		return nil, newParseError("", "patterns take up to two colors")
+	}
+	cols := []*hsla.HSLA{hsla.New(0, 0.0, 1.0, 1.0), hsla.New(0, 0.0, 0.0, 1.0)}
+	if kind == PATTERN_GRID {
+		cols[1] = hsla.New(0, 0.0, 0.0, 0.0)
+	}
+	for i, a := range args {
+		if cols[i], err = parseColor(a); err != nil {
+			return nil, newParseError("", "pattern colors must be given as `hsla%sh s l a%s`, got %s", STR_LPAREN, STR_RPAREN, a)
+		}
+	}
+	switch kind {
+	case PATTERN_CHECKERBOARD:
+		return func(w, h int) *image.Image { return image.NewCheckerboard(w, h, v["size"], cols[0], cols[1]) }, nil
+	case PATTERN_STRIPES:
+		return func(w, h int) *image.Image { return image.NewStripes(w, h, v["width"], v["angle"], cols[0], cols[1]) }, nil
+	default:
+		return func(w, h int) *image.Image { return image.NewGrid(w, h, v["size"], v["thickness"], cols[0], cols[1]) }, nil
+	}
+}
@@ -74,7 +74,8 @@ func GenerateVSIX() (string, error) {
 	// keywords
 	fnAddPattern("keyword.include", KEYWORDS_PATTERN)
 	// blendmodes
-	fnAddPattern("string.regexp", BLENDMODES_PATTERN)
+	fnAddPattern("string.regexp", BLENDMODES_PATTERN+`(?=\s+`+NUMBER_PATTERN+`)`)    // the alpha must follow, so modes like color don't match settings or the start of color-burn
+	fnAddPattern("string.regexp", `^\s*`+WORD_PATTERN+`(?=\s+`+NUMBER_PATTERN+`\s)`) // user-defined blend modes of [BLENDMODES] in layers
 	// filepaths
 	fnAddPattern("entity.name.type", SOURCE_PATTERN)
 	// numbers
@@ -83,6 +84,13 @@ func GenerateVSIX() (string, error) {
 	fnAddPattern("keyword.other", COMPOSITION_PATTERN+`(?=\s*`+STR_ASSIGN+`)`)
 	// layer operations
 	fnAddPattern("keyword.other", LAYER_PATTERN+`(?=\s+\d+)`)
+	fnAddPattern("keyword.other", `\b`+LAYER_RESAMPLE+`\b(?=\s+`+RESAMPLERS_PATTERN+`)`)
+	fnAddPattern("keyword.other", `\b`+LAYER_BLEND_SPACE+`\b(?=\s+`+BLEND_SPACES_PATTERN+`)`)
+	fnAddPattern("keyword.other", `\b`+LAYER_COMPOSITE+`\b(?=\s+`+COMPOSITE_OPS_PATTERN+`)`)
+	// resampling kernels, blend spaces and Porter-Duff operators
+	fnAddPattern("constant.language", RESAMPLERS_PATTERN)
+	fnAddPattern("constant.language", BLEND_SPACES_PATTERN)
+	fnAddPattern("constant.language", COMPOSITE_OPS_PATTERN)
 	// functions
 	fnAddPattern("support.function", WORD_PATTERN+`\s*\`+STR_LPAREN)
 	// sections
@@ -7,17 +7,16 @@ import (
 	"os"
 )
 
-func Save(img image.Image, path string) {
+func Save(img image.Image, path string) error {
 	outFile, err := os.Create(path) // #nosec G304
 	if err != nil {
-		panic(err)
+		return err
 	}
-	defer outFile.Close()
-
-	err = png.Encode(outFile, img)
-	if err != nil {
-		panic(err)
+	if err := png.Encode(outFile, img); err != nil {
+		_ = outFile.Close()
+		return err
 	}
+	return outFile.Close()
 }
 
 func FromFile(filename string) (image.Image, error) {
@@ -3,13 +3,31 @@ package dialogbox
 import (
 	"github.com/toxyl/gfx/color/blend"
 	"github.com/toxyl/gfx/image"
+	"github.com/toxyl/gfx/ui/text"
 	"github.com/toxyl/gfx/vars"
 )
 
+// size returns the smallest size of a dialog box that fits the title and content and the height of its header.
+func size(title string, content *image.Image) (w, h, hh int) {
+	wt, ht := text.Measure(title)
+	hh = max(vars.DIALOG_HEADER_HEIGHT, ht+2*vars.SPACING)
+	return max(wt+2*vars.SPACING, content.W()+4), hh + content.H() + 4, hh
+}
+
+// Size returns the smallest size of a dialog box that fits the title and content.
+func Size(title string, content *image.Image) (w, h int) {
+	w, h, _ = size(title, content)
+	return w, h
+}
+
+// Draw draws a dialog box with the title in its header and the content at the bottom of its body.
+// The box grows if w or h are too small to fit both, use 0 to size it to the title and content.
 func Draw(img *image.Image, x, y, w, h int, title string, content *image.Image, mode blend.BlendMode) {
 	wc := content.W()
 	hc := content.H()
-	hh := vars.DIALOG_HEADER_HEIGHT
+	wMin, hMin, hh := size(title, content)
+	w = max(w, wMin)
+	h = max(h, hMin)
 	x += vars.SPACING
 	y += vars.SPACING
 
@@ -30,7 +48,7 @@ func Draw(img *image.Image, x, y, w, h int, title string, content *image.Image,
 	img.DrawRect(x, y, w, hh, 2, vars.COLOR_BORDER_HEADER, vars.COLOR_FILL_HEADER, blend.NORMAL)
 
 	// title
-	img.DrawText(title, x+vars.SPACING, y+vars.SPACING, *vars.COLOR_FONT, false, blend.NORMAL)
+	text.Draw(img, x+vars.SPACING, y+vars.SPACING, title, blend.NORMAL)
 
 	// content border
 	img.DrawRect(x+1, y+hh, w-2, (h-hh)-1, 1, vars.COLOR_BLACK_50_PCT, vars.COLOR_TRANSPARENT, blend.NORMAL)
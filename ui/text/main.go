@@ -1,11 +1,41 @@
 package text
 
 import (
+	"sync"
+
 	"github.com/toxyl/gfx/color/blend"
 	"github.com/toxyl/gfx/image"
 	"github.com/toxyl/gfx/vars"
 )
 
+var (
+	mu   = &sync.Mutex{}
+	font = image.SpriteFont()
+)
+
+// SetFont selects the font Draw uses, e.g. a TrueType font loaded with image.LoadFont.
+// nil restores the embedded sprite font.
+func SetFont(f image.Font) {
+	mu.Lock()
+	defer mu.Unlock()
+	if f == nil {
+		f = image.SpriteFont()
+	}
+	font = f
+}
+
+// Font returns the font Draw uses.
+func Font() image.Font {
+	mu.Lock()
+	defer mu.Unlock()
+	return font
+}
+
 func Draw(img *image.Image, x, y int, text string, mode blend.BlendMode) {
-	img.DrawText(text, x, y, *vars.COLOR_FONT, false, mode)
+	img.DrawTextWith(Font(), text, x, y, *vars.COLOR_FONT, false, mode)
+}
+
+// Measure returns the size of the area Draw covers when drawing text.
+func Measure(text string) (w, h int) {
+	return image.MeasureText(Font(), text)
 }